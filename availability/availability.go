@@ -0,0 +1,128 @@
+// availability/availability.go
+package availability
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var ErrInvalidClock = errors.New(`clock time must look like "HH:MM" between 00:00 and 24:00`)
+
+// Clock is a wall-clock time of day. 24:00 stands for the following midnight.
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClock parses "HH:MM".
+func ParseClock(s string) (Clock, error) {
+	if len(s) != 5 || s[2] != ':' || !isDigits(s[:2]) || !isDigits(s[3:]) {
+		return Clock{}, fmt.Errorf("%w: %q", ErrInvalidClock, s)
+	}
+	c := Clock{
+		Hour:   int(s[0]-'0')*10 + int(s[1]-'0'),
+		Minute: int(s[3]-'0')*10 + int(s[4]-'0'),
+	}
+	if c.Minute > 59 || c.Hour > 24 || (c.Hour == 24 && c.Minute != 0) {
+		return Clock{}, fmt.Errorf("%w: %q", ErrInvalidClock, s)
+	}
+	return c, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// On returns the clock time on the given calendar day in loc. Building the
+// time from its fields keeps opening hours right across DST changes.
+func (c Clock) On(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, c.Hour, c.Minute, 0, 0, loc)
+}
+
+func (c Clock) minutes() int {
+	return c.Hour*60 + c.Minute
+}
+
+// Window is the half-open interval [Start, End).
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// OpeningWindow returns the opening hours starting on the given calendar day
+// in loc. A closing time at or before the opening time closes the next day.
+func OpeningWindow(year int, month time.Month, day int, loc *time.Location, opens, closes Clock) Window {
+	w := Window{Start: opens.On(year, month, day, loc), End: closes.On(year, month, day, loc)}
+	if closes.minutes() <= opens.minutes() {
+		w.End = closes.On(year, month, day+1, loc)
+	}
+	return w
+}
+
+// Clip narrows w to the part that also lies within bounds. The result is
+// empty (Start == End) when they do not overlap.
+func (w Window) Clip(bounds Window) Window {
+	if bounds.Start.After(w.Start) {
+		w.Start = bounds.Start
+	}
+	if bounds.End.Before(w.End) {
+		w.End = bounds.End
+	}
+	if !w.End.After(w.Start) {
+		w.End = w.Start
+	}
+	return w
+}
+
+// Free returns the parts of open not covered by any busy window, in order.
+func Free(open Window, busy []Window) []Window {
+	busy = slices.Clone(busy)
+	slices.SortFunc(busy, func(a, b Window) int { return a.Start.Compare(b.Start) })
+
+	var free []Window
+	cursor := open.Start
+	for _, b := range busy {
+		if !b.End.After(cursor) {
+			continue
+		}
+		if !b.Start.Before(open.End) {
+			break
+		}
+		if b.Start.After(cursor) {
+			free = append(free, Window{Start: cursor, End: b.Start})
+		}
+		cursor = b.End
+	}
+	if open.End.After(cursor) {
+		free = append(free, Window{Start: cursor, End: open.End})
+	}
+	return free
+}
+
+// Slots lays consecutive slots of the given duration into every free window,
+// starting at the beginning of each window. Remainders shorter than duration
+// are dropped.
+func Slots(free []Window, duration time.Duration) []Window {
+	if duration <= 0 {
+		return nil
+	}
+	var slots []Window
+	for _, w := range free {
+		for start := w.Start; !start.Add(duration).After(w.End); start = start.Add(duration) {
+			slots = append(slots, Window{Start: start, End: start.Add(duration)})
+		}
+	}
+	return slots
+}
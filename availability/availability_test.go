@@ -0,0 +1,77 @@
+// availability/availability_test.go
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(clock string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", "2024-10-10 "+clock)
+	return t
+}
+
+func window(start, end string) Window {
+	return Window{Start: at(start), End: at(end)}
+}
+
+func TestParseClock(t *testing.T) {
+	c, err := ParseClock("09:30")
+	assert.NoError(t, err)
+	assert.Equal(t, Clock{Hour: 9, Minute: 30}, c)
+
+	c, err = ParseClock("24:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 24, c.Hour)
+
+	for _, bad := range []string{"", "9:30", "24:30", "12:60", "ab:cd", "12-30", "25:00", "+1:30"} {
+		_, err := ParseClock(bad)
+		assert.ErrorIs(t, err, ErrInvalidClock, bad)
+	}
+}
+
+func TestOpeningWindow(t *testing.T) {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	w := OpeningWindow(2024, 10, 10, taipei, Clock{Hour: 9}, Clock{Hour: 24})
+	assert.Equal(t, time.Date(2024, 10, 10, 1, 0, 0, 0, time.UTC), w.Start.UTC())
+	assert.Equal(t, 15*time.Hour, w.Duration())
+
+	// Closing after midnight
+	w = OpeningWindow(2024, 10, 10, time.UTC, Clock{Hour: 18}, Clock{Hour: 2})
+	assert.Equal(t, at("18:00"), w.Start)
+	assert.Equal(t, 8*time.Hour, w.Duration())
+
+	// Opening hours follow the wall clock across a DST change
+	london, _ := time.LoadLocation("Europe/London")
+	w = OpeningWindow(2024, 10, 27, london, Clock{Hour: 0}, Clock{Hour: 24})
+	assert.Equal(t, 25*time.Hour, w.Duration())
+}
+
+func TestFree(t *testing.T) {
+	open := window("09:00", "18:00")
+	busy := []Window{
+		window("13:00", "14:00"),
+		window("08:00", "10:00"), // starts before opening
+		window("13:30", "15:00"), // overlaps the previous one
+		window("17:30", "19:00"), // runs past closing
+	}
+	assert.Equal(t, []Window{window("10:00", "13:00"), window("15:00", "17:30")}, Free(open, busy))
+	assert.Equal(t, []Window{open}, Free(open, nil))
+	assert.Empty(t, Free(open, []Window{window("08:00", "19:00")}))
+}
+
+func TestSlots(t *testing.T) {
+	free := []Window{window("10:00", "12:30"), window("15:00", "15:45")}
+	assert.Equal(t, []Window{window("10:00", "11:00"), window("11:00", "12:00")}, Slots(free, time.Hour))
+	assert.Len(t, Slots(free, 45*time.Minute), 4)
+	assert.Empty(t, Slots(free, 0))
+}
+
+func TestClip(t *testing.T) {
+	w := window("09:00", "18:00")
+	assert.Equal(t, window("12:00", "18:00"), w.Clip(window("12:00", "20:00")))
+	clipped := w.Clip(window("19:00", "20:00"))
+	assert.Zero(t, clipped.Duration())
+}
@@ -0,0 +1,469 @@
+// config/config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every tunable setting of the API server.
+type Config struct {
+	Server      ServerConfig      `yaml:"server" json:"server"`
+	Database    DatabaseConfig    `yaml:"database" json:"database"`
+	Challenge   ChallengeConfig   `yaml:"challenge" json:"challenge"`
+	Payment     PaymentConfig     `yaml:"payment" json:"payment"`
+	Reservation ReservationConfig `yaml:"reservation" json:"reservation"`
+	Realtime    RealtimeConfig    `yaml:"realtime" json:"realtime"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" json:"idempotency"`
+	Webhook     WebhookConfig     `yaml:"webhook" json:"webhook"`
+	Features    FeatureConfig     `yaml:"features" json:"features"`
+}
+
+// ServerConfig controls the HTTP listener.
+type ServerConfig struct {
+	Addr            string        `yaml:"addr" json:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" json:"write_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+}
+
+// DatabaseConfig controls the database connection and its pool.
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" json:"driver"` // postgres or sqlite
+	Path            string        `yaml:"path" json:"path"`     // SQLite database file
+	Host            string        `yaml:"host" json:"host"`
+	Port            int           `yaml:"port" json:"port"`
+	User            string        `yaml:"user" json:"user"`
+	Password        string        `yaml:"password" json:"password"`
+	Name            string        `yaml:"name" json:"name"`
+	SSLMode         string        `yaml:"sslmode" json:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+}
+
+// ChallengeConfig tunes the endless challenge.
+type ChallengeConfig struct {
+	EntryFee        int64                 `yaml:"entry_fee" json:"entry_fee"` // In cents
+	Duration        time.Duration         `yaml:"duration" json:"duration"`   // Time from entry until the outcome is decided
+	Cooldown        time.Duration         `yaml:"cooldown" json:"cooldown"`
+	ResultsLimit    int                   `yaml:"results_limit" json:"results_limit"`
+	Rake            float64               `yaml:"rake" json:"rake"`                         // Share of each entry fee kept by the house, 0 to 1
+	JackpotSeed     int64                 `yaml:"jackpot_seed" json:"jackpot_seed"`         // Pot in cents the jackpot starts from and is reset to after a payout
+	ResolveInterval time.Duration         `yaml:"resolve_interval" json:"resolve_interval"` // How often due challenges are looked up
+	Odds            map[string]OddsConfig `yaml:"odds" json:"odds"`                         // Win chance model by challenge type
+}
+
+// Odds models. See package odds for how each computes a win chance.
+const (
+	OddsFlat   = "flat"
+	OddsLinear = "linear"
+	OddsStreak = "streak"
+	OddsLevel  = "level"
+)
+
+// OddsConfig picks the model deciding a challenge's win chance and tunes
+// it. Chances are probabilities from 0 to 1.
+type OddsConfig struct {
+	Model  string             `yaml:"model" json:"model"`   // flat, linear, streak or level
+	Base   float64            `yaml:"base" json:"base"`     // Chance of a first entry, and the chance under flat
+	Step   float64            `yaml:"step" json:"step"`     // linear: added per earlier entry; streak: added per loss in a row
+	Cap    float64            `yaml:"cap" json:"cap"`       // Highest chance linear and streak reach
+	Levels map[string]float64 `yaml:"levels" json:"levels"` // level: chance by player level ID; other players get Base
+}
+
+// PaymentConfig tunes payment processing.
+type PaymentConfig struct {
+	Methods           []string      `yaml:"methods" json:"methods"`                       // Enabled payment methods
+	MinAmount         int64         `yaml:"min_amount" json:"min_amount"`                 // In cents
+	MaxAmount         int64         `yaml:"max_amount" json:"max_amount"`                 // In cents
+	ProcessInterval   time.Duration `yaml:"process_interval" json:"process_interval"`     // How often accepted payments are picked up to be charged
+	AuditInterval     time.Duration `yaml:"audit_interval" json:"audit_interval"`         // How often the ledger is checked against the wallet balances
+	MaxWait           time.Duration `yaml:"max_wait" json:"max_wait"`                     // Longest a GET /payments/:id?wait= long-poll is held open
+	CallbackTolerance time.Duration `yaml:"callback_tolerance" json:"callback_tolerance"` // How far a provider callback's signature time may be off, against replays
+
+	Gateways map[string]GatewayConfig `yaml:"gateways" json:"gateways"` // Provider endpoint by payment method
+}
+
+// GatewayConfig points a payment method at the HTTP endpoint of its provider.
+type GatewayConfig struct {
+	URL            string        `yaml:"url" json:"url"`                         // Base URL the authorize, capture, refund and status calls go to
+	APIKey         string        `yaml:"api_key" json:"api_key"`                 // Sent as a bearer token when set
+	Timeout        time.Duration `yaml:"timeout" json:"timeout"`                 // Per call
+	CallbackSecret string        `yaml:"callback_secret" json:"callback_secret"` // Verifies the provider's callbacks, which are refused while unset
+}
+
+// ReservationConfig sets the reservation policies.
+type ReservationConfig struct {
+	CancellationWindow time.Duration `yaml:"cancellation_window" json:"cancellation_window"` // Changes and cancellations close this long before the start
+	NoShowGrace        time.Duration `yaml:"no_show_grace" json:"no_show_grace"`             // Check-in opens this long before the start and closes this long after
+	SweepInterval      time.Duration `yaml:"sweep_interval" json:"sweep_interval"`           // How often no-shows and finished reservations are settled
+}
+
+// RealtimeConfig tunes the room WebSocket connections.
+type RealtimeConfig struct {
+	SendBuffer   int           `yaml:"send_buffer" json:"send_buffer"` // Events queued per client before it is dropped as too slow
+	PingInterval time.Duration `yaml:"ping_interval" json:"ping_interval"`
+	PongTimeout  time.Duration `yaml:"pong_timeout" json:"pong_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" json:"write_timeout"`
+}
+
+// IdempotencyConfig controls how Idempotency-Key headers are honored.
+type IdempotencyConfig struct {
+	TTL           time.Duration `yaml:"ttl" json:"ttl"`                       // How long a key's response is kept for replay
+	WaitTimeout   time.Duration `yaml:"wait_timeout" json:"wait_timeout"`     // How long a retry waits for the first request to finish
+	LockTimeout   time.Duration `yaml:"lock_timeout" json:"lock_timeout"`     // How long a request holds its key before a retry may take it over
+	PurgeInterval time.Duration `yaml:"purge_interval" json:"purge_interval"` // How often expired keys are deleted
+}
+
+// WebhookConfig tunes the delivery of webhook events.
+type WebhookConfig struct {
+	DispatchInterval time.Duration `yaml:"dispatch_interval" json:"dispatch_interval"` // How often due deliveries are sent
+	Timeout          time.Duration `yaml:"timeout" json:"timeout"`                     // Per delivery attempt
+	MaxAttempts      int           `yaml:"max_attempts" json:"max_attempts"`           // Before a delivery is given up as failed
+	BackoffBase      time.Duration `yaml:"backoff_base" json:"backoff_base"`           // Wait after the first failed attempt, doubled after each further one
+	BackoffMax       time.Duration `yaml:"backoff_max" json:"backoff_max"`             // Longest wait between attempts
+}
+
+// FeatureConfig toggles optional parts of the API.
+type FeatureConfig struct {
+	Swagger    bool `yaml:"swagger" json:"swagger"`
+	Challenges bool `yaml:"challenges" json:"challenges"`
+	Payments   bool `yaml:"payments" json:"payments"`
+}
+
+// Default returns the configuration used when nothing else is provided.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverPostgres,
+			Path:            "spinner.db",
+			Host:            "db",
+			Port:            5432,
+			User:            "archie",
+			Password:        "postgres",
+			Name:            "spinnerdb",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Challenge: ChallengeConfig{
+			EntryFee:        2001,
+			Duration:        30 * time.Second,
+			Cooldown:        time.Minute,
+			ResultsLimit:    10,
+			Rake:            0.1,
+			JackpotSeed:     10000,
+			ResolveInterval: time.Second,
+			Odds: map[string]OddsConfig{
+				"endless": {Model: OddsFlat, Base: 0.01, Step: 0.005, Cap: 0.05},
+			},
+		},
+		Payment: PaymentConfig{
+			Methods:           []string{"CreditCard", "BankTransfer", "ThirdParty", "Blockchain"},
+			MinAmount:         1,
+			MaxAmount:         1000000,
+			ProcessInterval:   time.Second,
+			AuditInterval:     10 * time.Minute,
+			MaxWait:           time.Minute,
+			CallbackTolerance: 5 * time.Minute,
+			// The stub gateways served by the stubgateway subcommand
+			Gateways: map[string]GatewayConfig{
+				"CreditCard":   {URL: "http://localhost:9090/creditcard", Timeout: 10 * time.Second},
+				"BankTransfer": {URL: "http://localhost:9090/banktransfer", Timeout: 10 * time.Second},
+				"ThirdParty":   {URL: "http://localhost:9090/thirdparty", Timeout: 10 * time.Second},
+				"Blockchain":   {URL: "http://localhost:9090/blockchain", Timeout: 10 * time.Second},
+			},
+		},
+		Reservation: ReservationConfig{
+			CancellationWindow: 2 * time.Hour,
+			NoShowGrace:        15 * time.Minute,
+			SweepInterval:      time.Minute,
+		},
+		Realtime: RealtimeConfig{
+			SendBuffer:   64,
+			PingInterval: 30 * time.Second,
+			PongTimeout:  60 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           24 * time.Hour,
+			WaitTimeout:   10 * time.Second,
+			LockTimeout:   30 * time.Second,
+			PurgeInterval: time.Hour,
+		},
+		Webhook: WebhookConfig{
+			DispatchInterval: 5 * time.Second,
+			Timeout:          10 * time.Second,
+			MaxAttempts:      10,
+			BackoffBase:      10 * time.Second,
+			BackoffMax:       time.Hour,
+		},
+		Features: FeatureConfig{
+			Swagger:    true,
+			Challenges: true,
+			Payments:   true,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, then the optional file named
+// by CONFIG_FILE, then environment variables, and validates the result.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays the settings found in a YAML or JSON file.
+// JSON is a subset of YAML, so a single decoder handles both formats.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr is required"))
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.Host == "" {
+			errs = append(errs, errors.New("database.host is required"))
+		}
+		if c.Database.Port <= 0 || c.Database.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database.port %d is out of range", c.Database.Port))
+		}
+		if c.Database.User == "" {
+			errs = append(errs, errors.New("database.user is required"))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, errors.New("database.name is required"))
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("database.path is required for sqlite"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver %q is not supported (use %s or %s)", c.Database.Driver, DriverPostgres, DriverSQLite))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+
+	if c.Challenge.EntryFee <= 0 {
+		errs = append(errs, errors.New("challenge.entry_fee must be positive"))
+	}
+	if c.Challenge.Duration <= 0 {
+		errs = append(errs, errors.New("challenge.duration must be positive"))
+	}
+	if c.Challenge.Cooldown < 0 {
+		errs = append(errs, errors.New("challenge.cooldown must not be negative"))
+	}
+	if c.Challenge.Rake < 0 || c.Challenge.Rake >= 1 {
+		errs = append(errs, errors.New("challenge.rake must be at least 0 and below 1"))
+	}
+	if c.Challenge.ResolveInterval <= 0 {
+		errs = append(errs, errors.New("challenge.resolve_interval must be positive"))
+	}
+	if c.Challenge.JackpotSeed < 0 {
+		errs = append(errs, errors.New("challenge.jackpot_seed must not be negative"))
+	}
+	if c.Challenge.ResultsLimit <= 0 {
+		errs = append(errs, errors.New("challenge.results_limit must be positive"))
+	}
+	// Entries without a type are endless challenges
+	if _, ok := c.Challenge.Odds["endless"]; !ok {
+		errs = append(errs, errors.New("challenge.odds must configure the endless challenge type"))
+	}
+	for challengeType, odds := range c.Challenge.Odds {
+		errs = append(errs, odds.validate("challenge.odds."+challengeType)...)
+	}
+
+	if len(c.Payment.Methods) == 0 {
+		errs = append(errs, errors.New("payment.methods must list at least one method"))
+	}
+	if c.Payment.MinAmount <= 0 {
+		errs = append(errs, errors.New("payment.min_amount must be positive"))
+	}
+	if c.Payment.MaxAmount < c.Payment.MinAmount {
+		errs = append(errs, errors.New("payment.max_amount must not be below payment.min_amount"))
+	}
+	if c.Payment.ProcessInterval <= 0 {
+		errs = append(errs, errors.New("payment.process_interval must be positive"))
+	}
+	if c.Payment.AuditInterval <= 0 {
+		errs = append(errs, errors.New("payment.audit_interval must be positive"))
+	}
+	if c.Payment.MaxWait <= 0 {
+		errs = append(errs, errors.New("payment.max_wait must be positive"))
+	}
+	if c.Payment.CallbackTolerance <= 0 {
+		errs = append(errs, errors.New("payment.callback_tolerance must be positive"))
+	}
+	// Every enabled method needs a provider to process it
+	for _, method := range c.Payment.Methods {
+		gateway, ok := c.Payment.Gateways[method]
+		if !ok {
+			errs = append(errs, fmt.Errorf("payment.gateways must configure the %s method", method))
+			continue
+		}
+		if u, err := url.Parse(gateway.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("payment.gateways.%s.url must be an http or https URL", method))
+		}
+		if gateway.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("payment.gateways.%s.timeout must be positive", method))
+		}
+	}
+
+	if c.Reservation.CancellationWindow < 0 {
+		errs = append(errs, errors.New("reservation.cancellation_window must not be negative"))
+	}
+	if c.Reservation.NoShowGrace <= 0 || c.Reservation.SweepInterval <= 0 {
+		errs = append(errs, errors.New("reservation.no_show_grace and reservation.sweep_interval must be positive"))
+	}
+
+	if c.Realtime.SendBuffer <= 0 {
+		errs = append(errs, errors.New("realtime.send_buffer must be positive"))
+	}
+	if c.Realtime.PingInterval <= 0 || c.Realtime.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("realtime.ping_interval and realtime.write_timeout must be positive"))
+	}
+	if c.Realtime.PongTimeout <= c.Realtime.PingInterval {
+		errs = append(errs, errors.New("realtime.pong_timeout must exceed realtime.ping_interval"))
+	}
+
+	if c.Idempotency.TTL <= 0 || c.Idempotency.WaitTimeout <= 0 || c.Idempotency.LockTimeout <= 0 || c.Idempotency.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("idempotency.ttl, idempotency.wait_timeout, idempotency.lock_timeout and idempotency.purge_interval must be positive"))
+	}
+
+	if c.Webhook.DispatchInterval <= 0 || c.Webhook.Timeout <= 0 {
+		errs = append(errs, errors.New("webhook.dispatch_interval and webhook.timeout must be positive"))
+	}
+	if c.Webhook.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("webhook.max_attempts must be positive"))
+	}
+	if c.Webhook.BackoffBase <= 0 || c.Webhook.BackoffMax < c.Webhook.BackoffBase {
+		errs = append(errs, errors.New("webhook.backoff_base must be positive and no longer than webhook.backoff_max"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DSN returns the PostgreSQL connection string for the database settings.
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode)
+}
+
+// Redacted returns a copy of the configuration with secrets masked.
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	c.Payment.Methods = append([]string(nil), c.Payment.Methods...)
+	gateways := make(map[string]GatewayConfig, len(c.Payment.Gateways))
+	for method, gateway := range c.Payment.Gateways {
+		if gateway.APIKey != "" {
+			gateway.APIKey = redacted
+		}
+		if gateway.CallbackSecret != "" {
+			gateway.CallbackSecret = redacted
+		}
+		gateways[method] = gateway
+	}
+	c.Payment.Gateways = gateways
+	return c
+}
+
+// String renders the configuration as YAML with secrets masked, so it is
+// safe to print in logs.
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("<config: %v>", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+const redacted = "[REDACTED]"
+
+// Supported database drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Validate reports every invalid odds setting.
+func (o OddsConfig) Validate() error {
+	return errors.Join(o.validate("odds")...)
+}
+
+// validate reports the invalid odds settings under the given key.
+func (o OddsConfig) validate(key string) []error {
+	var errs []error
+	inRange := func(p float64) bool { return p >= 0 && p <= 1 }
+	switch o.Model {
+	case OddsFlat, OddsLevel:
+	case OddsLinear, OddsStreak:
+		if o.Step < 0 {
+			errs = append(errs, fmt.Errorf("%s.step must not be negative", key))
+		}
+		if !inRange(o.Cap) || o.Cap < o.Base {
+			errs = append(errs, fmt.Errorf("%s.cap must be between %s.base and 1", key, key))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.model %q is not supported (use %s, %s, %s or %s)", key, o.Model, OddsFlat, OddsLinear, OddsStreak, OddsLevel))
+	}
+	if !inRange(o.Base) {
+		errs = append(errs, fmt.Errorf("%s.base must be between 0 and 1", key))
+	}
+	for level, chance := range o.Levels {
+		if !inRange(chance) {
+			errs = append(errs, fmt.Errorf("%s.levels.%s must be between 0 and 1", key, level))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,193 @@
+// config/config_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+}
+
+func TestLoadEnvOverridesDefaults(t *testing.T) {
+	env := map[string]string{
+		"SERVER_ADDR":          ":9090",
+		"DB_HOST":              "localhost",
+		"DB_PORT":              "6543",
+		"DB_PASSWORD":          "s3cret",
+		"CHALLENGE_COOLDOWN":   "2m",
+		"CHALLENGE_ODDS_MODEL": "linear",
+		"CHALLENGE_ODDS_CAP":   "0.2",
+		"PAYMENT_METHODS":      "CreditCard, Blockchain",
+		"FEATURE_SWAGGER":      "false",
+		"IDEMPOTENCY_TTL":      "1h",
+
+		"PAYMENT_GATEWAY_CREDITCARD_URL":             "https://cards.example.com",
+		"PAYMENT_GATEWAY_CREDITCARD_API_KEY":         "sk_test",
+		"PAYMENT_GATEWAY_BLOCKCHAIN_TIMEOUT":         "1m",
+		"PAYMENT_GATEWAY_BLOCKCHAIN_CALLBACK_SECRET": "cb_test",
+	}
+	cfg := Default()
+	err := cfg.loadEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, 6543, cfg.Database.Port)
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+	assert.Equal(t, 2*time.Minute, cfg.Challenge.Cooldown)
+	assert.Equal(t, OddsConfig{Model: OddsLinear, Base: 0.01, Step: 0.005, Cap: 0.2}, cfg.Challenge.Odds["endless"])
+	assert.Equal(t, []string{"CreditCard", "Blockchain"}, cfg.Payment.Methods)
+	assert.False(t, cfg.Features.Swagger)
+	assert.Equal(t, time.Hour, cfg.Idempotency.TTL)
+	assert.Equal(t, GatewayConfig{URL: "https://cards.example.com", APIKey: "sk_test", Timeout: 10 * time.Second}, cfg.Payment.Gateways["CreditCard"])
+	assert.Equal(t, time.Minute, cfg.Payment.Gateways["Blockchain"].Timeout)
+	assert.Equal(t, "cb_test", cfg.Payment.Gateways["Blockchain"].CallbackSecret)
+	// Untouched settings keep their defaults
+	assert.Equal(t, "spinnerdb", cfg.Database.Name)
+}
+
+func TestLoadEnvRejectsMalformedValues(t *testing.T) {
+	cfg := Default()
+	err := cfg.loadEnv(func(key string) (string, bool) {
+		if key == "DB_PORT" {
+			return "not-a-number", true
+		}
+		return "", false
+	})
+	assert.ErrorContains(t, err, "DB_PORT")
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(yamlPath, []byte(`
+server:
+  addr: ":7070"
+challenge:
+  duration: 45s
+`), 0o600))
+
+	jsonPath := filepath.Join(dir, "config.json")
+	assert.NoError(t, os.WriteFile(jsonPath, []byte(`{"database": {"max_open_conns": 50}}`), 0o600))
+
+	cfg := Default()
+	assert.NoError(t, cfg.loadFile(yamlPath))
+	assert.NoError(t, cfg.loadFile(jsonPath))
+
+	assert.Equal(t, ":7070", cfg.Server.Addr)
+	assert.Equal(t, 45*time.Second, cfg.Challenge.Duration)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 5432, cfg.Database.Port)
+}
+
+func TestLoadPrefersEnvOverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("database:\n  host: from-file\n"), 0o600))
+
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DB_HOST", "from-env")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Database.Host)
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Port = 0
+	cfg.Challenge.EntryFee = 0
+	cfg.Challenge.Rake = 1
+	cfg.Payment.Methods = nil
+	cfg.Idempotency.TTL = 0
+	cfg.Webhook.BackoffMax = time.Second
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "database.port")
+	assert.ErrorContains(t, err, "challenge.entry_fee")
+	assert.ErrorContains(t, err, "challenge.rake")
+	assert.ErrorContains(t, err, "payment.methods")
+	assert.ErrorContains(t, err, "idempotency.ttl")
+	assert.ErrorContains(t, err, "webhook.backoff_base")
+}
+
+func TestValidateGateways(t *testing.T) {
+	cfg := Default()
+	delete(cfg.Payment.Gateways, "Blockchain")
+	cfg.Payment.Gateways["CreditCard"] = GatewayConfig{URL: "localhost:9090", Timeout: time.Second}
+	cfg.Payment.Gateways["ThirdParty"] = GatewayConfig{URL: "http://localhost:9090"}
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "payment.gateways must configure the Blockchain method")
+	assert.ErrorContains(t, err, "payment.gateways.CreditCard.url")
+	assert.ErrorContains(t, err, "payment.gateways.ThirdParty.timeout")
+
+	// Disabled methods need no gateway
+	cfg = Default()
+	cfg.Payment.Methods = []string{"CreditCard"}
+	cfg.Payment.Gateways = map[string]GatewayConfig{"CreditCard": cfg.Payment.Gateways["CreditCard"]}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateOdds(t *testing.T) {
+	cfg := Default()
+	cfg.Challenge.Odds["streak"] = OddsConfig{Model: OddsStreak, Base: 0.1, Step: 0.01, Cap: 0.05}
+	cfg.Challenge.Odds["dice"] = OddsConfig{Model: "dice", Base: 2}
+	cfg.Challenge.Odds["ranked"] = OddsConfig{Model: OddsLevel, Levels: map[string]float64{"1": 1.5}}
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "challenge.odds.streak.cap")
+	assert.ErrorContains(t, err, `challenge.odds.dice.model "dice"`)
+	assert.ErrorContains(t, err, "challenge.odds.dice.base")
+	assert.ErrorContains(t, err, "challenge.odds.ranked.levels.1")
+
+	delete(cfg.Challenge.Odds, "endless")
+	assert.ErrorContains(t, cfg.Validate(), "endless")
+}
+
+func TestValidateSQLiteOnlyNeedsPath(t *testing.T) {
+	cfg := Default()
+	cfg.Database = DatabaseConfig{Driver: DriverSQLite, Path: "test.db"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Database.Path = ""
+	assert.ErrorContains(t, cfg.Validate(), "database.path")
+
+	cfg.Database.Driver = "mysql"
+	assert.ErrorContains(t, cfg.Validate(), "not supported")
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "hunter2"
+	cfg.Payment.Gateways["CreditCard"] = GatewayConfig{URL: "https://cards.example.com", APIKey: "sk_live", Timeout: time.Second, CallbackSecret: "cb_live"}
+
+	out := cfg.String()
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "sk_live")
+	assert.NotContains(t, out, "cb_live")
+	assert.True(t, strings.Contains(out, redacted))
+	// The original is left untouched
+	assert.Equal(t, "hunter2", cfg.Database.Password)
+	assert.Equal(t, "sk_live", cfg.Payment.Gateways["CreditCard"].APIKey)
+}
+
+func TestExampleFileIsValid(t *testing.T) {
+	cfg := Default()
+	assert.NoError(t, cfg.loadFile(filepath.Join("..", "config.example.yaml")))
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, Default().Challenge, cfg.Challenge)
+	assert.Equal(t, Default().Payment, cfg.Payment)
+	assert.Equal(t, Default().Idempotency, cfg.Idempotency)
+	assert.Equal(t, Default().Webhook, cfg.Webhook)
+}
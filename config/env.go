@@ -0,0 +1,235 @@
+// config/env.go
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupFunc matches os.LookupEnv so tests can supply their own environment.
+type lookupFunc func(key string) (string, bool)
+
+// loadEnv overlays settings from environment variables. The DB_* names match
+// the ones already set in docker-compose.yml.
+func (c *Config) loadEnv(lookup lookupFunc) error {
+	bindings := []binding{
+		{"SERVER_ADDR", setString(&c.Server.Addr)},
+		{"SERVER_READ_TIMEOUT", setDuration(&c.Server.ReadTimeout)},
+		{"SERVER_WRITE_TIMEOUT", setDuration(&c.Server.WriteTimeout)},
+		{"SERVER_SHUTDOWN_TIMEOUT", setDuration(&c.Server.ShutdownTimeout)},
+
+		{"DB_DRIVER", setString(&c.Database.Driver)},
+		{"DB_PATH", setString(&c.Database.Path)},
+		{"DB_HOST", setString(&c.Database.Host)},
+		{"DB_PORT", setInt(&c.Database.Port)},
+		{"DB_USER", setString(&c.Database.User)},
+		{"DB_PASSWORD", setString(&c.Database.Password)},
+		{"DB_NAME", setString(&c.Database.Name)},
+		{"DB_SSLMODE", setString(&c.Database.SSLMode)},
+		{"DB_MAX_OPEN_CONNS", setInt(&c.Database.MaxOpenConns)},
+		{"DB_MAX_IDLE_CONNS", setInt(&c.Database.MaxIdleConns)},
+		{"DB_CONN_MAX_LIFETIME", setDuration(&c.Database.ConnMaxLifetime)},
+
+		{"CHALLENGE_ENTRY_FEE", setInt64(&c.Challenge.EntryFee)},
+		{"CHALLENGE_DURATION", setDuration(&c.Challenge.Duration)},
+		{"CHALLENGE_COOLDOWN", setDuration(&c.Challenge.Cooldown)},
+		{"CHALLENGE_RESULTS_LIMIT", setInt(&c.Challenge.ResultsLimit)},
+		{"CHALLENGE_RAKE", setFloat(&c.Challenge.Rake)},
+		{"CHALLENGE_JACKPOT_SEED", setInt64(&c.Challenge.JackpotSeed)},
+		{"CHALLENGE_RESOLVE_INTERVAL", setDuration(&c.Challenge.ResolveInterval)},
+		{"CHALLENGE_ODDS_MODEL", c.setOdds(func(o *OddsConfig) func(string) error { return setString(&o.Model) })},
+		{"CHALLENGE_ODDS_BASE", c.setOdds(func(o *OddsConfig) func(string) error { return setFloat(&o.Base) })},
+		{"CHALLENGE_ODDS_STEP", c.setOdds(func(o *OddsConfig) func(string) error { return setFloat(&o.Step) })},
+		{"CHALLENGE_ODDS_CAP", c.setOdds(func(o *OddsConfig) func(string) error { return setFloat(&o.Cap) })},
+
+		{"PAYMENT_METHODS", setList(&c.Payment.Methods)},
+		{"PAYMENT_MIN_AMOUNT", setInt64(&c.Payment.MinAmount)},
+		{"PAYMENT_MAX_AMOUNT", setInt64(&c.Payment.MaxAmount)},
+		{"PAYMENT_PROCESS_INTERVAL", setDuration(&c.Payment.ProcessInterval)},
+		{"PAYMENT_AUDIT_INTERVAL", setDuration(&c.Payment.AuditInterval)},
+		{"PAYMENT_MAX_WAIT", setDuration(&c.Payment.MaxWait)},
+		{"PAYMENT_CALLBACK_TOLERANCE", setDuration(&c.Payment.CallbackTolerance)},
+
+		{"RESERVATION_CANCELLATION_WINDOW", setDuration(&c.Reservation.CancellationWindow)},
+		{"RESERVATION_NO_SHOW_GRACE", setDuration(&c.Reservation.NoShowGrace)},
+		{"RESERVATION_SWEEP_INTERVAL", setDuration(&c.Reservation.SweepInterval)},
+
+		{"REALTIME_SEND_BUFFER", setInt(&c.Realtime.SendBuffer)},
+		{"REALTIME_PING_INTERVAL", setDuration(&c.Realtime.PingInterval)},
+		{"REALTIME_PONG_TIMEOUT", setDuration(&c.Realtime.PongTimeout)},
+		{"REALTIME_WRITE_TIMEOUT", setDuration(&c.Realtime.WriteTimeout)},
+
+		{"IDEMPOTENCY_TTL", setDuration(&c.Idempotency.TTL)},
+		{"IDEMPOTENCY_WAIT_TIMEOUT", setDuration(&c.Idempotency.WaitTimeout)},
+		{"IDEMPOTENCY_LOCK_TIMEOUT", setDuration(&c.Idempotency.LockTimeout)},
+		{"IDEMPOTENCY_PURGE_INTERVAL", setDuration(&c.Idempotency.PurgeInterval)},
+
+		{"WEBHOOK_DISPATCH_INTERVAL", setDuration(&c.Webhook.DispatchInterval)},
+		{"WEBHOOK_TIMEOUT", setDuration(&c.Webhook.Timeout)},
+		{"WEBHOOK_MAX_ATTEMPTS", setInt(&c.Webhook.MaxAttempts)},
+		{"WEBHOOK_BACKOFF_BASE", setDuration(&c.Webhook.BackoffBase)},
+		{"WEBHOOK_BACKOFF_MAX", setDuration(&c.Webhook.BackoffMax)},
+
+		{"FEATURE_SWAGGER", setBool(&c.Features.Swagger)},
+		{"FEATURE_CHALLENGES", setBool(&c.Features.Challenges)},
+		{"FEATURE_PAYMENTS", setBool(&c.Features.Payments)},
+	}
+
+	if err := applyEnv(lookup, bindings); err != nil {
+		return err
+	}
+
+	// Gateways are bound per method once PAYMENT_METHODS has been applied,
+	// e.g. PAYMENT_GATEWAY_CREDITCARD_URL
+	var gateways []binding
+	for _, method := range c.gatewayMethods() {
+		prefix := "PAYMENT_GATEWAY_" + strings.ToUpper(method)
+		gateways = append(gateways,
+			binding{prefix + "_URL", c.setGateway(method, func(g *GatewayConfig) func(string) error { return setString(&g.URL) })},
+			binding{prefix + "_API_KEY", c.setGateway(method, func(g *GatewayConfig) func(string) error { return setString(&g.APIKey) })},
+			binding{prefix + "_TIMEOUT", c.setGateway(method, func(g *GatewayConfig) func(string) error { return setDuration(&g.Timeout) })},
+			binding{prefix + "_CALLBACK_SECRET", c.setGateway(method, func(g *GatewayConfig) func(string) error { return setString(&g.CallbackSecret) })},
+		)
+	}
+	return applyEnv(lookup, gateways)
+}
+
+// binding ties an environment variable to the setting it overrides.
+type binding struct {
+	key string
+	set func(string) error
+}
+
+func applyEnv(lookup lookupFunc, bindings []binding) error {
+	for _, b := range bindings {
+		value, ok := lookup(b.key)
+		if !ok {
+			continue
+		}
+		if err := b.set(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("invalid %s: %w", b.key, err)
+		}
+	}
+	return nil
+}
+
+// gatewayMethods lists the enabled methods and those with a gateway configured.
+func (c *Config) gatewayMethods() []string {
+	methods := append([]string(nil), c.Payment.Methods...)
+	for method := range c.Payment.Gateways {
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// setGateway applies a setter to the gateway of a payment method.
+func (c *Config) setGateway(method string, field func(g *GatewayConfig) func(string) error) func(string) error {
+	return func(v string) error {
+		if c.Payment.Gateways == nil {
+			c.Payment.Gateways = map[string]GatewayConfig{}
+		}
+		gateway := c.Payment.Gateways[method]
+		if err := field(&gateway)(v); err != nil {
+			return err
+		}
+		c.Payment.Gateways[method] = gateway
+		return nil
+	}
+}
+
+// setOdds applies a setter to the odds of the endless challenge type, the
+// one entries without a type use. Other types are configured in the file.
+func (c *Config) setOdds(field func(o *OddsConfig) func(string) error) func(string) error {
+	return func(v string) error {
+		if c.Challenge.Odds == nil {
+			c.Challenge.Odds = map[string]OddsConfig{}
+		}
+		odds := c.Challenge.Odds["endless"]
+		if err := field(&odds)(v); err != nil {
+			return err
+		}
+		c.Challenge.Odds["endless"] = odds
+		return nil
+	}
+}
+
+func setString(dst *string) func(string) error {
+	return func(v string) error {
+		*dst = v
+		return nil
+	}
+}
+
+func setInt(dst *int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	}
+}
+
+func setInt64(dst *int64) func(string) error {
+	return func(v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	}
+}
+
+func setFloat(dst *float64) func(string) error {
+	return func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*dst = f
+		return nil
+	}
+}
+
+func setBool(dst *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*dst = b
+		return nil
+	}
+}
+
+func setDuration(dst *time.Duration) func(string) error {
+	return func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*dst = d
+		return nil
+	}
+}
+
+// setList parses a comma-separated list, dropping empty items.
+func setList(dst *[]string) func(string) error {
+	return func(v string) error {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+		return nil
+	}
+}
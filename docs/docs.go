@@ -15,9 +15,36 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/challenges": {
+        "/challenge-definitions": {
+            "get": {
+                "description": "Retrieve every challenge definition, including ones outside their active window",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Challenges"
+                ],
+                "summary": "List challenge definitions",
+                "responses": {
+                    "200": {
+                        "description": "A list of challenge definitions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ChallengeDefinition"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Players can participate in an endless challenge by paying 20.01.",
+                "description": "Add a challenge players can enter with POST /challenges by its ID, with its own entry fee, duration, cooldown, odds model, active window and daily entry limit per player.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,101 +54,85 @@ const docTemplate = `{
                 "tags": [
                     "Challenges"
                 ],
-                "summary": "Participate in a Challenge",
+                "summary": "Create a challenge definition",
                 "parameters": [
                     {
-                        "description": "Challenge Participation",
-                        "name": "challenge",
+                        "description": "Challenge definition",
+                        "name": "definition",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ChallengeRequest"
+                            "$ref": "#/definitions/models.ChallengeDefinition"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Challenge started",
+                    "201": {
+                        "description": "Successfully created definition ID",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/challenges/results": {
+        "/challenge-definitions/{id}": {
             "get": {
-                "description": "Retrieve a list of recent challenge results.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieve a challenge definition by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Challenges"
                 ],
-                "summary": "Get Recent Challenge Results",
+                "summary": "Get a challenge definition",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Maximum number of results to return",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Definition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "A list of recent challenges",
+                        "description": "Challenge definition",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Challenge"
-                            }
+                            "$ref": "#/definitions/models.ChallengeDefinition"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/levels": {
-            "get": {
-                "description": "Retrieve a list of all levels",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "levels"
-                ],
-                "summary": "Get all levels",
-                "responses": {
-                    "200": {
-                        "description": "A list of levels",
+                    },
+                    "404": {
+                        "description": "Definition not found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Level"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -132,8 +143,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create a new level in the system",
+            "put": {
+                "description": "Replace the settings of a challenge definition. Entries already made keep the fee, resolve time and win chance they were given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -141,28 +152,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "levels"
+                    "Challenges"
                 ],
-                "summary": "Add a new level",
+                "summary": "Update a challenge definition",
                 "parameters": [
                     {
-                        "description": "Level Information",
-                        "name": "level",
+                        "type": "integer",
+                        "description": "Definition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Challenge definition",
+                        "name": "definition",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Level"
+                            "$ref": "#/definitions/models.ChallengeDefinition"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created level ID",
+                    "200": {
+                        "description": "Update status",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
@@ -171,68 +186,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Definition not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/logs": {
-            "get": {
-                "description": "Retrieve a list of game logs with optional filters.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Logs"
-                ],
-                "summary": "Retrieve Game Logs",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Filter by Player ID",
-                        "name": "player_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by Action Type (e.g., Register, Login)",
-                        "name": "action",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter logs from this time (RFC3339 format)",
-                        "name": "start_time",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter logs up to this time (RFC3339 format)",
-                        "name": "end_time",
-                        "in": "query"
                     },
-                    {
-                        "type": "integer",
-                        "description": "Maximum number of logs to return",
-                        "name": "limit",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "A list of game logs",
+                    "409": {
+                        "description": "Name already taken",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Log"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -243,34 +206,29 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create a new game operation log.",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Remove a challenge definition nobody has entered. Definitions with entries are ended by setting ends_at instead.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Logs"
+                    "Challenges"
                 ],
-                "summary": "Create a Game Log",
+                "summary": "Delete a challenge definition",
                 "parameters": [
                     {
-                        "description": "Game Log Information",
-                        "name": "log",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LogRequest"
-                        }
+                        "type": "integer",
+                        "description": "Definition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "New Log ID",
+                        "description": "Deletion status",
                         "schema": {
-                            "$ref": "#/definitions/handlers.LogResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
@@ -279,6 +237,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Definition not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Definition has entries",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -288,9 +258,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/payments": {
+        "/challenges": {
             "post": {
-                "description": "Process a payment using various payment methods.",
+                "description": "Players can participate in an endless challenge by paying the entry fee (2001 cents by default) from their wallet. The fee, less the house rake, is added to the jackpot and winnings are credited back to the wallet. The win chance is fixed at entry by the odds model configured for endless challenges; the outcome is decided at resolve_at. Naming a definition_id enters that challenge definition instead, with its own fee, duration, cooldown, odds and daily entry limit, while it is running.",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,45 +268,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payments"
+                    "Challenges"
                 ],
-                "summary": "Process a Payment",
+                "summary": "Participate in a Challenge",
                 "parameters": [
                     {
-                        "description": "Payment Information",
-                        "name": "payment",
+                        "description": "Challenge Participation",
+                        "name": "challenge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.PaymentRequest"
+                            "$ref": "#/definitions/handlers.ChallengeRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a retry with the same key and body gets the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Payment Status",
+                        "description": "Challenge started",
                         "schema": {
-                            "$ref": "#/definitions/models.Payment"
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.Payment"
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Wallet cannot cover the entry fee",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Challenge definition not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Challenge definition is not running, or a request with the same Idempotency-Key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/models.Payment"
+                            "$ref": "#/definitions/handlers.ChallengeResponse"
                         }
                     }
                 }
             }
         },
-        "/payments/{id}": {
+        "/challenges/jackpot": {
             "get": {
-                "description": "Retrieve detailed information about a specific payment.",
+                "description": "Retrieve the current jackpot, which the next winning challenge takes in full",
                 "consumes": [
                     "application/json"
                 ],
@@ -344,29 +344,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payments"
-                ],
-                "summary": "Get Payment Details",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Payment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Challenges"
                 ],
+                "summary": "Get the jackpot",
                 "responses": {
                     "200": {
-                        "description": "Payment Details",
-                        "schema": {
-                            "$ref": "#/definitions/models.Payment"
-                        }
-                    },
-                    "404": {
-                        "description": "Payment Not Found",
+                        "description": "Current jackpot",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/handlers.JackpotResponse"
                         }
                     },
                     "500": {
@@ -378,9 +363,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/players": {
+        "/challenges/jackpot/payouts": {
             "get": {
-                "description": "Retrieve a list of all players with their level information",
+                "description": "Retrieve the most recent jackpot payouts, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -388,16 +373,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "Challenges"
+                ],
+                "summary": "Get jackpot payouts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of payouts to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all players",
                 "responses": {
                     "200": {
-                        "description": "A list of players",
+                        "description": "A list of payouts",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Player"
+                                "$ref": "#/definitions/models.JackpotPayout"
                             }
                         }
                     },
@@ -408,9 +401,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new player with a specified level",
+            }
+        },
+        "/challenges/results": {
+            "get": {
+                "description": "Retrieve a list of recent challenge results.",
                 "consumes": [
                     "application/json"
                 ],
@@ -418,36 +413,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "Challenges"
                 ],
-                "summary": "Register a new player",
+                "summary": "Get Recent Challenge Results",
                 "parameters": [
                     {
-                        "description": "Player Information",
-                        "name": "player",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Player"
-                        }
+                        "type": "integer",
+                        "description": "Maximum number of results to return",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created player ID",
+                    "200": {
+                        "description": "A list of recent challenges",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Challenge"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -457,23 +443,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/players/{id}": {
+        "/challenges/{id}": {
             "get": {
-                "description": "Retrieve detailed information of a player by their ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieve a challenge with its status, pending or resolved, and the seconds left until its outcome is decided. Players poll this after entering.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "Challenges"
                 ],
-                "summary": "Get player by ID",
+                "summary": "Get a challenge",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Player ID",
+                        "type": "integer",
+                        "description": "Challenge ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -481,13 +464,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Player details",
+                        "description": "Challenge status",
                         "schema": {
-                            "$ref": "#/definitions/models.Player"
+                            "$ref": "#/definitions/handlers.ChallengeStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Player not found",
+                        "description": "Challenge not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -499,42 +488,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update the information of an existing player",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/challenges/{id}/verify": {
+            "get": {
+                "description": "Reveal the server seed of a resolved challenge together with the client seed, nonce, roll and win chance its outcome was derived from. The seed is revealed once every challenge played with it is resolved; the player's seed pair is replaced as soon as the first of them is. The seed hashes to the server_seed_hash published before entry, and HMAC-SHA256(server_seed, client_seed + \":\" + nonce) reproduces the roll; the challenge is won when roll \u003c win_chance.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "Challenges"
                 ],
-                "summary": "Update player information",
+                "summary": "Verify a challenge outcome",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Player ID",
+                        "type": "integer",
+                        "description": "Challenge ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated Player Information",
-                        "name": "player",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Player"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Update status",
+                        "description": "Outcome proof",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/handlers.ChallengeVerification"
                         }
                     },
                     "400": {
@@ -543,40 +522,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Challenge not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Remove a player from the system by their ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "players"
-                ],
-                "summary": "Delete a player",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Player ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Deletion status",
+                    },
+                    "409": {
+                        "description": "Challenge, or another played with the same server seed, not resolved yet, or seeded when resolved and so not verifiable",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -588,9 +543,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/reservations": {
+        "/levels": {
             "get": {
-                "description": "Retrieve reservations with optional filters for room ID, date, and limit",
+                "description": "Retrieve a list of all levels",
                 "consumes": [
                     "application/json"
                 ],
@@ -598,36 +553,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
-                ],
-                "summary": "Get reservations",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Room ID to filter reservations",
-                        "name": "room_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Date to filter reservations (YYYY-MM-DD)",
-                        "name": "date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum number of reservations to return",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "levels"
                 ],
+                "summary": "Get all levels",
                 "responses": {
                     "200": {
-                        "description": "A list of reservations",
+                        "description": "A list of levels",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Reservation"
+                                "$ref": "#/definitions/models.Level"
                             }
                         }
                     },
@@ -640,7 +575,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Add a new reservation for a game room",
+                "description": "Create a new level in the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -648,27 +583,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "levels"
                 ],
-                "summary": "Create a new reservation",
+                "summary": "Add a new level",
                 "parameters": [
                     {
-                        "description": "Reservation Information",
-                        "name": "reservation",
+                        "description": "Level Information",
+                        "name": "level",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReservationInput"
+                            "$ref": "#/definitions/models.Level"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created reservation ID",
+                        "description": "Successfully created level ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "integer"
+                                "type": "string"
                             }
                         }
                     },
@@ -687,9 +622,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms": {
+        "/logs": {
             "get": {
-                "description": "Retrieve a list of all game rooms with their details",
+                "description": "Retrieve a list of game logs with optional filters.",
                 "consumes": [
                     "application/json"
                 ],
@@ -697,16 +632,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "Logs"
+                ],
+                "summary": "Retrieve Game Logs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by Player ID",
+                        "name": "player_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by Action Type (e.g., Register, Login)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter logs from this time (RFC3339 format)",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter logs up to this time (RFC3339 format)",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of logs to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all game rooms",
                 "responses": {
                     "200": {
-                        "description": "A list of game rooms",
+                        "description": "A list of game logs",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Room"
+                                "$ref": "#/definitions/models.Log"
                             }
                         }
                     },
@@ -719,7 +686,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Add a new game room with specified name and description",
+                "description": "Create a new game operation log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -727,28 +694,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "Logs"
                 ],
-                "summary": "Create a new game room",
+                "summary": "Create a Game Log",
                 "parameters": [
                     {
-                        "description": "Room Information",
-                        "name": "room",
+                        "description": "Game Log Information",
+                        "name": "log",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Room"
+                            "$ref": "#/definitions/handlers.LogRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created room ID",
+                    "200": {
+                        "description": "New Log ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/handlers.LogResponse"
                         }
                     },
                     "400": {
@@ -766,9 +730,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{id}": {
-            "get": {
-                "description": "Retrieve detailed information of a game room by its ID",
+        "/matches": {
+            "post": {
+                "description": "Start a new OXO match between two players seated in a room. X moves first. A room holds one match in progress at a time.",
                 "consumes": [
                     "application/json"
                 ],
@@ -776,13 +740,65 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "matches"
                 ],
-                "summary": "Get room details by ID",
+                "summary": "Create a match",
+                "parameters": [
+                    {
+                        "description": "Match Information",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created match",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Players not seated, room closed or already in a match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}": {
+            "get": {
+                "description": "Retrieve the board, turn, outcome and move history of a match",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Get match state",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Match ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -790,13 +806,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Room details",
+                        "description": "Match state",
                         "schema": {
-                            "$ref": "#/definitions/models.Room"
+                            "$ref": "#/definitions/handlers.MatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Match not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -808,9 +830,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update the information of an existing game room",
+            }
+        },
+        "/matches/{id}/moves": {
+            "post": {
+                "description": "Place the player's mark on the board. Rows and columns start at 0.",
                 "consumes": [
                     "application/json"
                 ],
@@ -818,42 +842,208 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "matches"
                 ],
-                "summary": "Update room information",
+                "summary": "Submit a move",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Match ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Room Information",
-                        "name": "room",
+                        "description": "Move",
+                        "name": "move",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Room"
+                            "$ref": "#/definitions/handlers.MoveRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Update status",
+                        "description": "Match state after the move",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/handlers.MatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Move outside the board",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Player is not part of the match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not the player's turn, cell occupied or match over",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments": {
+            "post": {
+                "description": "Accept a payment for processing with the gateway of its method. The payment is created as Pending and charged in the background; follow the Location header, optionally with ?wait=, to learn the outcome.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Process a Payment",
+                "parameters": [
+                    {
+                        "description": "Payment Information",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaymentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a retry with the same key and body gets the first response replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Payment accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaymentResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the payment"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaymentResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaymentResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/callbacks/{provider}": {
+            "post": {
+                "description": "Providers that confirm payments later report the outcome here, signed in the X-Gateway-Signature header as t=\u003cunix seconds\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\" keyed with the callback secret configured for the provider\u003e. The payment is found by the provider's transaction ID. Repeated callbacks are acknowledged without effect; callbacks that would move a payment backwards, such as from Failed to Success, are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Receive a provider callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment method of the provider, in lower case, e.g. banktransfer",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the body",
+                        "name": "X-Gateway-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "The transaction's state at the provider",
+                        "name": "callback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway.Result"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The payment's status after the callback",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed callback",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Unknown provider or transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The payment cannot make the transition",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The callback does not match the payment",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -865,9 +1055,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove a game room from the system by its ID",
+            }
+        },
+        "/payments/{id}": {
+            "get": {
+                "description": "Retrieve detailed information about a specific payment. With wait, a Pending payment is held until it leaves Pending or the wait is over, whichever comes first, and then returned as it stands.",
                 "consumes": [
                     "application/json"
                 ],
@@ -875,27 +1067,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "Payments"
                 ],
-                "summary": "Delete a room",
+                "summary": "Get Payment Details",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Payment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Long-poll for up to this long while the payment is Pending, e.g. 30s; capped at payment.max_wait",
+                        "name": "wait",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Deletion status",
+                        "description": "Payment Details",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payment ID or wait",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Payment Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -908,97 +1112,2216 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.ChallengeRequest": {
-            "type": "object",
-            "required": [
-                "player_id"
-            ],
-            "properties": {
-                "player_id": {
-                    "type": "integer"
+        },
+        "/players": {
+            "get": {
+                "description": "Retrieve a list of all players with their level information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get all players",
+                "responses": {
+                    "200": {
+                        "description": "A list of players",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Player"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new player with a specified level",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Register a new player",
+                "parameters": [
+                    {
+                        "description": "Player Information",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created player ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}": {
+            "get": {
+                "description": "Retrieve detailed information of a player by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get player by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Player details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the information of an existing player",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Update player information",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Player Information",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update status",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a player from the system by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Delete a player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deletion status",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/challenges": {
+            "get": {
+                "description": "Retrieve a page of the challenges a player entered, newest first, with the total number entered",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's challenges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of challenges to return (defaults to the results limit, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of newer challenges to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A page of the player's challenges",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChallengePage"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/challenges/stats": {
+            "get": {
+                "description": "Retrieve how many challenges a player entered, how many are pending and won, the entry fees paid and the jackpots won",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's challenge stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The player's challenge stats",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChallengeStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/ledger": {
+            "get": {
+                "description": "Retrieve the ledger entries of the player's wallet, newest first. Amounts are in cents; positive amounts credit the wallet. Each entry belongs to a transfer whose entries sum to zero.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's ledger",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries to return (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The player's ledger entries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LedgerEntry"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/reservations": {
+            "get": {
+                "description": "Retrieve the reservations a player hosts or is invited to, ordered by start time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get a player's reservations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The player's reservations",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/seed": {
+            "get": {
+                "description": "Retrieve the hash of the server seed the player's next challenges are played with, the client seed used when an entry brings none, and the nonce entries so far took. The server seed is drawn before the player picks a client seed and is replaced once a challenge played with it is resolved, and revealed by verification once all of them are.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's seed commitment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The player's seed commitment",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlayerSeed"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/wallet": {
+            "get": {
+                "description": "Retrieve the player's wallet balance in cents. Successful payments are deposited into it, challenge entry fees are paid from it and jackpot winnings are credited to it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The player's wallet",
+                        "schema": {
+                            "$ref": "#/definitions/models.Wallet"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "description": "Retrieve reservations with their room, host and guests, with optional filters for room ID, date, and limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get reservations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID to filter reservations",
+                        "name": "room_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reservations overlapping this UTC day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of reservations to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A list of reservations",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Book a room from start_at up to (but not including) end_at for a host player and their invited guests. Slots that start in the past or overlap another reservation of the same room are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create a new reservation",
+                "parameters": [
+                    {
+                        "description": "Reservation Information",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created reservation ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slot overlaps an existing reservation",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "get": {
+                "description": "Retrieve a reservation with its room, host and guests, including its status: booked, checked_in, cancelled, no_show or completed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get reservation details by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reservation details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Move a booked reservation to a new slot or change its details; omitted fields keep their current value. Changes close once the reservation is within the cancellation window of its start, and it cannot be moved into that window.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Update a reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Reservation Information",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated reservation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slot overlaps another reservation, or the reservation can no longer be changed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a booked reservation and release its slot. Cancellations close once the reservation is within the cancellation window of its start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Cancel a reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cancelled reservation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation can no longer be cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/check-in": {
+            "post": {
+                "description": "Mark a booked reservation as used. Check-in is open from the no-show grace period before the start until the same period after it; unclaimed reservations then become no_show.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Check in to a reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checked-in reservation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation is not booked or check-in is closed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "get": {
+                "description": "Retrieve a list of all game rooms with their details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get all game rooms",
+                "responses": {
+                    "200": {
+                        "description": "A list of game rooms",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Room"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new game room with specified name, description, capacity (default 2) and opening hours (default 00:00-24:00 UTC). New rooms are open unless created closed or under maintenance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Create a new game room",
+                "parameters": [
+                    {
+                        "description": "Room Information",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created room ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/availability": {
+            "get": {
+                "description": "List the free slots of every room in service on a day. The day, from and to are read in each room's own time zone; slots fall within the room's opening hours and avoid existing reservations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Search room availability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day to search (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "1h",
+                        "description": "Slot length such as 30m or 1h30m",
+                        "name": "duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest slot start (HH:MM)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest slot end (HH:MM, 24:00 for midnight)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Free slots per room",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.RoomAvailability"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}": {
+            "get": {
+                "description": "Retrieve detailed information of a game room by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get room details by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Room details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the information of an existing game room. Status may only be set to closed, maintenance or back to open; other statuses follow from the room's members and matches.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update room information",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Room Information",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update status",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Capacity below the seated players",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a game room from the system by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Delete a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deletion status",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/join": {
+            "post": {
+                "description": "Seat a player in the room. A player can sit in one room at a time, and closed or full rooms cannot be joined.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Join a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Joining player",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MembershipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Room after joining",
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Room full or closed, or player already in a room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/leave": {
+            "post": {
+                "description": "Free the player's seat in the room",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Leave a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Leaving player",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MembershipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Room after leaving",
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Player is not in the room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket that receives room updates, players joining, leaving, connecting and disconnecting, and match moves.\nSend {\"type\":\"move\",\"match_id\":1,\"player_id\":\"...\",\"row\":0,\"col\":0} to play; rejected moves are answered with an error message.",
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Follow a room in real time",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player announced to the room while connected",
+                        "name": "player_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Retrieve every webhook, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "A list of webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to events. Every event is POSTed to it as JSON, signed in the X-Webhook-Signature header as t=\u003cunix seconds\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\" keyed with the secret\u003e. Deliveries answered with anything but a 2xx are retried with exponential backoff. The secret is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Subscribe a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The webhook, including its secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Retrieve a webhook by its ID, without its secret",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unsubscribe a webhook. Its deliveries, including the ones not yet sent, are deleted with it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deletion status",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Retrieve the most recent deliveries to a webhook, newest first, with the payload sent and the outcome of the latest attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook's deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only deliveries with this status: pending, succeeded or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries to return (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The webhook's deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "availability.Window": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway.Result": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChallengePage": {
+            "type": "object",
+            "properties": {
+                "challenges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Challenge"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Challenges the player has entered",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ChallengeRequest": {
+            "type": "object",
+            "required": [
+                "player_id"
+            ],
+            "properties": {
+                "client_seed": {
+                    "description": "Optional, the seed pair's client seed by default; mixed into the outcome so the server cannot pick it alone",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "definition_id": {
+                    "description": "Optional challenge definition to enter instead of the endless challenge",
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "client_seed": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nonce": {
+                    "type": "integer"
+                },
+                "odds_model": {
+                    "type": "string"
+                },
+                "resolve_at": {
+                    "description": "When the outcome will be decided",
+                    "type": "string"
+                },
+                "server_seed_hash": {
+                    "description": "SHA-256 of the secret server seed, as published before entry",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "win_chance": {
+                    "description": "Probability of winning, fixed at entry",
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.ChallengeStatus": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Entry fee in cents",
+                    "type": "integer"
+                },
+                "client_seed": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "definition_id": {
+                    "description": "The challenge definition entered, if any",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nonce": {
+                    "description": "Numbers the entries played with the server seed",
+                    "type": "integer"
+                },
+                "odds_model": {
+                    "description": "Model WinChance was computed with",
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "remaining_seconds": {
+                    "description": "Until resolve_at; 0 once due or resolved",
+                    "type": "integer"
+                },
+                "resolve_at": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "roll": {
+                    "description": "Set once resolved",
+                    "type": "number"
+                },
+                "server_seed_hash": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Picks the odds model in the configuration",
+                    "type": "string"
+                },
+                "win_chance": {
+                    "description": "Probability the roll has to beat, computed at entry",
+                    "type": "number"
+                },
+                "won": {
+                    "description": "Only meaningful once resolved",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.ChallengeVerification": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "integer"
+                },
+                "client_seed": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "integer"
+                },
+                "roll": {
+                    "type": "number"
+                },
+                "server_seed": {
+                    "type": "string"
+                },
+                "server_seed_hash": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Whether the server's own recomputation agrees",
+                    "type": "boolean"
+                },
+                "win_chance": {
+                    "type": "number"
+                },
+                "won": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.JackpotResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "Challenges entered since the last payout",
+                    "type": "integer"
+                },
+                "entry_fee": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "rake": {
+                    "description": "Share of each entry fee kept by the house",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.LogRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "details",
+                "player_id"
+            ],
+            "properties": {
+                "action": {
+                    "description": "e.g., Register, Login, Logout, Enter Room, Exit Room, Participate in Challenge, Challenge Result",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Additional information about the action",
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.LogResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MatchRequest": {
+            "type": "object",
+            "required": [
+                "player_o_id",
+                "player_x_id",
+                "room_id"
+            ],
+            "properties": {
+                "player_o_id": {
+                    "type": "string"
+                },
+                "player_x_id": {
+                    "description": "Moves first",
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Board size, defaults to 3",
+                    "type": "integer"
+                },
+                "win_length": {
+                    "description": "Marks in a row needed to win, defaults to min(size, 5)",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.MatchResponse": {
+            "type": "object",
+            "properties": {
+                "board": {
+                    "description": "Cells row by row, '.' for empty",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MatchMove"
+                    }
+                },
+                "next_turn": {
+                    "description": "X or O while the match is in progress",
+                    "type": "string"
+                },
+                "player_o_id": {
+                    "type": "string"
+                },
+                "player_x_id": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "in_progress, won, draw",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "win_length": {
+                    "type": "integer"
+                },
+                "winner": {
+                    "description": "X or O once won",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MembershipRequest": {
+            "type": "object",
+            "required": [
+                "player_id"
+            ],
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MoveRequest": {
+            "type": "object",
+            "required": [
+                "col",
+                "player_id",
+                "row"
+            ],
+            "properties": {
+                "col": {
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PaymentRequest": {
+            "type": "object"
+        },
+        "handlers.PaymentResponse": {
+            "type": "object",
+            "properties": {
+                "error_message": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReservationConflictResponse": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "$ref": "#/definitions/models.Reservation"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReservationInput": {
+            "type": "object",
+            "required": [
+                "end_at",
+                "host_id",
+                "room_id",
+                "start_at"
+            ],
+            "properties": {
+                "end_at": {
+                    "description": "RFC 3339, must be after start_at",
+                    "type": "string"
+                },
+                "guest_ids": {
+                    "description": "Registered players invited by the host",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "host_id": {
+                    "description": "Registered player making the booking",
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_at": {
+                    "description": "RFC 3339, e.g. \"2024-10-10T14:00:00+08:00\"",
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "IANA zone such as \"Asia/Taipei\", defaults to UTC",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReservationUpdate": {
+            "type": "object",
+            "properties": {
+                "end_at": {
+                    "description": "RFC 3339, must be after the start; keeps the current end when omitted",
+                    "type": "string"
+                },
+                "guest_ids": {
+                    "description": "Replaces the guests when given; [] removes them all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "note": {
+                    "description": "Keeps the current note when empty",
+                    "type": "string"
+                },
+                "start_at": {
+                    "description": "RFC 3339; keeps the current start when omitted",
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "Keeps the current zone when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RoomAvailability": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/availability.Window"
+                    }
+                },
+                "time_zone": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "description": "payment.succeeded, payment.failed, challenge.resolved, jackpot.won",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "payment.succeeded",
+                        "challenge.resolved"
+                    ]
+                },
+                "secret": {
+                    "description": "Signs the deliveries; generated when left out",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/oxo"
+                }
+            }
+        },
+        "models.Challenge": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Entry fee in cents",
+                    "type": "integer"
+                },
+                "client_seed": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "definition_id": {
+                    "description": "The challenge definition entered, if any",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nonce": {
+                    "description": "Numbers the entries played with the server seed",
+                    "type": "integer"
+                },
+                "odds_model": {
+                    "description": "Model WinChance was computed with",
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "resolve_at": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "roll": {
+                    "description": "Set once resolved",
+                    "type": "number"
+                },
+                "server_seed_hash": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Picks the odds model in the configuration",
+                    "type": "string"
+                },
+                "win_chance": {
+                    "description": "Probability the roll has to beat, computed at entry",
+                    "type": "number"
+                },
+                "won": {
+                    "description": "Only meaningful once resolved",
+                    "type": "boolean"
                 }
             }
         },
-        "handlers.ChallengeResponse": {
+        "models.ChallengeDefinition": {
             "type": "object",
             "properties": {
-                "error": {
+                "cooldown_seconds": {
+                    "description": "Between a player's entries",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_seconds": {
+                    "description": "From entry until the outcome is decided",
+                    "type": "integer"
+                },
+                "ends_at": {
+                    "description": "Open-ended when empty",
                     "type": "string"
                 },
+                "entry_fee": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "status": {
+                "max_entries_per_day": {
+                    "description": "Per player and UTC day; 0 for no limit",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Recorded as the type of its entries",
+                    "type": "string"
+                },
+                "odds_base": {
+                    "type": "number"
+                },
+                "odds_cap": {
+                    "type": "number"
+                },
+                "odds_levels": {
+                    "description": "Chance by player level under the level model",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "odds_model": {
+                    "description": "flat, linear, streak or level",
+                    "type": "string"
+                },
+                "odds_step": {
+                    "type": "number"
+                },
+                "starts_at": {
+                    "description": "Open from the start when empty",
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "handlers.LogRequest": {
+        "models.ChallengeStats": {
             "type": "object",
-            "required": [
-                "action",
-                "details",
-                "player_id"
-            ],
             "properties": {
-                "action": {
-                    "description": "e.g., Register, Login, Logout, Enter Room, Exit Room, Participate in Challenge, Challenge Result",
-                    "type": "string"
+                "entries": {
+                    "type": "integer"
                 },
-                "details": {
-                    "description": "Additional information about the action",
-                    "type": "string"
+                "pending": {
+                    "type": "integer"
                 },
                 "player_id": {
                     "type": "integer"
+                },
+                "total_spent": {
+                    "description": "Entry fees paid, in cents",
+                    "type": "integer"
+                },
+                "total_won": {
+                    "description": "Jackpots won, in cents",
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.LogResponse": {
+        "models.ErrorResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
+                "error": {
+                    "type": "string",
+                    "example": "some thing wrong..."
                 }
             }
         },
-        "handlers.PaymentRequest": {
-            "type": "object"
-        },
-        "handlers.ReservationInput": {
+        "models.JackpotPayout": {
             "type": "object",
-            "required": [
-                "date",
-                "player_info",
-                "room_id",
-                "time"
-            ],
             "properties": {
-                "date": {
-                    "description": "Expected format: \"YYYY-MM-DD\"",
-                    "type": "string"
+                "amount": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "challenge_id": {
+                    "type": "integer"
                 },
-                "player_info": {
-                    "description": "JSON or string",
+                "created_at": {
                     "type": "string"
                 },
-                "room_id": {
+                "id": {
                     "type": "integer"
                 },
-                "time": {
-                    "description": "Expected format: \"HH:MM-HH:MM\"",
-                    "type": "string"
+                "player_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Challenge": {
+        "models.LedgerEntry": {
             "type": "object",
             "properties": {
+                "account": {
+                    "type": "string"
+                },
                 "amount": {
-                    "type": "number"
+                    "description": "In cents; positive credits the account",
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
@@ -1006,20 +3329,12 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "player_id": {
-                    "type": "integer"
+                "kind": {
+                    "type": "string"
                 },
-                "won": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "models.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "some thing wrong..."
+                "transfer": {
+                    "description": "e.g. payment:12 or challenge:7:entry",
+                    "type": "string"
                 }
             }
         },
@@ -1057,11 +3372,41 @@ const docTemplate = `{
                 }
             }
         },
+        "models.MatchMove": {
+            "type": "object",
+            "properties": {
+                "col": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mark": {
+                    "type": "string"
+                },
+                "match_id": {
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "seq": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Payment": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "type": "number"
+                    "description": "In cents",
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
@@ -1085,11 +3430,11 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "status": {
-                    "description": "e.g., Pending, Success, Failed",
+                    "description": "e.g., Pending, Success, Failed, Refunded",
                     "type": "string"
                 },
                 "transaction_id": {
-                    "description": "Populated on success",
+                    "description": "The provider's, populated once authorized",
                     "type": "string"
                 },
                 "updated_at": {
@@ -1103,6 +3448,9 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "level": {
+                    "$ref": "#/definitions/models.Level"
+                },
                 "level_id": {
                     "type": "string"
                 },
@@ -1111,25 +3459,71 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PlayerSeed": {
+            "type": "object",
+            "properties": {
+                "client_seed": {
+                    "description": "Used by entries that bring none",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "description": "Challenges entered with the pair; the next one gets nonce + 1",
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "server_seed_hash": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Reservation": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "date": {
+                "end_at": {
+                    "description": "Exclusive, so back-to-back slots do not overlap",
+                    "type": "string"
+                },
+                "guests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Player"
+                    }
+                },
+                "host": {
+                    "$ref": "#/definitions/models.Player"
+                },
+                "host_id": {
+                    "description": "Empty once the host's account is deleted",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "player_info": {
+                "note": {
+                    "description": "Free text; holds the player info of bookings made before hosts",
                     "type": "string"
                 },
+                "room": {
+                    "$ref": "#/definitions/models.Room"
+                },
                 "room_id": {
                     "type": "integer"
                 },
-                "time": {
+                "start_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time_zone": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -1140,6 +3534,14 @@ const docTemplate = `{
         "models.Room": {
             "type": "object",
             "properties": {
+                "capacity": {
+                    "description": "Number of seats, defaults to 2",
+                    "type": "integer"
+                },
+                "closes_at": {
+                    "description": "Local \"HH:MM\", defaults to 24:00; before OpensAt means closing after midnight",
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -1149,10 +3551,31 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RoomMember"
+                    }
+                },
                 "name": {
                     "type": "string"
                 },
+                "opens_at": {
+                    "description": "Local \"HH:MM\", defaults to 00:00",
+                    "type": "string"
+                },
+                "reservations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Reservation"
+                    }
+                },
                 "status": {
+                    "description": "open, full, in_game, closed or maintenance",
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "IANA zone of the opening hours, defaults to UTC",
                     "type": "string"
                 },
                 "updated_at": {
@@ -1160,6 +3583,26 @@ const docTemplate = `{
                 }
             }
         },
+        "models.RoomMember": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "seat": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -1168,6 +3611,94 @@ const docTemplate = `{
                     "example": "success"
                 }
             }
+        },
+        "models.Wallet": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "In cents",
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Only shown when the webhook is created",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "The JSON body sent",
+                    "type": "string"
+                },
+                "response_status": {
+                    "description": "HTTP status of the latest attempt",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,59 @@
+// Package fairness derives challenge outcomes with a commit-reveal scheme
+// that lets anyone check a result after the fact.
+//
+// Each player has a secret server seed, drawn and published only as its
+// SHA-256 hash before the player enters, so the server cannot pick it once
+// it knows the player's client seed. The player may supply a client seed of
+// their own with each entry, and each entry played with the server seed
+// takes the next nonce. The outcome is
+//
+//	roll = first 53 bits of HMAC-SHA256(key: server seed, message: client seed ":" nonce) / 2^53
+//	won  = roll < win chance
+//
+// Once a challenge is resolved its server seed can be revealed, and the
+// player gets a new one. Hashing it shows it is the seed committed to before
+// entry, and recomputing the HMAC shows the roll was not picked by the
+// server.
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+)
+
+// seedBytes is the amount of randomness in a generated seed.
+const seedBytes = 32
+
+// NewSeed returns a hex-encoded random seed from a cryptographic source.
+func NewSeed() (string, error) {
+	b := make([]byte, seedBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Hash returns the hex-encoded SHA-256 of a server seed, the commitment
+// published before the outcome is decided.
+func Hash(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// Roll derives a number uniform in [0, 1) from the seeds and nonce.
+func Roll(serverSeed, clientSeed string, nonce uint64) float64 {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(clientSeed + ":" + strconv.FormatUint(nonce, 10)))
+	sum := mac.Sum(nil)
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+// Verify reports whether a revealed server seed matches the published hash
+// and produces the recorded roll.
+func Verify(serverSeed, serverSeedHash, clientSeed string, nonce uint64, roll float64) bool {
+	return hmac.Equal([]byte(Hash(serverSeed)), []byte(serverSeedHash)) && Roll(serverSeed, clientSeed, nonce) == roll
+}
@@ -0,0 +1,43 @@
+package fairness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSeedIsRandomHex(t *testing.T) {
+	a, err := NewSeed()
+	assert.NoError(t, err)
+	b, err := NewSeed()
+	assert.NoError(t, err)
+	assert.Len(t, a, 2*seedBytes)
+	assert.NotEqual(t, a, b)
+}
+
+func TestHashIsSHA256(t *testing.T) {
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Hash(""))
+}
+
+func TestRollIsDeterministicAndInRange(t *testing.T) {
+	r := Roll("server", "client", 1)
+	assert.Equal(t, r, Roll("server", "client", 1))
+	assert.NotEqual(t, r, Roll("server", "client", 2))
+	assert.NotEqual(t, r, Roll("server", "other", 1))
+	assert.NotEqual(t, r, Roll("other", "client", 1))
+
+	for nonce := uint64(0); nonce < 1000; nonce++ {
+		r := Roll("server", "client", nonce)
+		assert.GreaterOrEqual(t, r, 0.0)
+		assert.Less(t, r, 1.0)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	seed := "server"
+	roll := Roll(seed, "client", 3)
+	assert.True(t, Verify(seed, Hash(seed), "client", 3, roll))
+	assert.False(t, Verify("forged", Hash(seed), "client", 3, roll))
+	assert.False(t, Verify(seed, Hash(seed), "client", 4, roll))
+	assert.False(t, Verify(seed, Hash(seed), "client", 3, roll/2))
+}
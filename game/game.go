@@ -0,0 +1,243 @@
+// game/game.go
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Mark is the symbol occupying a board cell.
+type Mark byte
+
+const (
+	Empty Mark = '.'
+	X     Mark = 'X'
+	O     Mark = 'O'
+)
+
+func (m Mark) String() string {
+	if m == X || m == O {
+		return string(m)
+	}
+	return ""
+}
+
+// Opponent returns the other player's mark.
+func (m Mark) Opponent() Mark {
+	if m == X {
+		return O
+	}
+	return X
+}
+
+// Match status values.
+const (
+	StatusInProgress = "in_progress"
+	StatusWon        = "won"
+	StatusDraw       = "draw"
+)
+
+// Board size limits. WinLength must lie between MinSize and the board size.
+const (
+	MinSize = 3
+	MaxSize = 19
+)
+
+var (
+	ErrInvalidRules = errors.New("invalid game rules")
+	ErrInvalidBoard = errors.New("invalid board")
+	ErrGameOver     = errors.New("game is already over")
+	ErrNotYourTurn  = errors.New("not your turn")
+	ErrOutOfBounds  = errors.New("move is outside the board")
+	ErrCellOccupied = errors.New("cell is already occupied")
+)
+
+// Rules describe a board variant: 3x3 with three in a row is classic OXO,
+// larger boards with a win length of five play like gomoku.
+type Rules struct {
+	Size      int
+	WinLength int
+}
+
+// DefaultWinLength is the win length used when none is given: the whole
+// row on small boards, five in a row on large ones.
+func DefaultWinLength(size int) int {
+	return min(size, 5)
+}
+
+// Validate checks the rules describe a playable board.
+func (r Rules) Validate() error {
+	if r.Size < MinSize || r.Size > MaxSize {
+		return fmt.Errorf("%w: size must be between %d and %d", ErrInvalidRules, MinSize, MaxSize)
+	}
+	if r.WinLength < MinSize || r.WinLength > r.Size {
+		return fmt.Errorf("%w: win length must be between %d and the board size", ErrInvalidRules, MinSize)
+	}
+	return nil
+}
+
+// Game is the state of a single match. X always moves first.
+type Game struct {
+	rules  Rules
+	cells  []Mark
+	turn   Mark
+	status string
+	winner Mark
+	moves  int
+}
+
+// New starts an empty game.
+func New(rules Rules) (*Game, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	cells := make([]Mark, rules.Size*rules.Size)
+	for i := range cells {
+		cells[i] = Empty
+	}
+	return &Game{rules: rules, cells: cells, turn: X, status: StatusInProgress}, nil
+}
+
+// Restore rebuilds a game from an encoded board as returned by Board.
+// Turn order and the outcome are derived from the cells.
+func Restore(rules Rules, board string) (*Game, error) {
+	g, err := New(rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(board) != len(g.cells) {
+		return nil, fmt.Errorf("%w: expected %d cells, got %d", ErrInvalidBoard, len(g.cells), len(board))
+	}
+
+	xCount, oCount := 0, 0
+	for i := 0; i < len(board); i++ {
+		switch mark := Mark(board[i]); mark {
+		case X:
+			xCount++
+		case O:
+			oCount++
+		case Empty:
+		default:
+			return nil, fmt.Errorf("%w: unexpected cell %q", ErrInvalidBoard, board[i])
+		}
+		g.cells[i] = Mark(board[i])
+	}
+	if xCount != oCount && xCount != oCount+1 {
+		return nil, fmt.Errorf("%w: X has %d marks and O has %d", ErrInvalidBoard, xCount, oCount)
+	}
+
+	g.moves = xCount + oCount
+	if xCount > oCount {
+		g.turn = O
+	}
+	for i, mark := range g.cells {
+		if mark != Empty && g.completesLine(i/rules.Size, i%rules.Size) {
+			g.status, g.winner = StatusWon, mark
+			return g, nil
+		}
+	}
+	if g.moves == len(g.cells) {
+		g.status = StatusDraw
+	}
+	return g, nil
+}
+
+// Play places mark at (row, col) after checking the move is legal.
+func (g *Game) Play(mark Mark, row, col int) error {
+	if g.status != StatusInProgress {
+		return ErrGameOver
+	}
+	if mark != g.turn {
+		return ErrNotYourTurn
+	}
+	if row < 0 || row >= g.rules.Size || col < 0 || col >= g.rules.Size {
+		return ErrOutOfBounds
+	}
+	idx := row*g.rules.Size + col
+	if g.cells[idx] != Empty {
+		return ErrCellOccupied
+	}
+
+	g.cells[idx] = mark
+	g.moves++
+	switch {
+	case g.completesLine(row, col):
+		g.status, g.winner = StatusWon, mark
+	case g.moves == len(g.cells):
+		g.status = StatusDraw
+	default:
+		g.turn = mark.Opponent()
+	}
+	return nil
+}
+
+// completesLine reports whether the mark at (row, col) is part of an
+// unbroken line of at least WinLength cells in any direction.
+func (g *Game) completesLine(row, col int) bool {
+	mark := g.At(row, col)
+	for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		count := 1 + g.count(row, col, d[0], d[1], mark) + g.count(row, col, -d[0], -d[1], mark)
+		if count >= g.rules.WinLength {
+			return true
+		}
+	}
+	return false
+}
+
+// count returns how many consecutive cells hold mark walking from (row, col)
+// in direction (dr, dc), excluding the starting cell.
+func (g *Game) count(row, col, dr, dc int, mark Mark) int {
+	n := 0
+	for r, c := row+dr, col+dc; r >= 0 && r < g.rules.Size && c >= 0 && c < g.rules.Size; r, c = r+dr, c+dc {
+		if g.At(r, c) != mark {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// At returns the mark at (row, col).
+func (g *Game) At(row, col int) Mark {
+	return g.cells[row*g.rules.Size+col]
+}
+
+// Board encodes the cells row by row, using '.' for empty cells.
+func (g *Game) Board() string {
+	var b strings.Builder
+	for _, mark := range g.cells {
+		b.WriteByte(byte(mark))
+	}
+	return b.String()
+}
+
+// Rows returns the board split into one string per row.
+func (g *Game) Rows() []string {
+	board := g.Board()
+	rows := make([]string, g.rules.Size)
+	for i := range rows {
+		rows[i] = board[i*g.rules.Size : (i+1)*g.rules.Size]
+	}
+	return rows
+}
+
+// Rules returns the rules the game is played with.
+func (g *Game) Rules() Rules { return g.rules }
+
+// Turn returns the mark that moves next.
+func (g *Game) Turn() Mark { return g.turn }
+
+// Status returns StatusInProgress, StatusWon or StatusDraw.
+func (g *Game) Status() string { return g.status }
+
+// Winner returns the winning mark, or Empty when nobody has won.
+func (g *Game) Winner() Mark {
+	if g.status != StatusWon {
+		return Empty
+	}
+	return g.winner
+}
+
+// Moves returns the number of marks on the board.
+func (g *Game) Moves() int { return g.moves }
@@ -0,0 +1,102 @@
+// game/game_test.go
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func play(t *testing.T, g *Game, moves ...[2]int) {
+	t.Helper()
+	for _, m := range moves {
+		assert.NoError(t, g.Play(g.Turn(), m[0], m[1]))
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	assert.NoError(t, Rules{Size: 3, WinLength: 3}.Validate())
+	assert.NoError(t, Rules{Size: 15, WinLength: 5}.Validate())
+	assert.ErrorIs(t, Rules{Size: 2, WinLength: 2}.Validate(), ErrInvalidRules)
+	assert.ErrorIs(t, Rules{Size: 4, WinLength: 5}.Validate(), ErrInvalidRules)
+	assert.ErrorIs(t, Rules{Size: 20, WinLength: 5}.Validate(), ErrInvalidRules)
+}
+
+func TestRowWin(t *testing.T) {
+	g, err := New(Rules{Size: 3, WinLength: 3})
+	assert.NoError(t, err)
+
+	play(t, g, [2]int{0, 0}, [2]int{1, 0}, [2]int{0, 1}, [2]int{1, 1}, [2]int{0, 2})
+	assert.Equal(t, StatusWon, g.Status())
+	assert.Equal(t, X, g.Winner())
+	assert.ErrorIs(t, g.Play(O, 2, 2), ErrGameOver)
+}
+
+func TestDiagonalWinOnLargerBoard(t *testing.T) {
+	g, err := New(Rules{Size: 5, WinLength: 4})
+	assert.NoError(t, err)
+
+	// O builds the anti-diagonal from (0,4) to (3,1)
+	play(t, g,
+		[2]int{0, 0}, [2]int{0, 4},
+		[2]int{1, 0}, [2]int{1, 3},
+		[2]int{2, 0}, [2]int{2, 2},
+		[2]int{4, 4}, [2]int{3, 1},
+	)
+	assert.Equal(t, StatusWon, g.Status())
+	assert.Equal(t, O, g.Winner())
+}
+
+func TestDraw(t *testing.T) {
+	g, err := New(Rules{Size: 3, WinLength: 3})
+	assert.NoError(t, err)
+
+	// X O X
+	// X O O
+	// O X X
+	play(t, g,
+		[2]int{0, 0}, [2]int{0, 1}, [2]int{0, 2},
+		[2]int{1, 1}, [2]int{1, 0}, [2]int{1, 2},
+		[2]int{2, 1}, [2]int{2, 0}, [2]int{2, 2},
+	)
+	assert.Equal(t, StatusDraw, g.Status())
+	assert.Equal(t, Empty, g.Winner())
+}
+
+func TestIllegalMoves(t *testing.T) {
+	g, err := New(Rules{Size: 3, WinLength: 3})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, g.Play(O, 0, 0), ErrNotYourTurn)
+	assert.ErrorIs(t, g.Play(X, 3, 0), ErrOutOfBounds)
+	assert.ErrorIs(t, g.Play(X, 0, -1), ErrOutOfBounds)
+	assert.NoError(t, g.Play(X, 1, 1))
+	assert.ErrorIs(t, g.Play(O, 1, 1), ErrCellOccupied)
+	assert.Equal(t, 1, g.Moves())
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	g, err := New(Rules{Size: 4, WinLength: 4})
+	assert.NoError(t, err)
+	play(t, g, [2]int{0, 0}, [2]int{3, 3}, [2]int{1, 1})
+
+	restored, err := Restore(g.Rules(), g.Board())
+	assert.NoError(t, err)
+	assert.Equal(t, g.Board(), restored.Board())
+	assert.Equal(t, O, restored.Turn())
+	assert.Equal(t, 3, restored.Moves())
+	assert.Equal(t, []string{"X...", ".X..", "....", "...O"}, restored.Rows())
+}
+
+func TestRestoreDetectsFinishedGames(t *testing.T) {
+	g, err := Restore(Rules{Size: 3, WinLength: 3}, "XXXOO....")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusWon, g.Status())
+	assert.Equal(t, X, g.Winner())
+
+	_, err = Restore(Rules{Size: 3, WinLength: 3}, "XXX......")
+	assert.ErrorIs(t, err, ErrInvalidBoard)
+
+	_, err = Restore(Rules{Size: 3, WinLength: 3}, "XO")
+	assert.ErrorIs(t, err, ErrInvalidBoard)
+}
@@ -0,0 +1,147 @@
+// Package gateway talks to the payment providers behind each payment method.
+//
+// Every provider is reached through the same Gateway interface. A payment is
+// first authorized, which reserves the amount and returns the provider's
+// transaction ID, and then captured, which moves the money. Captured
+// transactions can be refunded and any transaction's state can be looked up.
+//
+// HTTPGateway speaks the protocol below to a configurable base URL, and Stub
+// serves it locally with scriptable outcomes and latency:
+//
+//	POST {base}/authorize                 {"reference", "amount", "details"}
+//	POST {base}/capture                   {"transaction_id", "amount"}
+//	POST {base}/refund                    {"transaction_id", "amount"}
+//	GET  {base}/transactions/{transaction_id}
+//
+// Amounts are in minor units. Every call answers with a Result; a declined
+// call answers 200 with status "declined" and a message, while other
+// non-2xx answers mean the provider could not handle the call at all.
+//
+// Providers that confirm later, such as bank transfers, answer an authorize
+// or capture with status "pending" and report the outcome afterwards by
+// POSTing a Result to the payment service's callback endpoint for them:
+//
+//	POST {payments}/payments/callbacks/{method in lower case}
+//	X-Gateway-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// The signature is keyed with the callback secret shared with the provider
+// and made the same way as the webhook package signs deliveries.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"interview_YangYang_20241010/config"
+)
+
+// Transaction states reported by a provider.
+const (
+	StatusAuthorized = "authorized"
+	StatusPending    = "pending" // The outcome follows by callback
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusDeclined   = "declined"
+)
+
+var (
+	// ErrDeclined is returned when the provider turned the call down, e.g.
+	// for insufficient funds. The Result message says why.
+	ErrDeclined = errors.New("declined by the payment provider")
+	// ErrUnknownTransaction is returned for a transaction ID the provider
+	// does not know.
+	ErrUnknownTransaction = errors.New("unknown transaction")
+	// ErrUnavailable is returned when the provider could not be reached or
+	// failed to handle the call.
+	ErrUnavailable = errors.New("payment provider unavailable")
+	// ErrUnknownMethod is returned by the registry for a method without a
+	// gateway.
+	ErrUnknownMethod = errors.New("no gateway for the payment method")
+)
+
+// CallbackSignatureHeader carries the signature of a provider callback.
+const CallbackSignatureHeader = "X-Gateway-Signature"
+
+// Request asks a provider to authorize a payment.
+type Request struct {
+	Reference string          `json:"reference"` // Our payment ID
+	Amount    int64           `json:"amount"`    // In minor units
+	Details   json.RawMessage `json:"details,omitempty"`
+}
+
+// Result is a provider's answer to any call.
+type Result struct {
+	TransactionID string `json:"transaction_id"`
+	Reference     string `json:"reference,omitempty"`
+	Status        string `json:"status"`
+	Amount        int64  `json:"amount,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// Gateway is a payment provider. Declines are reported as ErrDeclined
+// alongside the Result that carries the provider's message.
+type Gateway interface {
+	Authorize(ctx context.Context, req Request) (*Result, error)
+	Capture(ctx context.Context, transactionID string, amount int64) (*Result, error)
+	Refund(ctx context.Context, transactionID string, amount int64) (*Result, error)
+	Status(ctx context.Context, transactionID string) (*Result, error)
+}
+
+// Registry maps payment methods to the gateways that process them.
+type Registry struct {
+	mu       sync.RWMutex
+	gateways map[string]Gateway
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{gateways: map[string]Gateway{}}
+}
+
+// NewRegistryFromConfig registers an HTTP gateway for every enabled payment
+// method.
+func NewRegistryFromConfig(cfg config.PaymentConfig) (*Registry, error) {
+	r := NewRegistry()
+	for _, method := range cfg.Methods {
+		gc, ok := cfg.Gateways[method]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownMethod, method)
+		}
+		r.Register(method, NewHTTPGateway(gc))
+	}
+	return r, nil
+}
+
+// Register sets the gateway for a method, replacing any earlier one.
+func (r *Registry) Register(method string, g Gateway) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gateways[method] = g
+}
+
+// Get returns the gateway for a method.
+func (r *Registry) Get(method string) (Gateway, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.gateways[method]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownMethod, method)
+	}
+	return g, nil
+}
+
+// Methods lists the registered methods in order.
+func (r *Registry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	methods := make([]string, 0, len(r.gateways))
+	for method := range r.gateways {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStubGateway serves a stub and returns an HTTP gateway pointed at it.
+func newStubGateway(t *testing.T, apiKey string, timeout time.Duration) (*HTTPGateway, *Stub) {
+	t.Helper()
+	stub := NewStub(apiKey)
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return NewHTTPGateway(config.GatewayConfig{URL: srv.URL, APIKey: apiKey, Timeout: timeout}), stub
+}
+
+func TestAuthorizeCaptureRefund(t *testing.T) {
+	g, stub := newStubGateway(t, "sk_test", time.Second)
+	ctx := context.Background()
+
+	auth, err := g.Authorize(ctx, Request{Reference: "7", Amount: 1500})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAuthorized, auth.Status)
+	assert.Equal(t, "7", auth.Reference)
+	assert.True(t, strings.HasPrefix(auth.TransactionID, "tx_"))
+
+	captured, err := g.Capture(ctx, auth.TransactionID, 1500)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, captured.Status)
+
+	// Captures happen once and refunds are capped at what was captured
+	_, err = g.Capture(ctx, auth.TransactionID, 1500)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = g.Refund(ctx, auth.TransactionID, 2000)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	refund, err := g.Refund(ctx, auth.TransactionID, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, refund.Status)
+	assert.Equal(t, int64(1000), refund.Amount)
+	_, err = g.Refund(ctx, auth.TransactionID, 1000)
+	assert.NoError(t, err)
+
+	status, err := g.Status(ctx, auth.TransactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRefunded, status.Status)
+	assert.Equal(t, int64(1500), status.Amount)
+
+	_, err = g.Status(ctx, "tx_missing")
+	assert.ErrorIs(t, err, ErrUnknownTransaction)
+	assert.Equal(t, 2, stub.Calls(OpStatus))
+}
+
+func TestStubRequiresTheAPIKey(t *testing.T) {
+	srv := httptest.NewServer(NewStub("sk_test"))
+	defer srv.Close()
+	g := NewHTTPGateway(config.GatewayConfig{URL: srv.URL, APIKey: "sk_wrong", Timeout: time.Second})
+
+	_, err := g.Authorize(context.Background(), Request{Amount: 100})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "invalid API key")
+}
+
+func TestScriptedOutcomes(t *testing.T) {
+	g, stub := newStubGateway(t, "", time.Second)
+	ctx := context.Background()
+
+	stub.Script(OpAuthorize, Behavior{Outcome: OutcomeDecline, Message: "insufficient funds"})
+	stub.Queue(OpAuthorize, Behavior{}, Behavior{Outcome: OutcomeFail, Message: "maintenance"})
+
+	// Queued behaviors come first, one call each
+	_, err := g.Authorize(ctx, Request{Amount: 100})
+	assert.NoError(t, err)
+	_, err = g.Authorize(ctx, Request{Amount: 100})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "maintenance")
+
+	result, err := g.Authorize(ctx, Request{Amount: 100})
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.Equal(t, StatusDeclined, result.Status)
+	assert.Equal(t, "insufficient funds", result.Message)
+	assert.Equal(t, 3, stub.Calls(OpAuthorize))
+}
+
+func TestLatencyIsBoundedByTheTimeout(t *testing.T) {
+	g, stub := newStubGateway(t, "", 50*time.Millisecond)
+	stub.Script(OpAuthorize, Behavior{Latency: time.Second})
+
+	started := time.Now()
+	_, err := g.Authorize(context.Background(), Request{Amount: 100})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Less(t, time.Since(started), time.Second)
+
+	stub.Script(OpAuthorize, Behavior{Latency: 10 * time.Millisecond})
+	_, err = g.Authorize(context.Background(), Request{Amount: 100})
+	assert.NoError(t, err)
+}
+
+func TestScriptOverHTTP(t *testing.T) {
+	g, stub := newStubGateway(t, "", time.Second)
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	script := func(body string) int {
+		resp, err := http.Post(srv.URL+"/_script", "application/json", strings.NewReader(body))
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, script(`{"op": "void"}`))
+	assert.Equal(t, http.StatusBadRequest, script(`{"op": "capture", "outcome": "maybe"}`))
+	assert.Equal(t, http.StatusBadRequest, script(`{"op": "capture", "latency": "soon"}`))
+	assert.Equal(t, http.StatusNoContent, script(`{"op": "capture", "outcome": "decline", "message": "expired card", "once": true}`))
+
+	auth, err := g.Authorize(context.Background(), Request{Amount: 100})
+	assert.NoError(t, err)
+	_, err = g.Capture(context.Background(), auth.TransactionID, 100)
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.ErrorContains(t, err, "expired card")
+	_, err = g.Capture(context.Background(), auth.TransactionID, 100)
+	assert.NoError(t, err)
+}
+
+func TestPendingTransactionsSettleWithACallback(t *testing.T) {
+	g, stub := newStubGateway(t, "", time.Second)
+	ctx := context.Background()
+	var body []byte
+	var signature string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(CallbackSignatureHeader)
+	}))
+	defer receiver.Close()
+	stub.SetCallback(receiver.URL, "cb_secret")
+	stub.Script(OpCapture, Behavior{Outcome: OutcomePending})
+
+	auth, err := g.Authorize(ctx, Request{Reference: "3", Amount: 900})
+	assert.NoError(t, err)
+	captured, err := g.Capture(ctx, auth.TransactionID, 900)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, captured.Status)
+	_, err = g.Refund(ctx, auth.TransactionID, 900)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	result, err := stub.Settle(auth.TransactionID, StatusCaptured)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{TransactionID: auth.TransactionID, Reference: "3", Status: StatusCaptured, Amount: 900}, result)
+	assert.NoError(t, webhook.Verify("cb_secret", signature, body, time.Now(), time.Minute))
+	var reported Result
+	assert.NoError(t, json.Unmarshal(body, &reported))
+	assert.Equal(t, result, reported)
+
+	// Only pending transactions settle
+	_, err = stub.Settle(auth.TransactionID, StatusDeclined)
+	assert.Error(t, err)
+	_, err = stub.Settle("tx_unknown", StatusCaptured)
+	assert.ErrorIs(t, err, ErrUnknownTransaction)
+	status, err := g.Status(ctx, auth.TransactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, status.Status)
+}
+
+func TestRegistryFromConfig(t *testing.T) {
+	cfg := config.Default().Payment
+	r, err := NewRegistryFromConfig(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BankTransfer", "Blockchain", "CreditCard", "ThirdParty"}, r.Methods())
+
+	_, err = r.Get("Cash")
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+	r.Register("Cash", NewHTTPGateway(config.GatewayConfig{URL: "http://localhost:9090/cash"}))
+	_, err = r.Get("Cash")
+	assert.NoError(t, err)
+
+	delete(cfg.Gateways, "CreditCard")
+	_, err = NewRegistryFromConfig(cfg)
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"interview_YangYang_20241010/config"
+)
+
+// HTTPGateway is a Gateway reached over HTTP at a configurable base URL.
+type HTTPGateway struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPGateway returns a gateway for the given endpoint. The timeout
+// bounds each call.
+func NewHTTPGateway(cfg config.GatewayConfig) *HTTPGateway {
+	return &HTTPGateway{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		apiKey:  cfg.APIKey,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// transactionRequest is the body of capture and refund calls.
+type transactionRequest struct {
+	TransactionID string `json:"transaction_id"`
+	Amount        int64  `json:"amount"`
+}
+
+// Authorize reserves the amount of a payment.
+func (g *HTTPGateway) Authorize(ctx context.Context, req Request) (*Result, error) {
+	return g.do(ctx, http.MethodPost, "/authorize", req)
+}
+
+// Capture moves an authorized amount.
+func (g *HTTPGateway) Capture(ctx context.Context, transactionID string, amount int64) (*Result, error) {
+	return g.do(ctx, http.MethodPost, "/capture", transactionRequest{TransactionID: transactionID, Amount: amount})
+}
+
+// Refund gives back part or all of a captured amount.
+func (g *HTTPGateway) Refund(ctx context.Context, transactionID string, amount int64) (*Result, error) {
+	return g.do(ctx, http.MethodPost, "/refund", transactionRequest{TransactionID: transactionID, Amount: amount})
+}
+
+// Status looks up a transaction.
+func (g *HTTPGateway) Status(ctx context.Context, transactionID string) (*Result, error) {
+	return g.do(ctx, http.MethodGet, "/transactions/"+url.PathEscape(transactionID), nil)
+}
+
+func (g *HTTPGateway) do(ctx context.Context, method, path string, body any) (*Result, error) {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var result Result
+	decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrUnknownTransaction
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		if result.Message != "" {
+			return nil, fmt.Errorf("%w: %s %s: %d %s", ErrUnavailable, method, path, resp.StatusCode, result.Message)
+		}
+		return nil, fmt.Errorf("%w: %s %s: %d", ErrUnavailable, method, path, resp.StatusCode)
+	case decodeErr != nil:
+		return nil, fmt.Errorf("%w: %s %s: %v", ErrUnavailable, method, path, decodeErr)
+	case result.Status == StatusDeclined:
+		return &result, fmt.Errorf("%w: %s", ErrDeclined, result.Message)
+	}
+	return &result, nil
+}
@@ -0,0 +1,417 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/webhook"
+)
+
+// Calls a Stub can be scripted for.
+const (
+	OpAuthorize = "authorize"
+	OpCapture   = "capture"
+	OpRefund    = "refund"
+	OpStatus    = "status"
+)
+
+// Outcomes a Stub can be scripted to answer with.
+const (
+	OutcomeApprove = "approve" // Handle the call normally
+	OutcomeDecline = "decline" // Answer 200 with status declined
+	OutcomeFail    = "fail"    // Answer 503 as if the provider were down
+	OutcomePending = "pending" // Authorize or capture, leaving the outcome to Settle
+)
+
+// Behavior scripts how a Stub answers a call.
+type Behavior struct {
+	Outcome string        `json:"outcome"`
+	Latency time.Duration `json:"latency"`
+	Message string        `json:"message"` // Decline or failure reason
+}
+
+// Stub is a local payment provider serving the gateway protocol in memory.
+// Each call is answered by the next behavior queued for it, or else by the
+// call's default behavior, which approves unless scripted otherwise. Tests
+// script it directly; a stub run as a server is scripted over HTTP with
+//
+//	POST /_script  {"op": "authorize", "outcome": "decline", "latency": "2s", "message": "...", "once": true}
+//
+// Transactions left pending are settled with Settle, or over HTTP with
+//
+//	POST /_settle  {"transaction_id": "tx_...", "status": "captured"}
+//
+// which also sends the signed callback when one is set with SetCallback.
+type Stub struct {
+	apiKey string
+
+	callbackURL    string
+	callbackSecret string
+	client         *http.Client
+
+	mu           sync.Mutex
+	defaults     map[string]Behavior
+	queued       map[string][]Behavior
+	calls        map[string]int
+	transactions map[string]*stubTransaction
+	mux          *http.ServeMux
+}
+
+type stubTransaction struct {
+	reference  string
+	status     string
+	authorized int64
+	captured   int64
+	refunded   int64
+}
+
+// NewStub returns a stub that approves every call. When apiKey is set calls
+// must carry it as a bearer token.
+func NewStub(apiKey string) *Stub {
+	s := &Stub{
+		apiKey:       apiKey,
+		defaults:     map[string]Behavior{},
+		queued:       map[string][]Behavior{},
+		calls:        map[string]int{},
+		transactions: map[string]*stubTransaction{},
+		client:       &http.Client{Timeout: 10 * time.Second},
+		mux:          http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /authorize", s.authorize)
+	s.mux.HandleFunc("POST /capture", s.capture)
+	s.mux.HandleFunc("POST /refund", s.refund)
+	s.mux.HandleFunc("GET /transactions/{id}", s.status)
+	s.mux.HandleFunc("POST /_script", s.script)
+	s.mux.HandleFunc("POST /_settle", s.settle)
+	return s
+}
+
+// SetCallback makes Settle report outcomes to url, signed with secret.
+func (s *Stub) SetCallback(url, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbackURL, s.callbackSecret = url, secret
+}
+
+// Script sets the behavior every later call of op gets unless one is queued.
+func (s *Stub) Script(op string, b Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults[op] = b
+}
+
+// Queue adds behaviors the next calls of op get, one call each.
+func (s *Stub) Queue(op string, bs ...Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued[op] = append(s.queued[op], bs...)
+}
+
+// Calls returns how many calls of op the stub has answered.
+func (s *Stub) Calls(op string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[op]
+}
+
+// Transaction returns the current state of a transaction.
+func (s *Stub) Transaction(id string) (Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.transactions[id]
+	if !ok {
+		return Result{}, false
+	}
+	return tx.result(id, ""), true
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+		writeStub(w, http.StatusUnauthorized, Result{Message: "invalid API key"})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// behave counts a call of op and plays its behavior. It reports whether the
+// call should go on to be handled, and whether it should be left pending.
+func (s *Stub) behave(w http.ResponseWriter, r *http.Request, op string) (handle, pending bool) {
+	s.mu.Lock()
+	s.calls[op]++
+	b := s.defaults[op]
+	if queue := s.queued[op]; len(queue) > 0 {
+		b, s.queued[op] = queue[0], queue[1:]
+	}
+	s.mu.Unlock()
+
+	if b.Latency > 0 {
+		select {
+		case <-time.After(b.Latency):
+		case <-r.Context().Done():
+			return false, false
+		}
+	}
+	switch b.Outcome {
+	case OutcomeDecline:
+		writeStub(w, http.StatusOK, Result{Status: StatusDeclined, Message: b.Message})
+		return false, false
+	case OutcomeFail:
+		writeStub(w, http.StatusServiceUnavailable, Result{Message: b.Message})
+		return false, false
+	}
+	return true, b.Outcome == OutcomePending
+}
+
+func (s *Stub) authorize(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 {
+		writeStub(w, http.StatusBadRequest, Result{Message: "a positive amount is required"})
+		return
+	}
+	handle, pending := s.behave(w, r, OpAuthorize)
+	if !handle {
+		return
+	}
+	id, err := newTransactionID()
+	if err != nil {
+		writeStub(w, http.StatusInternalServerError, Result{Message: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx := &stubTransaction{reference: req.Reference, status: StatusAuthorized, authorized: req.Amount}
+	if pending {
+		tx.status = StatusPending
+	}
+	s.transactions[id] = tx
+	writeStub(w, http.StatusOK, tx.result(id, ""))
+}
+
+func (s *Stub) capture(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, OpCapture, func(tx *stubTransaction, amount int64, pending bool) string {
+		if tx.status != StatusAuthorized {
+			return fmt.Sprintf("cannot capture a %s transaction", tx.status)
+		}
+		if amount > tx.authorized {
+			return "cannot capture more than was authorized"
+		}
+		tx.status, tx.captured = StatusCaptured, amount
+		if pending {
+			tx.status = StatusPending
+		}
+		return ""
+	})
+}
+
+func (s *Stub) refund(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, OpRefund, func(tx *stubTransaction, amount int64, _ bool) string {
+		if tx.status != StatusCaptured {
+			return fmt.Sprintf("cannot refund a %s transaction", tx.status)
+		}
+		if amount > tx.captured-tx.refunded {
+			return "cannot refund more than was captured"
+		}
+		tx.refunded += amount
+		if tx.refunded == tx.captured {
+			tx.status = StatusRefunded
+		}
+		return ""
+	})
+}
+
+// transition applies a capture or refund to a known transaction. apply
+// returns why the transaction cannot make the move, if it cannot.
+func (s *Stub) transition(w http.ResponseWriter, r *http.Request, op string, apply func(tx *stubTransaction, amount int64, pending bool) string) {
+	var req transactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 {
+		writeStub(w, http.StatusBadRequest, Result{Message: "a transaction ID and a positive amount are required"})
+		return
+	}
+	handle, pending := s.behave(w, r, op)
+	if !handle {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.transactions[req.TransactionID]
+	if !ok {
+		writeStub(w, http.StatusNotFound, Result{Message: "unknown transaction"})
+		return
+	}
+	if reason := apply(tx, req.Amount, pending); reason != "" {
+		writeStub(w, http.StatusConflict, tx.result(req.TransactionID, reason))
+		return
+	}
+	writeStub(w, http.StatusOK, tx.result(req.TransactionID, ""))
+}
+
+func (s *Stub) status(w http.ResponseWriter, r *http.Request) {
+	if handle, _ := s.behave(w, r, OpStatus); !handle {
+		return
+	}
+	id := r.PathValue("id")
+	if result, ok := s.Transaction(id); ok {
+		writeStub(w, http.StatusOK, result)
+		return
+	}
+	writeStub(w, http.StatusNotFound, Result{Message: "unknown transaction"})
+}
+
+// scriptRequest is the body of POST /_script. Latency is a Go duration.
+type scriptRequest struct {
+	Op      string `json:"op"`
+	Outcome string `json:"outcome"`
+	Latency string `json:"latency"`
+	Message string `json:"message"`
+	Once    bool   `json:"once"` // Queue the behavior for the next call only
+}
+
+func (s *Stub) script(w http.ResponseWriter, r *http.Request) {
+	var req scriptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStub(w, http.StatusBadRequest, Result{Message: err.Error()})
+		return
+	}
+	switch req.Op {
+	case OpAuthorize, OpCapture, OpRefund, OpStatus:
+	default:
+		writeStub(w, http.StatusBadRequest, Result{Message: fmt.Sprintf("unknown op %q", req.Op)})
+		return
+	}
+	switch req.Outcome {
+	case "", OutcomeApprove, OutcomeDecline, OutcomeFail, OutcomePending:
+	default:
+		writeStub(w, http.StatusBadRequest, Result{Message: fmt.Sprintf("unknown outcome %q", req.Outcome)})
+		return
+	}
+	b := Behavior{Outcome: req.Outcome, Message: req.Message}
+	if req.Latency != "" {
+		latency, err := time.ParseDuration(req.Latency)
+		if err != nil || latency < 0 {
+			writeStub(w, http.StatusBadRequest, Result{Message: fmt.Sprintf("invalid latency %q", req.Latency)})
+			return
+		}
+		b.Latency = latency
+	}
+
+	if req.Once {
+		s.Queue(req.Op, b)
+	} else {
+		s.Script(req.Op, b)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Settle moves a pending transaction to captured or declined and, when a
+// callback is set, reports the outcome to it.
+func (s *Stub) Settle(id, status string) (Result, error) {
+	if status != StatusCaptured && status != StatusDeclined {
+		return Result{}, fmt.Errorf("cannot settle a transaction as %q", status)
+	}
+	s.mu.Lock()
+	tx, ok := s.transactions[id]
+	if !ok {
+		s.mu.Unlock()
+		return Result{}, ErrUnknownTransaction
+	}
+	if tx.status != StatusPending {
+		s.mu.Unlock()
+		return Result{}, fmt.Errorf("cannot settle a %s transaction", tx.status)
+	}
+	tx.status = status
+	if status == StatusCaptured && tx.captured == 0 {
+		tx.captured = tx.authorized
+	}
+	result := tx.result(id, "")
+	url, secret := s.callbackURL, s.callbackSecret
+	s.mu.Unlock()
+
+	if url == "" {
+		return result, nil
+	}
+	return result, s.sendCallback(url, secret, result)
+}
+
+// sendCallback POSTs a signed result to the callback URL.
+func (s *Stub) sendCallback(url, secret string, result Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CallbackSignatureHeader, webhook.Sign(secret, time.Now(), body))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback answered %s", resp.Status)
+	}
+	return nil
+}
+
+// settleRequest is the body of POST /_settle.
+type settleRequest struct {
+	TransactionID string `json:"transaction_id"`
+	Status        string `json:"status"` // captured or declined
+}
+
+func (s *Stub) settle(w http.ResponseWriter, r *http.Request) {
+	var req settleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStub(w, http.StatusBadRequest, Result{Message: err.Error()})
+		return
+	}
+	result, err := s.Settle(req.TransactionID, req.Status)
+	switch {
+	case errors.Is(err, ErrUnknownTransaction):
+		writeStub(w, http.StatusNotFound, Result{Message: "unknown transaction"})
+	case err != nil && result.TransactionID == "":
+		writeStub(w, http.StatusConflict, Result{Message: err.Error()})
+	case err != nil:
+		// Settled, but the callback did not go through
+		result.Message = err.Error()
+		writeStub(w, http.StatusBadGateway, result)
+	default:
+		writeStub(w, http.StatusOK, result)
+	}
+}
+
+func (tx *stubTransaction) result(id, message string) Result {
+	amount := tx.authorized
+	switch tx.status {
+	case StatusCaptured:
+		amount = tx.captured - tx.refunded
+	case StatusRefunded:
+		amount = tx.refunded
+	}
+	return Result{TransactionID: id, Reference: tx.reference, Status: tx.status, Amount: amount, Message: message}
+}
+
+func newTransactionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "tx_" + hex.EncodeToString(b), nil
+}
+
+func writeStub(w http.ResponseWriter, status int, result Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,153 @@
+// handlers/availability.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"interview_YangYang_20241010/availability"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AvailabilityHandler computes bookable slots from rooms and reservations.
+type AvailabilityHandler struct {
+	rooms        repository.RoomStore
+	reservations repository.ReservationStore
+}
+
+// NewAvailabilityHandler creates an AvailabilityHandler backed by the given stores.
+func NewAvailabilityHandler(rooms repository.RoomStore, reservations repository.ReservationStore) *AvailabilityHandler {
+	return &AvailabilityHandler{rooms: rooms, reservations: reservations}
+}
+
+// RoomAvailability lists the free slots of a room, in the room's time zone.
+type RoomAvailability struct {
+	RoomID   uint                  `json:"room_id"`
+	Name     string                `json:"name"`
+	TimeZone string                `json:"time_zone"`
+	Slots    []availability.Window `json:"slots"`
+}
+
+// @Summary Search room availability
+// @Description List the free slots of every room in service on a day. The day, from and to are read in each room's own time zone; slots fall within the room's opening hours and avoid existing reservations.
+// @Tags rooms
+// @Produce json
+// @Param date query string true "Day to search (YYYY-MM-DD)"
+// @Param duration query string false "Slot length such as 30m or 1h30m" default(1h)
+// @Param from query string false "Earliest slot start (HH:MM)"
+// @Param to query string false "Latest slot end (HH:MM, 24:00 for midnight)"
+// @Success 200 {array} RoomAvailability "Free slots per room"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /rooms/availability [get]
+func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid date format. Expected YYYY-MM-DD"})
+		return
+	}
+
+	duration := time.Hour
+	if s := c.Query("duration"); s != "" {
+		duration, err = time.ParseDuration(s)
+		if err != nil || duration < time.Minute || duration > 24*time.Hour {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid duration. Expected between 1m and 24h, e.g. 90m"})
+			return
+		}
+	}
+
+	var from, to *availability.Clock
+	for _, param := range []struct {
+		name string
+		dst  **availability.Clock
+	}{{"from", &from}, {"to", &to}} {
+		s := c.Query(param.name)
+		if s == "" {
+			continue
+		}
+		clock, err := availability.ParseClock(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid " + param.name + ": " + err.Error()})
+			return
+		}
+		*param.dst = &clock
+	}
+
+	rooms, err := h.rooms.GetAllRooms()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result := []RoomAvailability{}
+	for _, room := range rooms {
+		if !room.InService() {
+			continue
+		}
+		slots, err := h.roomSlots(room, date, duration, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		result = append(result, RoomAvailability{RoomID: room.ID, Name: room.Name, TimeZone: room.TimeZone, Slots: slots})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// roomSlots computes the free slots of a room on the given day.
+func (h *AvailabilityHandler) roomSlots(room models.Room, date time.Time, duration time.Duration, from, to *availability.Clock) ([]availability.Window, error) {
+	loc, err := time.LoadLocation(room.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	opens, err := availability.ParseClock(room.OpensAt)
+	if err != nil {
+		opens = availability.Clock{}
+	}
+	closes, err := availability.ParseClock(room.ClosesAt)
+	if err != nil {
+		closes = availability.Clock{Hour: 24}
+	}
+
+	y, m, d := date.Date()
+	open := availability.OpeningWindow(y, m, d, loc, opens, closes)
+	bounds := open
+	if from != nil {
+		bounds.Start = from.On(y, m, d, loc)
+	}
+	if to != nil {
+		bounds.End = to.On(y, m, d, loc)
+		if !bounds.End.After(bounds.Start) {
+			bounds.End = to.On(y, m, d+1, loc)
+		}
+	}
+	open = open.Clip(bounds)
+	if open.Duration() < duration {
+		return []availability.Window{}, nil
+	}
+
+	// Reservations are looked up per UTC day, so collect every day the window touches
+	var busy []availability.Window
+	seen := map[uint]bool{}
+	for day := open.Start.UTC().Truncate(24 * time.Hour); day.Before(open.End); day = day.AddDate(0, 0, 1) {
+		reservations, err := h.reservations.GetReservations(room.ID, day, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reservations {
+			if r.HoldsSlot() && !seen[r.ID] {
+				seen[r.ID] = true
+				busy = append(busy, availability.Window{Start: r.StartAt, End: r.EndAt})
+			}
+		}
+	}
+
+	slots := availability.Slots(availability.Free(open, busy), duration)
+	if slots == nil {
+		slots = []availability.Window{}
+	}
+	return slots, nil
+}
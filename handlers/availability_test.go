@@ -0,0 +1,103 @@
+// handlers/availability_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAvailabilityRouter() (*gin.Engine, *fakeRoomStore, *fakeReservationStore) {
+	rooms := newFakeRoomStore()
+	reservations := &fakeReservationStore{}
+	h := NewAvailabilityHandler(rooms, reservations)
+	roomHandler := NewRoomHandler(rooms, &fakePublisher{})
+
+	r := gin.New()
+	r.GET("/rooms/availability", h.GetAvailability)
+	r.GET("/rooms/:id", roomHandler.GetRoomByID)
+	return r, rooms, reservations
+}
+
+func getAvailability(t *testing.T, r *gin.Engine, query string) []RoomAvailability {
+	t.Helper()
+	w := performRequest(r, http.MethodGet, "/rooms/availability?"+query, nil)
+	if !assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		return nil
+	}
+	var result []RoomAvailability
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestAvailabilitySkipsReservationsAndClosedRooms(t *testing.T) {
+	r, rooms, reservations := newAvailabilityRouter()
+	rooms.CreateRoom(models.Room{Name: "Room A", Status: models.RoomStatusOpen, OpensAt: "10:00", ClosesAt: "14:00", TimeZone: "Asia/Taipei"})
+	rooms.CreateRoom(models.Room{Name: "Room B", Status: models.RoomStatusMaintenance, OpensAt: "00:00", ClosesAt: "24:00", TimeZone: "UTC"})
+
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	bookedAt := time.Date(2024, 10, 9, 0, 0, 0, 0, taipei)
+	reservations.CreateReservation(models.Reservation{
+		RoomID:  1,
+		StartAt: time.Date(2024, 10, 10, 11, 30, 0, 0, taipei),
+		EndAt:   time.Date(2024, 10, 10, 12, 0, 0, 0, taipei),
+	}, bookedAt)
+	// Cancelled bookings give their slot back
+	cancelled, _ := reservations.CreateReservation(models.Reservation{
+		RoomID:  1,
+		StartAt: time.Date(2024, 10, 10, 13, 0, 0, 0, taipei),
+		EndAt:   time.Date(2024, 10, 10, 14, 0, 0, 0, taipei),
+	}, bookedAt)
+	reservations.reservations[cancelled-1].Status = models.ReservationStatusCancelled
+
+	result := getAvailability(t, r, "date=2024-10-10&duration=1h")
+	if !assert.Len(t, result, 1) {
+		return
+	}
+	assert.Equal(t, "Room A", result[0].Name)
+	var starts []string
+	for _, slot := range result[0].Slots {
+		starts = append(starts, slot.Start.In(taipei).Format("15:04"))
+	}
+	assert.Equal(t, []string{"10:00", "12:00", "13:00"}, starts)
+
+	// from and to narrow the window in the room's time zone
+	result = getAvailability(t, r, "date=2024-10-10&duration=30m&from=12:30&to=13:30")
+	if assert.Len(t, result, 1) {
+		assert.Len(t, result[0].Slots, 2)
+	}
+
+	// Rooms without room for the duration are listed without slots
+	result = getAvailability(t, r, "date=2024-10-10&duration=5h")
+	if assert.Len(t, result, 1) {
+		assert.Empty(t, result[0].Slots)
+	}
+}
+
+func TestAvailabilityHandlesOvernightHours(t *testing.T) {
+	r, rooms, _ := newAvailabilityRouter()
+	rooms.CreateRoom(models.Room{Name: "Night room", Status: models.RoomStatusOpen, OpensAt: "22:00", ClosesAt: "02:00", TimeZone: "UTC"})
+
+	result := getAvailability(t, r, "date=2024-10-10&duration=2h")
+	if assert.Len(t, result, 1) && assert.Len(t, result[0].Slots, 2) {
+		assert.Equal(t, time.Date(2024, 10, 11, 0, 0, 0, 0, time.UTC), result[0].Slots[1].Start.UTC())
+	}
+}
+
+func TestAvailabilityValidatesQuery(t *testing.T) {
+	r, _, _ := newAvailabilityRouter()
+	for _, query := range []string{"", "date=10/10/2024", "date=2024-10-10&duration=abc", "date=2024-10-10&duration=30s", "date=2024-10-10&from=9am"} {
+		w := performRequest(r, http.MethodGet, "/rooms/availability?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// The static route does not shadow room lookups
+	w := performRequest(r, http.MethodGet, "/rooms/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,202 @@
+// handlers/challenge_definitions.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDefinitionName matches the width of the challenge type column the name
+// is recorded in.
+const maxDefinitionName = 32
+
+// ChallengeDefinitionHandler serves the admin endpoints for challenge
+// definitions.
+type ChallengeDefinitionHandler struct {
+	definitions repository.ChallengeDefinitionStore
+}
+
+// NewChallengeDefinitionHandler creates a ChallengeDefinitionHandler backed
+// by the given store.
+func NewChallengeDefinitionHandler(definitions repository.ChallengeDefinitionStore) *ChallengeDefinitionHandler {
+	return &ChallengeDefinitionHandler{definitions: definitions}
+}
+
+// @Summary List challenge definitions
+// @Description Retrieve every challenge definition, including ones outside their active window
+// @Tags Challenges
+// @Produce json
+// @Success 200 {array} models.ChallengeDefinition "A list of challenge definitions"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenge-definitions [get]
+func (h *ChallengeDefinitionHandler) GetChallengeDefinitions(c *gin.Context) {
+	definitions, err := h.definitions.GetChallengeDefinitions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if definitions == nil {
+		definitions = []models.ChallengeDefinition{}
+	}
+	c.JSON(http.StatusOK, definitions)
+}
+
+// @Summary Create a challenge definition
+// @Description Add a challenge players can enter with POST /challenges by its ID, with its own entry fee, duration, cooldown, odds model, active window and daily entry limit per player.
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param definition body models.ChallengeDefinition true "Challenge definition"
+// @Success 201 {object} map[string]uint "Successfully created definition ID"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 409 {object} models.ErrorResponse "Name already taken"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenge-definitions [post]
+func (h *ChallengeDefinitionHandler) CreateChallengeDefinition(c *gin.Context) {
+	var definition models.ChallengeDefinition
+	if err := c.ShouldBindJSON(&definition); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if msg := validateChallengeDefinition(definition); msg != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: msg})
+		return
+	}
+
+	id, err := h.definitions.CreateChallengeDefinition(definition)
+	if err != nil {
+		if errors.Is(err, repository.ErrChallengeDefinitionExists) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, map[string]uint{"id": id})
+}
+
+// @Summary Get a challenge definition
+// @Description Retrieve a challenge definition by its ID
+// @Tags Challenges
+// @Produce json
+// @Param id path uint true "Definition ID"
+// @Success 200 {object} models.ChallengeDefinition "Challenge definition"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Definition not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenge-definitions/{id} [get]
+func (h *ChallengeDefinitionHandler) GetChallengeDefinition(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid definition ID"})
+		return
+	}
+	definition, err := h.definitions.GetChallengeDefinitionByID(id)
+	if err != nil {
+		writeDefinitionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, definition)
+}
+
+// @Summary Update a challenge definition
+// @Description Replace the settings of a challenge definition. Entries already made keep the fee, resolve time and win chance they were given.
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param id path uint true "Definition ID"
+// @Param definition body models.ChallengeDefinition true "Challenge definition"
+// @Success 200 {object} models.SuccessResponse "Update status"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Definition not found"
+// @Failure 409 {object} models.ErrorResponse "Name already taken"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenge-definitions/{id} [put]
+func (h *ChallengeDefinitionHandler) UpdateChallengeDefinition(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid definition ID"})
+		return
+	}
+	var definition models.ChallengeDefinition
+	if err := c.ShouldBindJSON(&definition); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if msg := validateChallengeDefinition(definition); msg != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: msg})
+		return
+	}
+
+	if err := h.definitions.UpdateChallengeDefinition(id, definition); err != nil {
+		writeDefinitionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{Status: "updated"})
+}
+
+// @Summary Delete a challenge definition
+// @Description Remove a challenge definition nobody has entered. Definitions with entries are ended by setting ends_at instead.
+// @Tags Challenges
+// @Produce json
+// @Param id path uint true "Definition ID"
+// @Success 200 {object} models.SuccessResponse "Deletion status"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Definition not found"
+// @Failure 409 {object} models.ErrorResponse "Definition has entries"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenge-definitions/{id} [delete]
+func (h *ChallengeDefinitionHandler) DeleteChallengeDefinition(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid definition ID"})
+		return
+	}
+	if err := h.definitions.DeleteChallengeDefinition(id); err != nil {
+		writeDefinitionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{Status: "deleted"})
+}
+
+// writeDefinitionError maps challenge definition store errors to responses.
+func writeDefinitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrChallengeDefinitionNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Challenge definition not found"})
+	case errors.Is(err, repository.ErrChallengeDefinitionExists), errors.Is(err, repository.ErrChallengeDefinitionInUse):
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+// validateChallengeDefinition checks the settings of a definition and
+// returns the problem found, if any.
+func validateChallengeDefinition(d models.ChallengeDefinition) string {
+	switch {
+	case d.Name == "" || len(d.Name) > maxDefinitionName:
+		return "Challenge definition name is required and may be at most 32 characters"
+	case d.Name == models.ChallengeTypeEndless:
+		return "The endless challenge is configured by the server"
+	case d.EntryFee <= 0:
+		return "Entry fee must be positive"
+	case d.DurationSeconds < 0 || d.CooldownSeconds < 0:
+		return "Duration and cooldown must not be negative"
+	case d.MaxEntriesPerDay < 0:
+		return "Max entries per day must not be negative"
+	case d.StartsAt != nil && d.EndsAt != nil && !d.EndsAt.After(*d.StartsAt):
+		return "ends_at must be after starts_at"
+	}
+	odds := config.OddsConfig{Model: d.OddsModel, Base: d.OddsBase, Step: d.OddsStep, Cap: d.OddsCap, Levels: d.OddsLevels}
+	if err := odds.Validate(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
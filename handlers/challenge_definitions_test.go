@@ -0,0 +1,100 @@
+// handlers/challenge_definitions_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newChallengeDefinitionRouter() (*gin.Engine, *fakeChallengeDefinitionStore) {
+	definitions := newFakeChallengeDefinitionStore()
+	h := NewChallengeDefinitionHandler(definitions)
+
+	r := gin.New()
+	r.GET("/challenge-definitions", h.GetChallengeDefinitions)
+	r.POST("/challenge-definitions", h.CreateChallengeDefinition)
+	r.GET("/challenge-definitions/:id", h.GetChallengeDefinition)
+	r.PUT("/challenge-definitions/:id", h.UpdateChallengeDefinition)
+	r.DELETE("/challenge-definitions/:id", h.DeleteChallengeDefinition)
+	return r, definitions
+}
+
+func TestChallengeDefinitionCRUD(t *testing.T) {
+	r, definitions := newChallengeDefinitionRouter()
+
+	w := performRequest(r, http.MethodGet, "/challenge-definitions", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	weekend := models.ChallengeDefinition{
+		Name: "weekend", EntryFee: 500, DurationSeconds: 60, CooldownSeconds: 300,
+		OddsModel: config.OddsLinear, OddsBase: 0.02, OddsStep: 0.01, OddsCap: 0.1, MaxEntriesPerDay: 3,
+	}
+	w = performRequest(r, http.MethodPost, "/challenge-definitions", weekend)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]uint
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = performRequest(r, http.MethodPost, "/challenge-definitions", weekend)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = performRequest(r, http.MethodGet, "/challenge-definitions/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.ChallengeDefinition
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, created["id"], got.ID)
+	assert.Equal(t, 3, got.MaxEntriesPerDay)
+
+	weekend.EntryFee = 700
+	w = performRequest(r, http.MethodPut, "/challenge-definitions/1", weekend)
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, err := definitions.GetChallengeDefinitionByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(700), stored.EntryFee)
+
+	w = performRequest(r, http.MethodPut, "/challenge-definitions/9", weekend)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	definitions.inUse[1] = true
+	w = performRequest(r, http.MethodDelete, "/challenge-definitions/1", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	definitions.inUse[1] = false
+	w = performRequest(r, http.MethodDelete, "/challenge-definitions/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = performRequest(r, http.MethodGet, "/challenge-definitions/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestChallengeDefinitionValidation(t *testing.T) {
+	r, _ := newChallengeDefinitionRouter()
+	start := time.Now()
+	valid := models.ChallengeDefinition{Name: "weekend", EntryFee: 500, OddsModel: config.OddsFlat, OddsBase: 0.1}
+
+	cases := map[string]func(d *models.ChallengeDefinition){
+		"missing name":     func(d *models.ChallengeDefinition) { d.Name = "" },
+		"endless":          func(d *models.ChallengeDefinition) { d.Name = models.ChallengeTypeEndless },
+		"free entry":       func(d *models.ChallengeDefinition) { d.EntryFee = 0 },
+		"negative timing":  func(d *models.ChallengeDefinition) { d.CooldownSeconds = -1 },
+		"negative limit":   func(d *models.ChallengeDefinition) { d.MaxEntriesPerDay = -1 },
+		"unknown model":    func(d *models.ChallengeDefinition) { d.OddsModel = "martingale" },
+		"chance above one": func(d *models.ChallengeDefinition) { d.OddsBase = 1.5 },
+		"inverted window": func(d *models.ChallengeDefinition) {
+			end := start.Add(-time.Hour)
+			d.StartsAt, d.EndsAt = &start, &end
+		},
+	}
+	for name, mutate := range cases {
+		d := valid
+		mutate(&d)
+		w := performRequest(r, http.MethodPost, "/challenge-definitions", d)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
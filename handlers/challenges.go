@@ -2,26 +2,83 @@ package handlers
 
 import (
     "errors"
-    "math/rand"
+    "fmt"
     "net/http"
     "strconv"
     "time"
 
     "github.com/gin-gonic/gin"
+    "interview_YangYang_20241010/config"
+    "interview_YangYang_20241010/fairness"
     "interview_YangYang_20241010/models"
     "interview_YangYang_20241010/repository"
 )
 
+// ChallengeHandler serves the challenge endpoints.
+type ChallengeHandler struct {
+    challenges  repository.ChallengeStore
+    definitions repository.ChallengeDefinitionStore
+    players     repository.PlayerStore
+    cfg         config.ChallengeConfig
+}
+
+// NewChallengeHandler creates a ChallengeHandler backed by the given stores and settings.
+func NewChallengeHandler(challenges repository.ChallengeStore, definitions repository.ChallengeDefinitionStore, players repository.PlayerStore, cfg config.ChallengeConfig) *ChallengeHandler {
+    return &ChallengeHandler{challenges: challenges, definitions: definitions, players: players, cfg: cfg}
+}
+
+// maxChallengePage caps the page size of a player's challenge history.
+const maxChallengePage = 100
+
 // ChallengeRequest represents the request body for creating a challenge.
 type ChallengeRequest struct {
-    PlayerID uint `json:"player_id" binding:"required"`
+    PlayerID     uint   `json:"player_id" binding:"required"`
+    DefinitionID uint   `json:"definition_id"`                 // Optional challenge definition to enter instead of the endless challenge
+    ClientSeed   string `json:"client_seed" binding:"max=64"` // Optional, the seed pair's client seed by default; mixed into the outcome so the server cannot pick it alone
 }
 
 // ChallengeResponse represents the response after creating a challenge.
 type ChallengeResponse struct {
-    Status string `json:"status"`
-    ID     uint   `json:"id,omitempty"`
-    Error  string `json:"error,omitempty"`
+    Status         string     `json:"status"`
+    ID             uint       `json:"id,omitempty"`
+    ResolveAt      *time.Time `json:"resolve_at,omitempty"`       // When the outcome will be decided
+    ServerSeedHash string     `json:"server_seed_hash,omitempty"` // SHA-256 of the secret server seed, as published before entry
+    ClientSeed     string     `json:"client_seed,omitempty"`
+    Nonce          uint64     `json:"nonce,omitempty"`
+    OddsModel      string     `json:"odds_model,omitempty"`
+    WinChance      *float64   `json:"win_chance,omitempty"` // Probability of winning, fixed at entry
+    Error          string     `json:"error,omitempty"`
+}
+
+// ChallengeVerification reveals what a resolved challenge's outcome was
+// derived from. Anyone can check that ServerSeed hashes to ServerSeedHash,
+// that HMAC-SHA256 keyed with ServerSeed over "ClientSeed:Nonce" gives Roll
+// (its first 53 bits over 2^53), and that Won is Roll < WinChance.
+type ChallengeVerification struct {
+    ChallengeID    uint    `json:"challenge_id"`
+    ServerSeed     string  `json:"server_seed"`
+    ServerSeedHash string  `json:"server_seed_hash"`
+    ClientSeed     string  `json:"client_seed"`
+    Nonce          uint64  `json:"nonce"`
+    Roll           float64 `json:"roll"`
+    WinChance      float64 `json:"win_chance"`
+    Won            bool    `json:"won"`
+    Valid          bool    `json:"valid"` // Whether the server's own recomputation agrees
+}
+
+// ChallengeStatus is a challenge together with the time left until its
+// outcome is decided.
+type ChallengeStatus struct {
+    models.Challenge
+    RemainingSeconds int64 `json:"remaining_seconds"` // Until resolve_at; 0 once due or resolved
+}
+
+// ChallengePage is one page of a player's challenges, newest first.
+type ChallengePage struct {
+    Challenges []models.Challenge `json:"challenges"`
+    Total      int64              `json:"total"` // Challenges the player has entered
+    Limit      int                `json:"limit"`
+    Offset     int                `json:"offset"`
 }
 
 // SuccessResponse represents a generic success response.
@@ -30,88 +87,278 @@ type SuccessResponse struct {
 }
 
 // @Summary Participate in a Challenge
-// @Description Players can participate in an endless challenge by paying 20.01.
+// @Description Players can participate in an endless challenge by paying the entry fee (2001 cents by default) from their wallet. The fee, less the house rake, is added to the jackpot and winnings are credited back to the wallet. The win chance is fixed at entry by the odds model configured for endless challenges; the outcome is decided at resolve_at. Naming a definition_id enters that challenge definition instead, with its own fee, duration, cooldown, odds and daily entry limit, while it is running.
 // @Tags Challenges
 // @Accept json
 // @Produce json
 // @Param challenge body ChallengeRequest true "Challenge Participation"
+// @Param Idempotency-Key header string false "Makes retries safe: a retry with the same key and body gets the first response replayed"
 // @Success 200 {object} ChallengeResponse "Challenge started"
 // @Failure 400 {object} ChallengeResponse "Bad Request"
+// @Failure 402 {object} ChallengeResponse "Wallet cannot cover the entry fee"
+// @Failure 404 {object} ChallengeResponse "Challenge definition not found"
+// @Failure 409 {object} ChallengeResponse "Challenge definition is not running, or a request with the same Idempotency-Key is still in progress"
+// @Failure 422 {object} models.ErrorResponse "Idempotency-Key was used for a different request"
 // @Failure 500 {object} ChallengeResponse "Internal Server Error"
 // @Router /challenges [post]
-func ParticipateChallenge(c *gin.Context) {
+func (h *ChallengeHandler) ParticipateChallenge(c *gin.Context) {
     var req ChallengeRequest
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, ChallengeResponse{Error: err.Error()})
         return
     }
 
-    // Initialize a new challenge with a fixed amount of 20.01
+    // Initialize a new challenge with the configured entry fee
     challenge := models.Challenge{
-        PlayerID: req.PlayerID,
-        Amount:   20.01,
-        Won:      false,
+        PlayerID:   req.PlayerID,
+        Amount:     h.cfg.EntryFee,
+        Won:        false,
+        ResolveAt:  time.Now().Add(h.cfg.Duration).UTC().Truncate(time.Second),
+        Type:       models.ChallengeTypeEndless,
+        ClientSeed: req.ClientSeed,
+    }
+    fee, cooldown := h.cfg.EntryFee, h.cfg.Cooldown
+
+    // A definition brings its own terms; the store applies them
+    if req.DefinitionID != 0 {
+        definition, err := h.definitions.GetChallengeDefinitionByID(req.DefinitionID)
+        if err != nil {
+            if errors.Is(err, repository.ErrChallengeDefinitionNotFound) {
+                c.JSON(http.StatusNotFound, ChallengeResponse{Error: "Challenge definition not found"})
+            } else {
+                c.JSON(http.StatusInternalServerError, ChallengeResponse{Error: err.Error()})
+            }
+            return
+        }
+        challenge.DefinitionID = &definition.ID
+        fee, cooldown = definition.EntryFee, definition.Cooldown()
     }
 
     // Attempt to create the challenge
-    challengeID, err := repository.CreateChallenge(challenge)
+    challengeID, err := h.challenges.CreateChallenge(challenge, h.cfg)
     if err != nil {
         if errors.Is(err, repository.ErrPlayerNotAllowed) {
-            c.JSON(http.StatusBadRequest, ChallengeResponse{Error: "Player can only participate once per minute"})
+            c.JSON(http.StatusBadRequest, ChallengeResponse{Error: fmt.Sprintf("Player can only participate once every %s", cooldown)})
+            return
+        }
+        if errors.Is(err, repository.ErrDailyEntryLimit) {
+            c.JSON(http.StatusBadRequest, ChallengeResponse{Error: "Player has no entries left today"})
+            return
+        }
+        if errors.Is(err, repository.ErrChallengeNotRunning) {
+            c.JSON(http.StatusConflict, ChallengeResponse{Error: "Challenge is not running"})
+            return
+        }
+        if errors.Is(err, repository.ErrChallengeDefinitionNotFound) {
+            c.JSON(http.StatusNotFound, ChallengeResponse{Error: "Challenge definition not found"})
+            return
+        }
+        if errors.Is(err, repository.ErrInsufficientFunds) {
+            c.JSON(http.StatusPaymentRequired, ChallengeResponse{Error: fmt.Sprintf("Entry fee of %d cents exceeds the wallet balance", fee)})
             return
         }
         c.JSON(http.StatusInternalServerError, ChallengeResponse{Error: "Failed to create challenge"})
         return
     }
 
-    // Respond immediately with the challenge status
+    created, err := h.challenges.GetChallengeByID(challengeID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, ChallengeResponse{Error: err.Error()})
+        return
+    }
+
+    // Respond immediately with the commitment; the resolver decides the
+    // outcome once the duration is over
     c.JSON(http.StatusOK, ChallengeResponse{
-        Status: "challenge started",
-        ID:     challengeID,
+        Status:         "challenge started",
+        ID:             challengeID,
+        ResolveAt:      &created.ResolveAt,
+        ServerSeedHash: created.ServerSeedHash,
+        ClientSeed:     created.ClientSeed,
+        Nonce:          created.Nonce,
+        OddsModel:      created.OddsModel,
+        WinChance:      created.WinChance,
     })
+}
 
-    // Process the challenge outcome after 30 seconds in the background
-    go processChallengeOutcome(challengeID)
+// @Summary Get a challenge
+// @Description Retrieve a challenge with its status, pending or resolved, and the seconds left until its outcome is decided. Players poll this after entering.
+// @Tags Challenges
+// @Produce json
+// @Param id path uint true "Challenge ID"
+// @Success 200 {object} ChallengeStatus "Challenge status"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Challenge not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenges/{id} [get]
+func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid challenge ID"})
+        return
+    }
+
+    challenge, err := h.challenges.GetChallengeByID(id)
+    if err != nil {
+        if errors.Is(err, repository.ErrChallengeNotFound) {
+            c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Challenge not found"})
+        } else {
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        }
+        return
+    }
+    status := ChallengeStatus{Challenge: *challenge}
+    if challenge.Status == models.ChallengeStatusPending {
+        // Round up so a challenge is not reported due a second early
+        remaining := time.Until(challenge.ResolveAt)
+        status.RemainingSeconds = max(int64((remaining+time.Second-1)/time.Second), 0)
+    }
+    c.JSON(http.StatusOK, status)
 }
 
-// processChallengeOutcome determines the outcome of a challenge after a delay.
-func processChallengeOutcome(challengeID uint) {
-    // Wait for 30 seconds before determining the outcome
-    time.Sleep(30 * time.Second)
+// @Summary Get a player's challenges
+// @Description Retrieve a page of the challenges a player entered, newest first, with the total number entered
+// @Tags players
+// @Produce json
+// @Param id path string true "Player ID"
+// @Param limit query int false "Maximum number of challenges to return (defaults to the results limit, at most 100)"
+// @Param offset query int false "Number of newer challenges to skip"
+// @Success 200 {object} ChallengePage "A page of the player's challenges"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/challenges [get]
+func (h *ChallengeHandler) GetPlayerChallenges(c *gin.Context) {
+    playerID, ok := numericPlayer(c, h.players)
+    if !ok {
+        return
+    }
+    page := ChallengePage{Limit: h.cfg.ResultsLimit}
+    if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+        page.Limit = min(parsedLimit, maxChallengePage)
+    }
+    if parsedOffset, err := strconv.Atoi(c.Query("offset")); err == nil && parsedOffset > 0 {
+        page.Offset = parsedOffset
+    }
 
-    // Retrieve the challenge from the database
-    challenge, err := repository.GetChallengeByID(challengeID)
+    challenges, total, err := h.challenges.GetPlayerChallenges(playerID, page.Limit, page.Offset)
     if err != nil {
-        // Log the error if necessary (not implemented here)
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
     }
+    page.Challenges, page.Total = challenges, total
+    if page.Challenges == nil {
+        page.Challenges = []models.Challenge{}
+    }
+    c.JSON(http.StatusOK, page)
+}
 
-    // Retrieve the player's total number of participations to adjust win probability
-    participationCount, err := repository.GetPlayerParticipationCount(challenge.PlayerID)
+// @Summary Get a player's challenge stats
+// @Description Retrieve how many challenges a player entered, how many are pending and won, the entry fees paid and the jackpots won
+// @Tags players
+// @Produce json
+// @Param id path string true "Player ID"
+// @Success 200 {object} models.ChallengeStats "The player's challenge stats"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/challenges/stats [get]
+func (h *ChallengeHandler) GetPlayerChallengeStats(c *gin.Context) {
+    playerID, ok := numericPlayer(c, h.players)
+    if !ok {
+        return
+    }
+    stats, err := h.challenges.GetPlayerChallengeStats(playerID)
     if err != nil {
-        // Log the error if necessary (not implemented here)
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
     }
+    c.JSON(http.StatusOK, stats)
+}
 
-    // Base win probability is 1%, increases by 1% per participation
-    winProbability := float64(participationCount) * 1.0
-    if winProbability > 100.0 {
-        winProbability = 100.0
+// @Summary Get a player's seed commitment
+// @Description Retrieve the hash of the server seed the player's next challenges are played with, the client seed used when an entry brings none, and the nonce entries so far took. The server seed is drawn before the player picks a client seed and is replaced once a challenge played with it is resolved, and revealed by verification once all of them are.
+// @Tags players
+// @Produce json
+// @Param id path string true "Player ID"
+// @Success 200 {object} models.PlayerSeed "The player's seed commitment"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/seed [get]
+func (h *ChallengeHandler) GetPlayerSeed(c *gin.Context) {
+    playerID, ok := numericPlayer(c, h.players)
+    if !ok {
+        return
+    }
+    seed, err := h.challenges.GetPlayerSeed(playerID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        return
     }
+    c.JSON(http.StatusOK, seed)
+}
 
-    // Generate a random float between 0 and 100
-    rand.Seed(time.Now().UnixNano())
-    randomNumber := rand.Float64() * 100.0
+// @Summary Verify a challenge outcome
+// @Description Reveal the server seed of a resolved challenge together with the client seed, nonce, roll and win chance its outcome was derived from. The seed is revealed once every challenge played with it is resolved; the player's seed pair is replaced as soon as the first of them is. The seed hashes to the server_seed_hash published before entry, and HMAC-SHA256(server_seed, client_seed + ":" + nonce) reproduces the roll; the challenge is won when roll < win_chance.
+// @Tags Challenges
+// @Produce json
+// @Param id path uint true "Challenge ID"
+// @Success 200 {object} ChallengeVerification "Outcome proof"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Challenge not found"
+// @Failure 409 {object} models.ErrorResponse "Challenge, or another played with the same server seed, not resolved yet, or seeded when resolved and so not verifiable"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenges/{id}/verify [get]
+func (h *ChallengeHandler) VerifyChallenge(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid challenge ID"})
+        return
+    }
 
-    // Determine if the player wins based on the probability
-    if randomNumber < winProbability {
-        challenge.Won = true
-    } else {
-        challenge.Won = false
+    challenge, err := h.challenges.GetChallengeByID(id)
+    if err != nil {
+        if errors.Is(err, repository.ErrChallengeNotFound) {
+            c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Challenge not found"})
+        } else {
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        }
+        return
+    }
+    // The seed stays secret until the outcome is final
+    if challenge.Status != models.ChallengeStatusResolved {
+        c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Challenge is not resolved yet; its server seed is revealed once it is"})
+        return
+    }
+    if challenge.Roll == nil || challenge.WinChance == nil {
+        c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Challenge was settled before outcomes became verifiable"})
+        return
+    }
+    if !challenge.Committed() {
+        c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Challenge was seeded when it was resolved; no server seed hash was published for it, so its outcome cannot be verified"})
+        return
+    }
+    // Revealing the seed would give away the outcome of entries still open on it
+    pending, err := h.challenges.CountPendingChallengesBySeed(challenge.ServerSeedHash)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    if pending > 0 {
+        c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Other challenges played with this server seed are not resolved yet; it is revealed once they are"})
+        return
     }
 
-    // Update the challenge outcome in the database
-    repository.UpdateChallenge(*challenge)
+    v := ChallengeVerification{
+        ChallengeID:    challenge.ID,
+        ServerSeed:     challenge.ServerSeed,
+        ServerSeedHash: challenge.ServerSeedHash,
+        ClientSeed:     challenge.ClientSeed,
+        Nonce:          challenge.Nonce,
+        Roll:           *challenge.Roll,
+        WinChance:      *challenge.WinChance,
+        Won:            challenge.Won,
+    }
+    v.Valid = fairness.Verify(v.ServerSeed, v.ServerSeedHash, v.ClientSeed, v.Nonce, v.Roll) && v.Won == (v.Roll < v.WinChance)
+    c.JSON(http.StatusOK, v)
 }
 
 // @Summary Get Recent Challenge Results
@@ -123,10 +370,10 @@ func processChallengeOutcome(challengeID uint) {
 // @Success 200 {array} models.Challenge "A list of recent challenges"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /challenges/results [get]
-func GetChallengeResults(c *gin.Context) {
-    // Retrieve 'limit' from query parameters; default to 10 if not provided
+func (h *ChallengeHandler) GetChallengeResults(c *gin.Context) {
+    // Retrieve 'limit' from query parameters; fall back to the configured default
     limitParam := c.Query("limit")
-    limit := 10
+    limit := h.cfg.ResultsLimit
     if limitParam != "" {
         parsedLimit, err := strconv.Atoi(limitParam)
         if err == nil && parsedLimit > 0 {
@@ -135,7 +382,7 @@ func GetChallengeResults(c *gin.Context) {
     }
 
     // Fetch recent challenge results from the repository
-    challenges, err := repository.GetRecentChallengeResults(limit)
+    challenges, err := h.challenges.GetRecentChallengeResults(limit)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve challenge results"})
         return
@@ -143,4 +390,51 @@ func GetChallengeResults(c *gin.Context) {
 
     // Respond with the list of challenges
     c.JSON(http.StatusOK, challenges)
-}
\ No newline at end of file
+}
+
+// JackpotResponse is the current jackpot together with the terms feeding it.
+type JackpotResponse struct {
+    models.Jackpot
+    EntryFee int64   `json:"entry_fee"` // In cents
+    Rake     float64 `json:"rake"`      // Share of each entry fee kept by the house
+}
+
+// @Summary Get the jackpot
+// @Description Retrieve the current jackpot, which the next winning challenge takes in full
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Success 200 {object} JackpotResponse "Current jackpot"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenges/jackpot [get]
+func (h *ChallengeHandler) GetJackpot(c *gin.Context) {
+    pot, err := h.challenges.GetJackpot(h.cfg.JackpotSeed)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, JackpotResponse{Jackpot: *pot, EntryFee: h.cfg.EntryFee, Rake: h.cfg.Rake})
+}
+
+// @Summary Get jackpot payouts
+// @Description Retrieve the most recent jackpot payouts, newest first
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param limit query int false "Maximum number of payouts to return"
+// @Success 200 {array} models.JackpotPayout "A list of payouts"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /challenges/jackpot/payouts [get]
+func (h *ChallengeHandler) GetJackpotPayouts(c *gin.Context) {
+    limit := h.cfg.ResultsLimit
+    if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+        limit = parsedLimit
+    }
+
+    payouts, err := h.challenges.GetJackpotPayouts(limit)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, payouts)
+}
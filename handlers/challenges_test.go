@@ -0,0 +1,351 @@
+// handlers/challenges_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/fairness"
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newChallengeRouter(cfg config.ChallengeConfig) (*gin.Engine, *fakeChallengeStore, *fakePlayerStore) {
+	challenges := newFakeChallengeStore()
+	challenges.definitions = newFakeChallengeDefinitionStore()
+	players := newFakePlayerStore()
+	h := NewChallengeHandler(challenges, challenges.definitions, players, cfg)
+	idempotency := NewIdempotency(newFakeIdempotencyStore(), config.Default().Idempotency)
+
+	r := gin.New()
+	r.POST("/challenges", idempotency.Middleware(), h.ParticipateChallenge)
+	r.GET("/challenges/:id", h.GetChallenge)
+	r.GET("/players/:id/challenges", h.GetPlayerChallenges)
+	r.GET("/players/:id/challenges/stats", h.GetPlayerChallengeStats)
+	r.GET("/players/:id/seed", h.GetPlayerSeed)
+	r.GET("/challenges/results", h.GetChallengeResults)
+	r.GET("/challenges/jackpot", h.GetJackpot)
+	r.GET("/challenges/jackpot/payouts", h.GetJackpotPayouts)
+	r.GET("/challenges/:id/verify", h.VerifyChallenge)
+	return r, challenges, players
+}
+
+func TestParticipateChallengeHandler(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Duration = time.Hour
+	r, challenges, _ := newChallengeRouter(cfg)
+
+	w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "challenge started", resp.Status)
+	if assert.NotNil(t, resp.ResolveAt) {
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *resp.ResolveAt, 2*time.Second)
+	}
+
+	challenge, err := challenges.GetChallengeByID(resp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.EntryFee, challenge.Amount)
+	assert.Equal(t, models.ChallengeStatusPending, challenge.Status)
+
+	assert.Equal(t, config.OddsFlat, resp.OddsModel)
+	if assert.NotNil(t, resp.WinChance) {
+		assert.Equal(t, 0.01, *resp.WinChance)
+	}
+
+	// A second entry within the cooldown is rejected
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+}
+
+func TestParticipateChallengeIgnoresRequestedType(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Odds = map[string]config.OddsConfig{
+		models.ChallengeTypeEndless: {Model: config.OddsFlat, Base: 0.01},
+		"vip":                       {Model: config.OddsFlat, Base: 0.9},
+	}
+	r, challenges, _ := newChallengeRouter(cfg)
+
+	// The odds model is the server's choice, not the client's
+	w := performRequest(r, http.MethodPost, "/challenges", map[string]any{"player_id": 7, "type": "vip"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.NotNil(t, resp.WinChance) {
+		assert.Equal(t, 0.01, *resp.WinChance)
+	}
+	challenge, err := challenges.GetChallengeByID(resp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChallengeTypeEndless, challenge.Type)
+	if assert.NotNil(t, challenge.WinChance) {
+		assert.Equal(t, 0.01, *challenge.WinChance)
+	}
+}
+
+func TestGetChallengeResultsHandlerUsesConfiguredLimit(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.ResultsLimit = 2
+	r, challenges, _ := newChallengeRouter(cfg)
+	for playerID := uint(1); playerID <= 3; playerID++ {
+		challenges.CreateChallenge(models.Challenge{PlayerID: playerID}, cfg)
+	}
+
+	w := performRequest(r, http.MethodGet, "/challenges/results", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results []models.Challenge
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Len(t, results, 2)
+
+	w = performRequest(r, http.MethodGet, "/challenges/results?limit=3", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Len(t, results, 3)
+}
+
+func TestJackpotHandlers(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Odds = map[string]config.OddsConfig{models.ChallengeTypeEndless: {Model: config.OddsFlat, Base: 1}}
+	r, challenges, _ := newChallengeRouter(cfg)
+
+	var pot JackpotResponse
+	w := performRequest(r, http.MethodGet, "/challenges/jackpot", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pot))
+	assert.Equal(t, cfg.JackpotSeed, pot.Amount)
+	assert.Equal(t, cfg.Rake, pot.Rake)
+
+	performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7})
+	w = performRequest(r, http.MethodGet, "/challenges/jackpot", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pot))
+	assert.Equal(t, cfg.JackpotSeed+1801, pot.Amount) // The 2001 fee less the 10% rake, rounded
+	assert.Equal(t, int64(1), pot.Entries)
+
+	for id := uint(1); id <= 2; id++ {
+		challenges.CreateChallenge(models.Challenge{PlayerID: 10 + id, Amount: cfg.EntryFee}, cfg)
+		_, err := challenges.ResolveChallenge(id, time.Now(), cfg)
+		assert.NoError(t, err)
+	}
+
+	var payouts []models.JackpotPayout
+	w = performRequest(r, http.MethodGet, "/challenges/jackpot/payouts?limit=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payouts))
+	if assert.Len(t, payouts, 1) {
+		assert.Equal(t, uint(2), payouts[0].ChallengeID)
+	}
+	w = performRequest(r, http.MethodGet, "/challenges/jackpot/payouts", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payouts))
+	assert.Len(t, payouts, 2)
+}
+
+func TestParticipateChallengeChargesTheWallet(t *testing.T) {
+	cfg := config.Default().Challenge
+	r, challenges, _ := newChallengeRouter(cfg)
+	challenges.balances = map[uint]int64{7: cfg.EntryFee + 1}
+
+	w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 8})
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), challenges.balances[7])
+}
+
+func TestVerifyChallengeHandler(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Odds = map[string]config.OddsConfig{models.ChallengeTypeEndless: {Model: config.OddsFlat, Base: 0.5}}
+	cfg.Cooldown = 0
+	r, challenges, players := newChallengeRouter(cfg)
+	players.CreatePlayer(models.Player{Name: "Alice"})
+
+	// The hash is published before the player picks a client seed
+	var commitment models.PlayerSeed
+	w := performRequest(r, http.MethodGet, "/players/1/seed", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &commitment))
+	assert.Len(t, commitment.ServerSeedHash, 64)
+
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 1, ClientSeed: "lucky"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "lucky", resp.ClientSeed)
+	assert.Equal(t, commitment.ServerSeedHash, resp.ServerSeedHash)
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var other ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &other))
+	assert.Equal(t, commitment.ServerSeedHash, other.ServerSeedHash)
+
+	// The seed stays hidden until the outcome is decided
+	w = performRequest(r, http.MethodGet, "/challenges/1/verify", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Settling an entry retires the seed for later ones
+	_, err := challenges.ResolveChallenge(resp.ID, time.Now(), cfg)
+	assert.NoError(t, err)
+	var next models.PlayerSeed
+	w = performRequest(r, http.MethodGet, "/players/1/seed", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &next))
+	assert.NotEqual(t, commitment.ServerSeedHash, next.ServerSeedHash)
+
+	// but it is not revealed while another entry played with it is open
+	w = performRequest(r, http.MethodGet, "/challenges/1/verify", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NotContains(t, w.Body.String(), "server-1-0")
+
+	_, err = challenges.ResolveChallenge(other.ID, time.Now(), cfg)
+	assert.NoError(t, err)
+	var proof ChallengeVerification
+	w = performRequest(r, http.MethodGet, "/challenges/1/verify", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &proof))
+	assert.True(t, proof.Valid)
+	assert.Equal(t, resp.ServerSeedHash, proof.ServerSeedHash)
+	assert.Equal(t, fairness.Roll(proof.ServerSeed, "lucky", resp.Nonce), proof.Roll)
+	assert.Equal(t, proof.Roll < 0.5, proof.Won)
+
+	// Verifying changes nothing
+	var after models.PlayerSeed
+	w = performRequest(r, http.MethodGet, "/players/1/seed", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &after))
+	assert.Equal(t, next.ServerSeedHash, after.ServerSeedHash)
+
+	// Challenges seeded when they were resolved published no hash to check
+	roll := 0.3
+	challenges.challenges[3] = models.Challenge{ID: 3, PlayerID: 1, Status: models.ChallengeStatusResolved,
+		ServerSeed: "legacy", ServerSeedHash: fairness.Hash("legacy"), ClientSeed: "client", Roll: &roll, WinChance: &roll}
+	w = performRequest(r, http.MethodGet, "/challenges/3/verify", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NotContains(t, w.Body.String(), "legacy")
+
+	w = performRequest(r, http.MethodGet, "/challenges/9/verify", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodPost, "/challenges", map[string]any{"player_id": 8, "client_seed": strings.Repeat("x", 65)})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestParticipateInChallengeDefinition(t *testing.T) {
+	cfg := config.Default().Challenge
+	r, challenges, _ := newChallengeRouter(cfg)
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	weekend, _ := challenges.definitions.CreateChallengeDefinition(models.ChallengeDefinition{
+		Name: "weekend", EntryFee: 500, DurationSeconds: 600, CooldownSeconds: 3600, OddsModel: config.OddsFlat, OddsBase: 0.2,
+	})
+	upcoming, _ := challenges.definitions.CreateChallengeDefinition(models.ChallengeDefinition{
+		Name: "upcoming", EntryFee: 500, OddsModel: config.OddsFlat, StartsAt: &future,
+	})
+	ended, _ := challenges.definitions.CreateChallengeDefinition(models.ChallengeDefinition{
+		Name: "ended", EntryFee: 500, OddsModel: config.OddsFlat, EndsAt: &past,
+	})
+
+	w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7, DefinitionID: weekend})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.NotNil(t, resp.ResolveAt) {
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), *resp.ResolveAt, 2*time.Second)
+	}
+	if assert.NotNil(t, resp.WinChance) {
+		assert.Equal(t, 0.2, *resp.WinChance)
+	}
+	challenge, err := challenges.GetChallengeByID(resp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), challenge.Amount)
+	assert.Equal(t, "weekend", challenge.Type)
+
+	// The definition's cooldown applies to the next entry
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 7, DefinitionID: weekend})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "1h0m0s")
+
+	for _, id := range []uint{upcoming, ended} {
+		w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 8, DefinitionID: id})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	}
+	w = performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 8, DefinitionID: 99})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetChallengeHandler(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Duration = time.Minute
+	r, challenges, _ := newChallengeRouter(cfg)
+
+	w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var created ChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	path := fmt.Sprintf("/challenges/%d", created.ID)
+	var status ChallengeStatus
+	w = performRequest(r, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, models.ChallengeStatusPending, status.Status)
+	assert.InDelta(t, 60, status.RemainingSeconds, 2)
+	assert.NotContains(t, w.Body.String(), "server-")
+
+	_, err := challenges.ResolveChallenge(created.ID, time.Now().Add(time.Minute), cfg)
+	assert.NoError(t, err)
+	w = performRequest(r, http.MethodGet, path, nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, models.ChallengeStatusResolved, status.Status)
+	assert.Zero(t, status.RemainingSeconds)
+
+	w = performRequest(r, http.MethodGet, "/challenges/99", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodGet, "/challenges/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPlayerChallengeHistoryHandlers(t *testing.T) {
+	cfg := config.Default().Challenge
+	cfg.Cooldown = 0
+	cfg.Odds = map[string]config.OddsConfig{models.ChallengeTypeEndless: {Model: config.OddsFlat, Base: 1}}
+	r, challenges, players := newChallengeRouter(cfg)
+	id, _ := players.CreatePlayer(models.Player{Name: "Alice"})
+	for range 3 {
+		w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 1})
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	w := performRequest(r, http.MethodPost, "/challenges", ChallengeRequest{PlayerID: 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, err := challenges.ResolveChallenge(2, time.Now(), cfg)
+	assert.NoError(t, err)
+	payout := challenges.payouts[0]
+
+	var page ChallengePage
+	w = performRequest(r, http.MethodGet, "/players/"+id+"/challenges?limit=2&offset=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 2, page.Limit)
+	assert.Equal(t, 1, page.Offset)
+	if assert.Len(t, page.Challenges, 2) {
+		assert.Equal(t, uint(2), page.Challenges[0].ID)
+		assert.Equal(t, uint(1), page.Challenges[1].ID)
+	}
+
+	var stats models.ChallengeStats
+	w = performRequest(r, http.MethodGet, "/players/"+id+"/challenges/stats", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(3), stats.Entries)
+	assert.Equal(t, int64(6003), stats.TotalSpent)
+	assert.Equal(t, payout.Amount, stats.TotalWon)
+
+	w = performRequest(r, http.MethodGet, "/players/99/challenges", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodGet, "/players/99/challenges/stats", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,1012 @@
+// handlers/fakes_test.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/fairness"
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/odds"
+	"interview_YangYang_20241010/realtime"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// performRequest sends a JSON request through the router and records the response.
+func performRequest(r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// fakePlayerStore keeps players in memory.
+type fakePlayerStore struct {
+	players map[string]models.Player
+	nextID  int
+}
+
+func newFakePlayerStore() *fakePlayerStore {
+	return &fakePlayerStore{players: map[string]models.Player{}}
+}
+
+func (f *fakePlayerStore) GetAllPlayers() ([]models.Player, error) {
+	var players []models.Player
+	for _, p := range f.players {
+		players = append(players, p)
+	}
+	return players, nil
+}
+
+func (f *fakePlayerStore) GetPlayerByID(id string) (*models.Player, error) {
+	p, ok := f.players[id]
+	if !ok {
+		return nil, repository.ErrPlayerNotFound
+	}
+	return &p, nil
+}
+
+func (f *fakePlayerStore) CreatePlayer(player models.Player) (string, error) {
+	f.nextID++
+	player.ID = strconv.Itoa(f.nextID)
+	f.players[player.ID] = player
+	return player.ID, nil
+}
+
+func (f *fakePlayerStore) UpdatePlayer(id string, updatedPlayer models.Player) error {
+	if _, ok := f.players[id]; !ok {
+		return repository.ErrPlayerNotFound
+	}
+	updatedPlayer.ID = id
+	f.players[id] = updatedPlayer
+	return nil
+}
+
+func (f *fakePlayerStore) DeletePlayer(id string) error {
+	if _, ok := f.players[id]; !ok {
+		return repository.ErrPlayerNotFound
+	}
+	delete(f.players, id)
+	return nil
+}
+
+// fakeLevelStore keeps levels in memory.
+type fakeLevelStore struct {
+	levels map[string]models.Level
+}
+
+func newFakeLevelStore(levels ...models.Level) *fakeLevelStore {
+	f := &fakeLevelStore{levels: map[string]models.Level{}}
+	for _, l := range levels {
+		f.levels[l.ID] = l
+	}
+	return f
+}
+
+func (f *fakeLevelStore) GetAllLevels() ([]models.Level, error) {
+	var levels []models.Level
+	for _, l := range f.levels {
+		levels = append(levels, l)
+	}
+	return levels, nil
+}
+
+func (f *fakeLevelStore) GetLevelByID(id string) (*models.Level, error) {
+	l, ok := f.levels[id]
+	if !ok {
+		return nil, repository.ErrLevelNotFound
+	}
+	return &l, nil
+}
+
+func (f *fakeLevelStore) CreateLevel(level models.Level) (string, error) {
+	level.ID = strconv.Itoa(len(f.levels) + 1)
+	f.levels[level.ID] = level
+	return level.ID, nil
+}
+
+// fakeRoomStore keeps rooms in memory.
+type fakeRoomStore struct {
+	rooms  map[uint]models.Room
+	nextID uint
+}
+
+func newFakeRoomStore() *fakeRoomStore {
+	return &fakeRoomStore{rooms: map[uint]models.Room{}}
+}
+
+func (f *fakeRoomStore) GetAllRooms() ([]models.Room, error) {
+	var rooms []models.Room
+	for _, r := range f.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms, nil
+}
+
+func (f *fakeRoomStore) GetRoomByID(id uint) (*models.Room, error) {
+	r, ok := f.rooms[id]
+	if !ok {
+		return nil, repository.ErrRoomNotFound
+	}
+	return &r, nil
+}
+
+func (f *fakeRoomStore) CreateRoom(room models.Room) (uint, error) {
+	if room.Capacity == 0 {
+		room.Capacity = models.DefaultRoomCapacity
+	}
+	f.nextID++
+	room.ID = f.nextID
+	f.rooms[room.ID] = room
+	return room.ID, nil
+}
+
+func (f *fakeRoomStore) UpdateRoom(id uint, updatedRoom models.Room) error {
+	if _, ok := f.rooms[id]; !ok {
+		return repository.ErrRoomNotFound
+	}
+	updatedRoom.ID = id
+	f.rooms[id] = updatedRoom
+	return nil
+}
+
+func (f *fakeRoomStore) DeleteRoom(id uint) error {
+	if _, ok := f.rooms[id]; !ok {
+		return repository.ErrRoomNotFound
+	}
+	delete(f.rooms, id)
+	return nil
+}
+
+func (f *fakeRoomStore) JoinRoom(roomID uint, playerID string) (*models.Room, error) {
+	r, ok := f.rooms[roomID]
+	if !ok {
+		return nil, repository.ErrRoomNotFound
+	}
+	for _, other := range f.rooms {
+		for _, m := range other.Members {
+			if m.PlayerID == playerID {
+				return nil, repository.ErrAlreadyInRoom
+			}
+		}
+	}
+	if len(r.Members) >= r.Capacity {
+		return nil, repository.ErrRoomFull
+	}
+	r.Members = append(r.Members, models.RoomMember{RoomID: roomID, PlayerID: playerID, Seat: len(r.Members) + 1})
+	f.rooms[roomID] = r
+	return &r, nil
+}
+
+func (f *fakeRoomStore) LeaveRoom(roomID uint, playerID string) (*models.Room, error) {
+	r, ok := f.rooms[roomID]
+	if !ok {
+		return nil, repository.ErrRoomNotFound
+	}
+	for i, m := range r.Members {
+		if m.PlayerID == playerID {
+			r.Members = append(r.Members[:i:i], r.Members[i+1:]...)
+			f.rooms[roomID] = r
+			return &r, nil
+		}
+	}
+	return nil, repository.ErrNotInRoom
+}
+
+// fakeChallengeStore keeps challenges and the jackpot in memory. It is safe
+// for concurrent use because the handler resolves challenges in the
+// background.
+type fakeChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[uint]models.Challenge
+	lastEntry  map[uint]time.Time
+	jackpot    *models.Jackpot
+	payouts    []models.JackpotPayout
+	// balances holds wallet balances; entry fees are only charged when set
+	balances map[uint]int64
+	// definitions are applied to entries naming one when set
+	definitions *fakeChallengeDefinitionStore
+	seeds       map[uint]models.PlayerSeed
+	rotations   int
+}
+
+func newFakeChallengeStore() *fakeChallengeStore {
+	return &fakeChallengeStore{challenges: map[uint]models.Challenge{}, lastEntry: map[uint]time.Time{}, seeds: map[uint]models.PlayerSeed{}}
+}
+
+// seedFor returns the player's seed pair, drawing it on first use. The
+// caller holds f.mu.
+func (f *fakeChallengeStore) seedFor(playerID uint) models.PlayerSeed {
+	seed, ok := f.seeds[playerID]
+	if !ok {
+		serverSeed := fmt.Sprintf("server-%d-%d", playerID, f.rotations)
+		seed = models.PlayerSeed{PlayerID: playerID, ServerSeed: serverSeed, ServerSeedHash: fairness.Hash(serverSeed), ClientSeed: "client"}
+		f.seeds[playerID] = seed
+	}
+	return seed
+}
+
+func (f *fakeChallengeStore) GetPlayerSeed(playerID uint) (*models.PlayerSeed, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	seed := f.seedFor(playerID)
+	return &seed, nil
+}
+
+func (f *fakeChallengeStore) CountPendingChallengesBySeed(serverSeedHash string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var count int64
+	for _, ch := range f.challenges {
+		if ch.Status == models.ChallengeStatusPending && ch.ServerSeedHash == serverSeedHash {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeChallengeStore) CreateChallenge(challenge models.Challenge, cfg config.ChallengeConfig) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if challenge.DefinitionID != nil && f.definitions != nil {
+		definition, err := f.definitions.GetChallengeDefinitionByID(*challenge.DefinitionID)
+		if err != nil {
+			return 0, err
+		}
+		if !definition.ActiveAt(time.Now()) {
+			return 0, repository.ErrChallengeNotRunning
+		}
+		cfg.Cooldown = definition.Cooldown()
+		cfg.Odds = map[string]config.OddsConfig{definition.Name: {Model: definition.OddsModel, Base: definition.OddsBase}}
+		challenge.Type, challenge.Amount = definition.Name, definition.EntryFee
+		challenge.ResolveAt = time.Now().Add(definition.Duration())
+	}
+	if last, ok := f.lastEntry[challenge.PlayerID]; ok && time.Since(last) < cfg.Cooldown {
+		return 0, repository.ErrPlayerNotAllowed
+	}
+	if f.balances != nil {
+		if f.balances[challenge.PlayerID] < challenge.Amount {
+			return 0, repository.ErrInsufficientFunds
+		}
+		f.balances[challenge.PlayerID] -= challenge.Amount
+	}
+	if challenge.Type == "" {
+		challenge.Type = models.ChallengeTypeEndless
+	}
+	oddsCfg, ok := cfg.Odds[challenge.Type]
+	if !ok {
+		return 0, repository.ErrUnknownChallengeType
+	}
+	model, err := odds.New(oddsCfg)
+	if err != nil {
+		return 0, err
+	}
+	var history odds.History
+	for _, earlier := range f.challenges {
+		if earlier.PlayerID == challenge.PlayerID {
+			history.Participations++
+		}
+	}
+	chance := model.Chance(history)
+	challenge.OddsModel, challenge.WinChance = model.Name(), &chance
+	challenge.ID = uint(len(f.challenges) + 1)
+	challenge.Status = models.ChallengeStatusPending
+	seed := f.seedFor(challenge.PlayerID)
+	seed.Nonce++
+	f.seeds[challenge.PlayerID] = seed
+	challenge.ServerSeed, challenge.ServerSeedHash, challenge.Nonce = seed.ServerSeed, seed.ServerSeedHash, seed.Nonce
+	if challenge.ClientSeed == "" {
+		challenge.ClientSeed = seed.ClientSeed
+	}
+	challenge.CreatedAt = time.Now()
+	f.challenges[challenge.ID] = challenge
+	f.lastEntry[challenge.PlayerID] = challenge.CreatedAt
+	if f.jackpot == nil {
+		f.jackpot = &models.Jackpot{Amount: cfg.JackpotSeed}
+	}
+	f.jackpot.Amount += int64(math.Round(float64(challenge.Amount) * (1 - cfg.Rake)))
+	f.jackpot.Entries++
+	return challenge.ID, nil
+}
+
+func (f *fakeChallengeStore) GetRecentChallengeResults(limit int) ([]models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var challenges []models.Challenge
+	for id := uint(len(f.challenges)); id > 0 && len(challenges) < limit; id-- {
+		challenges = append(challenges, f.challenges[id])
+	}
+	return challenges, nil
+}
+
+func (f *fakeChallengeStore) GetChallengeByID(id uint) (*models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch, ok := f.challenges[id]
+	if !ok {
+		return nil, repository.ErrChallengeNotFound
+	}
+	return &ch, nil
+}
+
+func (f *fakeChallengeStore) UpdateChallenge(challenge models.Challenge) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (f *fakeChallengeStore) GetPlayerChallenges(playerID uint, limit, offset int) ([]models.Challenge, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var challenges []models.Challenge
+	for id := uint(len(f.challenges)); id > 0; id-- {
+		if ch := f.challenges[id]; ch.PlayerID == playerID {
+			challenges = append(challenges, ch)
+		}
+	}
+	total := int64(len(challenges))
+	challenges = challenges[min(offset, len(challenges)):]
+	return challenges[:min(limit, len(challenges))], total, nil
+}
+
+func (f *fakeChallengeStore) GetPlayerChallengeStats(playerID uint) (*models.ChallengeStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := models.ChallengeStats{PlayerID: playerID}
+	for _, ch := range f.challenges {
+		if ch.PlayerID != playerID {
+			continue
+		}
+		stats.Entries++
+		stats.TotalSpent += ch.Amount
+		if ch.Status == models.ChallengeStatusPending {
+			stats.Pending++
+		} else if ch.Won {
+			stats.Wins++
+		}
+	}
+	for _, payout := range f.payouts {
+		if payout.PlayerID == playerID {
+			stats.TotalWon += payout.Amount
+		}
+	}
+	return &stats, nil
+}
+
+func (f *fakeChallengeStore) GetPlayerParticipationCount(playerID uint) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, ch := range f.challenges {
+		if ch.PlayerID == playerID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeChallengeStore) GetDueChallenges(now time.Time, limit int) ([]models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []models.Challenge
+	for id := uint(1); id <= uint(len(f.challenges)) && len(due) < limit; id++ {
+		if ch := f.challenges[id]; ch.Status == models.ChallengeStatusPending && !ch.ResolveAt.After(now) {
+			due = append(due, ch)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeChallengeStore) ResolveChallenge(id uint, now time.Time, cfg config.ChallengeConfig) (*models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch, ok := f.challenges[id]
+	if !ok || ch.Status != models.ChallengeStatusPending {
+		return nil, repository.ErrChallengeResolved
+	}
+	roll := fairness.Roll(ch.ServerSeed, ch.ClientSeed, ch.Nonce)
+	ch.Status = models.ChallengeStatusResolved
+	ch.Won, ch.Roll = roll < *ch.WinChance, &roll
+	ch.ResolvedAt = &now
+	f.challenges[id] = ch
+	if ch.Won {
+		f.payJackpot(ch, cfg.JackpotSeed)
+	}
+	if seed, ok := f.seeds[ch.PlayerID]; ok && ch.Committed() && seed.ServerSeedHash == ch.ServerSeedHash {
+		delete(f.seeds, ch.PlayerID)
+		f.rotations++
+	}
+	return &ch, nil
+}
+
+func (f *fakeChallengeStore) GetJackpot(seed int64) (*models.Jackpot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.jackpot == nil {
+		return &models.Jackpot{Amount: seed}, nil
+	}
+	pot := *f.jackpot
+	return &pot, nil
+}
+
+// payJackpot pays the pot to a challenge that won. The caller holds f.mu.
+func (f *fakeChallengeStore) payJackpot(challenge models.Challenge, seed int64) {
+	payout := models.JackpotPayout{ID: uint(len(f.payouts) + 1), ChallengeID: challenge.ID, PlayerID: challenge.PlayerID, Amount: seed}
+	if f.jackpot != nil {
+		payout.Amount = f.jackpot.Amount
+	}
+	f.payouts = append(f.payouts, payout)
+	if f.balances != nil {
+		f.balances[challenge.PlayerID] += payout.Amount
+	}
+	f.jackpot = &models.Jackpot{Amount: seed}
+}
+
+func (f *fakeChallengeStore) GetJackpotPayouts(limit int) ([]models.JackpotPayout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payouts := []models.JackpotPayout{}
+	for i := len(f.payouts) - 1; i >= 0 && len(payouts) < limit; i-- {
+		payouts = append(payouts, f.payouts[i])
+	}
+	return payouts, nil
+}
+
+// fakeChallengeDefinitionStore keeps challenge definitions in memory.
+type fakeChallengeDefinitionStore struct {
+	mu          sync.Mutex
+	definitions map[uint]models.ChallengeDefinition
+	nextID      uint
+	inUse       map[uint]bool
+}
+
+func newFakeChallengeDefinitionStore() *fakeChallengeDefinitionStore {
+	return &fakeChallengeDefinitionStore{definitions: map[uint]models.ChallengeDefinition{}, inUse: map[uint]bool{}}
+}
+
+func (f *fakeChallengeDefinitionStore) GetChallengeDefinitions() ([]models.ChallengeDefinition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var definitions []models.ChallengeDefinition
+	for id := uint(1); id <= f.nextID; id++ {
+		if d, ok := f.definitions[id]; ok {
+			definitions = append(definitions, d)
+		}
+	}
+	return definitions, nil
+}
+
+func (f *fakeChallengeDefinitionStore) GetChallengeDefinitionByID(id uint) (*models.ChallengeDefinition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.definitions[id]
+	if !ok {
+		return nil, repository.ErrChallengeDefinitionNotFound
+	}
+	return &d, nil
+}
+
+func (f *fakeChallengeDefinitionStore) CreateChallengeDefinition(definition models.ChallengeDefinition) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.definitions {
+		if d.Name == definition.Name {
+			return 0, repository.ErrChallengeDefinitionExists
+		}
+	}
+	f.nextID++
+	definition.ID = f.nextID
+	f.definitions[definition.ID] = definition
+	return definition.ID, nil
+}
+
+func (f *fakeChallengeDefinitionStore) UpdateChallengeDefinition(id uint, definition models.ChallengeDefinition) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.definitions[id]; !ok {
+		return repository.ErrChallengeDefinitionNotFound
+	}
+	definition.ID = id
+	f.definitions[id] = definition
+	return nil
+}
+
+func (f *fakeChallengeDefinitionStore) DeleteChallengeDefinition(id uint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.definitions[id]; !ok {
+		return repository.ErrChallengeDefinitionNotFound
+	}
+	if f.inUse[id] {
+		return repository.ErrChallengeDefinitionInUse
+	}
+	delete(f.definitions, id)
+	return nil
+}
+
+// fakePaymentStore keeps payments in memory.
+type fakePaymentStore struct {
+	mu       sync.Mutex
+	payments map[uint]models.Payment
+}
+
+func newFakePaymentStore() *fakePaymentStore {
+	return &fakePaymentStore{payments: map[uint]models.Payment{}}
+}
+
+func (f *fakePaymentStore) CreatePayment(payment models.Payment) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payment.ID = uint(len(f.payments) + 1)
+	if payment.Status == "" {
+		payment.Status = "Pending"
+	}
+	if payment.Status == "Pending" && payment.TransactionID == "" && payment.ProcessAt == nil {
+		now := time.Now().UTC()
+		payment.ProcessAt = &now
+	}
+	f.payments[payment.ID] = payment
+	return payment.ID, nil
+}
+
+func (f *fakePaymentStore) GetPaymentByID(id uint) (*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.payments[id]
+	if !ok {
+		return nil, repository.ErrPaymentNotFound
+	}
+	return &p, nil
+}
+
+func (f *fakePaymentStore) GetPaymentByTransactionID(method, transactionID string) (*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.payments {
+		if p.Method == method && p.TransactionID == transactionID && transactionID != "" {
+			return &p, nil
+		}
+	}
+	return nil, repository.ErrPaymentNotFound
+}
+
+func (f *fakePaymentStore) UpdatePayment(payment models.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.payments[payment.ID]
+	if !ok {
+		return repository.ErrPaymentNotFound
+	}
+	if !stored.CanMoveTo(payment.Status) {
+		return repository.ErrPaymentTransition
+	}
+	stored.Status, stored.TransactionID, stored.ErrorMessage, stored.ProcessAt = payment.Status, payment.TransactionID, payment.ErrorMessage, payment.ProcessAt
+	if stored.Status != "Pending" {
+		stored.ProcessAt = nil
+	}
+	f.payments[payment.ID] = stored
+	return nil
+}
+
+func (f *fakePaymentStore) ClaimDuePayments(now time.Time, lease time.Duration, limit int) ([]models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []models.Payment
+	for id, payment := range f.payments {
+		if len(due) < limit && payment.Status == "Pending" && payment.ProcessAt != nil && !payment.ProcessAt.After(now) {
+			next := now.Add(lease)
+			payment.ProcessAt = &next
+			f.payments[id] = payment
+			due = append(due, payment)
+		}
+	}
+	return due, nil
+}
+
+// fakeMatchStore keeps matches in memory and plays moves with the game engine.
+type fakeMatchStore struct {
+	mu      sync.Mutex
+	matches map[uint]models.Match
+}
+
+func newFakeMatchStore() *fakeMatchStore {
+	return &fakeMatchStore{matches: map[uint]models.Match{}}
+}
+
+func (f *fakeMatchStore) CreateMatch(match models.Match) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	match.ID = uint(len(f.matches) + 1)
+	f.matches[match.ID] = match
+	return match.ID, nil
+}
+
+func (f *fakeMatchStore) GetMatchByID(id uint) (*models.Match, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.matches[id]
+	if !ok {
+		return nil, repository.ErrMatchNotFound
+	}
+	return &m, nil
+}
+
+func (f *fakeMatchStore) ApplyMove(matchID uint, playerID string, row, col int) (*models.Match, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.matches[matchID]
+	if !ok {
+		return nil, repository.ErrMatchNotFound
+	}
+	var mark game.Mark
+	switch playerID {
+	case m.PlayerXID:
+		mark = game.X
+	case m.PlayerOID:
+		mark = game.O
+	default:
+		return nil, repository.ErrNotMatchPlayer
+	}
+	g, err := game.Restore(game.Rules{Size: m.Size, WinLength: m.WinLength}, m.Board)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Play(mark, row, col); err != nil {
+		return nil, err
+	}
+	m.Moves = append(m.Moves, models.MatchMove{MatchID: m.ID, Seq: g.Moves(), PlayerID: playerID, Mark: mark.String(), Row: row, Col: col})
+	repository.ApplyGameState(&m, g)
+	f.matches[m.ID] = m
+	return &m, nil
+}
+
+// fakePublisher records the events published by handlers.
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []realtime.Event
+}
+
+func (f *fakePublisher) Publish(event realtime.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+}
+
+func (f *fakePublisher) types() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var types []string
+	for _, e := range f.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// fakeReservationStore keeps reservations in memory and rejects overlaps.
+// Hosts and guests are checked against players when it is set.
+type fakeReservationStore struct {
+	reservations []models.Reservation
+	players      *fakePlayerStore
+}
+
+func (f *fakeReservationStore) GetReservations(roomID uint, date time.Time, limit int) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	for _, r := range f.reservations {
+		if roomID == 0 || r.RoomID == roomID {
+			reservations = append(reservations, r)
+		}
+	}
+	return reservations, nil
+}
+
+func (f *fakeReservationStore) CreateReservation(reservation models.Reservation, now time.Time) (uint, error) {
+	if !reservation.StartAt.After(now) {
+		return 0, repository.ErrSlotTooSoon
+	}
+	if err := f.checkPlayers(reservation.PlayerIDs()); err != nil {
+		return 0, err
+	}
+	for _, r := range f.reservations {
+		if r.RoomID == reservation.RoomID && r.HoldsSlot() && r.StartAt.Before(reservation.EndAt) && reservation.StartAt.Before(r.EndAt) {
+			return 0, &repository.ReservationConflictError{Existing: r}
+		}
+	}
+	reservation.ID = uint(len(f.reservations) + 1)
+	reservation.Status = models.ReservationStatusBooked
+	f.reservations = append(f.reservations, reservation)
+	return reservation.ID, nil
+}
+
+func (f *fakeReservationStore) GetReservationByID(id uint) (*models.Reservation, error) {
+	if id == 0 || int(id) > len(f.reservations) {
+		return nil, repository.ErrReservationNotFound
+	}
+	reservation := f.reservations[id-1]
+	return &reservation, nil
+}
+
+func (f *fakeReservationStore) UpdateReservation(id uint, changes models.Reservation, cutoff time.Time) (*models.Reservation, error) {
+	reservation, err := f.changeable(id, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if changes.StartAt.IsZero() {
+		changes.StartAt = reservation.StartAt
+	}
+	if changes.EndAt.IsZero() {
+		changes.EndAt = reservation.EndAt
+	}
+	if !changes.EndAt.After(changes.StartAt) {
+		return nil, repository.ErrInvalidSlot
+	}
+	if !changes.StartAt.After(cutoff) {
+		return nil, repository.ErrSlotTooSoon
+	}
+	for _, r := range f.reservations {
+		if r.ID != id && r.RoomID == reservation.RoomID && r.HoldsSlot() && r.StartAt.Before(changes.EndAt) && changes.StartAt.Before(r.EndAt) {
+			return nil, &repository.ReservationConflictError{Existing: r}
+		}
+	}
+	reservation.StartAt, reservation.EndAt = changes.StartAt, changes.EndAt
+	if changes.Note != "" {
+		reservation.Note = changes.Note
+	}
+	if changes.Guests != nil {
+		if err := f.checkPlayers(changes.PlayerIDs()); err != nil {
+			return nil, err
+		}
+		reservation.Guests = changes.Guests
+	}
+	return f.GetReservationByID(id)
+}
+
+func (f *fakeReservationStore) GetPlayerReservations(playerID string) ([]models.Reservation, error) {
+	if err := f.checkPlayers([]string{playerID}); err != nil {
+		return nil, err
+	}
+	reservations := []models.Reservation{}
+	for _, r := range f.reservations {
+		for _, id := range r.PlayerIDs() {
+			if id == playerID {
+				reservations = append(reservations, r)
+				break
+			}
+		}
+	}
+	return reservations, nil
+}
+
+func (f *fakeReservationStore) checkPlayers(ids []string) error {
+	if f.players == nil {
+		return nil
+	}
+	for _, id := range ids {
+		if _, err := f.players.GetPlayerByID(id); err != nil {
+			return fmt.Errorf("%w: %s", err, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeReservationStore) CancelReservation(id uint, cutoff time.Time) (*models.Reservation, error) {
+	reservation, err := f.changeable(id, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	reservation.Status = models.ReservationStatusCancelled
+	return f.GetReservationByID(id)
+}
+
+func (f *fakeReservationStore) CheckInReservation(id uint, now time.Time, grace time.Duration) (*models.Reservation, error) {
+	if _, err := f.GetReservationByID(id); err != nil {
+		return nil, err
+	}
+	reservation := &f.reservations[id-1]
+	if reservation.Status != models.ReservationStatusBooked {
+		return nil, repository.ErrReservationClosed
+	}
+	if now.Before(reservation.StartAt.Add(-grace)) || !now.Before(reservation.StartAt.Add(grace)) {
+		return nil, repository.ErrCheckInClosed
+	}
+	reservation.Status = models.ReservationStatusCheckedIn
+	return f.GetReservationByID(id)
+}
+
+func (f *fakeReservationStore) MarkNoShows(cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeReservationStore) CompleteReservations(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// changeable returns the stored reservation if it is booked and starts after cutoff.
+func (f *fakeReservationStore) changeable(id uint, cutoff time.Time) (*models.Reservation, error) {
+	if _, err := f.GetReservationByID(id); err != nil {
+		return nil, err
+	}
+	reservation := &f.reservations[id-1]
+	if reservation.Status != models.ReservationStatusBooked || !reservation.StartAt.After(cutoff) {
+		return nil, repository.ErrReservationClosed
+	}
+	return reservation, nil
+}
+
+// fakeWalletStore keeps wallets and their ledger entries in memory.
+type fakeWalletStore struct {
+	wallets map[uint]models.Wallet
+	entries []models.LedgerEntry
+}
+
+func (f *fakeWalletStore) GetWallet(playerID uint) (*models.Wallet, error) {
+	wallet, ok := f.wallets[playerID]
+	if !ok {
+		wallet = models.Wallet{PlayerID: playerID}
+	}
+	return &wallet, nil
+}
+
+func (f *fakeWalletStore) GetLedger(playerID uint, limit int) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	for i := len(f.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if f.entries[i].Account == models.PlayerAccount(playerID) {
+			entries = append(entries, f.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func (f *fakeWalletStore) CheckLedger() ([]models.LedgerDiscrepancy, error) {
+	return []models.LedgerDiscrepancy{}, nil
+}
+
+// fakeIdempotencyStore keeps idempotency keys in memory.
+type fakeIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]models.IdempotencyKey
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{keys: map[string]models.IdempotencyKey{}}
+}
+
+func (f *fakeIdempotencyStore) ClaimIdempotencyKey(scope, key, requestHash, token string, now time.Time, lease time.Duration) (*models.IdempotencyKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.keys[scope+" "+key]
+	if !ok || !stored.ExpiresAt.After(now) || (!stored.Completed() && !stored.LockedUntil.After(now)) {
+		f.keys[scope+" "+key] = models.IdempotencyKey{Scope: scope, Key: key, RequestHash: requestHash, ClaimToken: token, CreatedAt: now, LockedUntil: now.Add(lease), ExpiresAt: now.Add(lease)}
+		return nil, nil
+	}
+	switch {
+	case stored.RequestHash != requestHash:
+		return nil, repository.ErrIdempotencyKeyMismatch
+	case !stored.Completed():
+		return nil, repository.ErrIdempotencyKeyInProgress
+	}
+	return &stored, nil
+}
+
+func (f *fakeIdempotencyStore) CompleteIdempotencyKey(completed models.IdempotencyKey) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := completed.Scope + " " + completed.Key
+	stored, ok := f.keys[id]
+	if ok && !stored.Completed() && stored.ClaimToken == completed.ClaimToken {
+		stored.StatusCode, stored.ContentType, stored.Location, stored.Body = completed.StatusCode, completed.ContentType, completed.Location, completed.Body
+		stored.ExpiresAt = completed.ExpiresAt
+		f.keys[id] = stored
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyStore) ReleaseIdempotencyKey(scope, key, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if stored, ok := f.keys[scope+" "+key]; ok && !stored.Completed() && stored.ClaimToken == token {
+		delete(f.keys, scope+" "+key)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyStore) PurgeIdempotencyKeys(now time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var purged int64
+	for id, stored := range f.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(f.keys, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// fakeWebhookStore keeps webhooks and their deliveries in memory.
+type fakeWebhookStore struct {
+	repository.WebhookStore
+	mu         sync.Mutex
+	webhooks   map[uint]models.Webhook
+	deliveries []models.WebhookDelivery
+	nextID     uint
+}
+
+func newFakeWebhookStore() *fakeWebhookStore {
+	return &fakeWebhookStore{webhooks: map[uint]models.Webhook{}}
+}
+
+func (f *fakeWebhookStore) GetWebhooks() ([]models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var webhooks []models.Webhook
+	for id := uint(1); id <= f.nextID; id++ {
+		if w, ok := f.webhooks[id]; ok {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (f *fakeWebhookStore) GetWebhookByID(id uint) (*models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, ok := f.webhooks[id]
+	if !ok {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return &w, nil
+}
+
+func (f *fakeWebhookStore) CreateWebhook(webhook models.Webhook) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	webhook.ID = f.nextID
+	webhook.CreatedAt = time.Now()
+	f.webhooks[webhook.ID] = webhook
+	return webhook.ID, nil
+}
+
+func (f *fakeWebhookStore) DeleteWebhook(id uint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.webhooks[id]; !ok {
+		return repository.ErrWebhookNotFound
+	}
+	delete(f.webhooks, id)
+	return nil
+}
+
+func (f *fakeWebhookStore) GetWebhookDeliveries(webhookID uint, status string, limit int) ([]models.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deliveries []models.WebhookDelivery
+	for i := len(f.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := f.deliveries[i]
+		if d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,160 @@
+// handlers/idempotency.go
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader names the header clients set to make retries safe.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencyPollInterval is how often a retry checks whether the
+	// request it duplicates has finished.
+	idempotencyPollInterval = 50 * time.Millisecond
+)
+
+// Idempotency makes create endpoints safe to retry. A request carrying an
+// Idempotency-Key is handled once; retries with the same key and body get
+// the first response replayed, retries with a different body are rejected
+// with 422, and retries arriving while the first request is still running
+// wait for it to finish. Server errors are not stored, so a retry after one
+// handles the request again.
+type Idempotency struct {
+	keys repository.IdempotencyStore
+	cfg  config.IdempotencyConfig
+}
+
+// NewIdempotency creates the middleware backed by the given store and settings.
+func NewIdempotency(keys repository.IdempotencyStore, cfg config.IdempotencyConfig) *Idempotency {
+	return &Idempotency{keys: keys, cfg: cfg}
+}
+
+// Middleware returns the gin middleware. Requests without the header pass
+// straight through.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{Error: "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{Error: "Failed to read the request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		scope := c.Request.Method + " " + c.FullPath()
+
+		token := models.NewID()
+		stored, err := i.claim(c, scope, key, hash, token)
+		switch {
+		case errors.Is(err, repository.ErrIdempotencyKeyMismatch):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: "Idempotency-Key was already used for a different request"})
+			return
+		case errors.Is(err, repository.ErrIdempotencyKeyInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, models.ErrorResponse{Error: "A request with this Idempotency-Key is still in progress"})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to check the Idempotency-Key"})
+			return
+		case stored != nil:
+			c.Header(IdempotentReplayedHeader, "true")
+			if stored.Location != "" {
+				c.Header("Location", stored.Location)
+			}
+			c.Data(stored.StatusCode, stored.ContentType, []byte(stored.Body))
+			c.Abort()
+			return
+		}
+
+		// Handle the request, keeping a copy of the response. The claim is
+		// released if the handler fails or panics.
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := i.keys.ReleaseIdempotencyKey(scope, key, token); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+		}()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		header := c.Writer.Header()
+		err = i.keys.CompleteIdempotencyKey(models.IdempotencyKey{
+			Scope: scope, Key: key, ClaimToken: token, StatusCode: status,
+			ContentType: header.Get("Content-Type"), Location: header.Get("Location"), Body: recorder.body.String(),
+			ExpiresAt: time.Now().UTC().Add(i.cfg.TTL),
+		})
+		if err != nil {
+			log.Printf("Failed to store the response for idempotency key %q: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// claim claims the key for LockTimeout under token, waiting up to
+// WaitTimeout while another request holds it.
+func (i *Idempotency) claim(c *gin.Context, scope, key, hash, token string) (*models.IdempotencyKey, error) {
+	deadline := time.Now().Add(i.cfg.WaitTimeout)
+	for {
+		stored, err := i.keys.ClaimIdempotencyKey(scope, key, hash, token, time.Now().UTC(), i.cfg.LockTimeout)
+		if !errors.Is(err, repository.ErrIdempotencyKeyInProgress) || time.Now().After(deadline) {
+			return stored, err
+		}
+		select {
+		case <-c.Request.Context().Done():
+			return nil, err
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+// responseRecorder copies everything written to the response.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,174 @@
+// handlers/idempotency_test.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// performIdempotentRequest sends body as JSON with the given Idempotency-Key.
+func performIdempotentRequest(r http.Handler, method, path, key string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(body)
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newCountingRouter serves POST /things through the middleware, answering
+// with the given status codes in turn and counting the calls that got
+// through. Each call blocks until release is closed, when given.
+func newCountingRouter(cfg config.IdempotencyConfig, release chan struct{}, statuses ...int) (*gin.Engine, *atomic.Int32) {
+	var calls atomic.Int32
+	r := gin.New()
+	r.POST("/things", NewIdempotency(newFakeIdempotencyStore(), cfg).Middleware(), func(c *gin.Context) {
+		n := calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		status := statuses[min(int(n), len(statuses))-1]
+		c.JSON(status, gin.H{"call": n})
+	})
+	return r, &calls
+}
+
+func TestIdempotentPaymentRetriesReplayTheFirstResponse(t *testing.T) {
+	r, payments, _ := newPaymentRouter(t, config.Default().Payment)
+	req := PaymentRequest{PlayerID: 1, Method: "CreditCard", Amount: 1000, Details: json.RawMessage(`{}`)}
+
+	first := performIdempotentRequest(r, http.MethodPost, "/payments", "k1", req)
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	retry := performIdempotentRequest(r, http.MethodPost, "/payments", "k1", req)
+	assert.Equal(t, first.Code, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/payments/1", retry.Header().Get("Location"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+
+	// The key cannot be reused for another payment
+	req.Amount = 2000
+	w := performIdempotentRequest(r, http.MethodPost, "/payments", "k1", req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = performIdempotentRequest(r, http.MethodPost, "/payments", "k2", req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	awaitPayment(t, payments, 1)
+	awaitPayment(t, payments, 2)
+	assert.Len(t, payments.payments, 2)
+}
+
+func TestConcurrentIdempotentChallengeEntries(t *testing.T) {
+	r, challenges, _ := newChallengeRouter(config.Default().Challenge)
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 10)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = performIdempotentRequest(r, http.MethodPost, "/challenges", "entry-1", ChallengeRequest{PlayerID: 7})
+		}(i)
+	}
+	wg.Wait()
+
+	// Every duplicate gets the entry instead of a cooldown error
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, responses[0].Body.String(), w.Body.String())
+	}
+	assert.Len(t, challenges.challenges, 1)
+}
+
+func TestIdempotentRetriesWaitForTheFirstRequest(t *testing.T) {
+	release := make(chan struct{})
+	r, calls := newCountingRouter(config.Default().Idempotency, release, http.StatusCreated)
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1}) }()
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	retry := make(chan *httptest.ResponseRecorder)
+	go func() { retry <- performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1}) }()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	w1, w2 := <-first, <-retry
+	assert.Equal(t, http.StatusCreated, w1.Code)
+	assert.Equal(t, http.StatusCreated, w2.Code)
+	assert.Equal(t, w1.Body.String(), w2.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotentRetriesGiveUpWaiting(t *testing.T) {
+	cfg := config.Default().Idempotency
+	cfg.WaitTimeout = 50 * time.Millisecond
+	release := make(chan struct{})
+	r, calls := newCountingRouter(cfg, release, http.StatusCreated)
+
+	done := make(chan struct{})
+	go func() {
+		performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	w := performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	close(release)
+	<-done
+}
+
+func TestIdempotencyDoesNotKeepServerErrors(t *testing.T) {
+	r, calls := newCountingRouter(config.Default().Idempotency, nil, http.StatusInternalServerError, http.StatusCreated)
+
+	w := performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	w = performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyKeysExpire(t *testing.T) {
+	cfg := config.Default().Idempotency
+	cfg.TTL = 50 * time.Millisecond
+	r, calls := newCountingRouter(cfg, nil, http.StatusCreated)
+
+	performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 1})
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	// An expired key may even be used for another request
+	w := performIdempotentRequest(r, http.MethodPost, "/things", "k1", gin.H{"n": 2})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyRejectsLongKeys(t *testing.T) {
+	r, calls := newCountingRouter(config.Default().Idempotency, nil, http.StatusCreated)
+
+	w := performIdempotentRequest(r, http.MethodPost, "/things", strings.Repeat("k", 256), gin.H{"n": 1})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	// Requests without a key pass straight through
+	performRequest(r, http.MethodPost, "/things", gin.H{"n": 1})
+	performRequest(r, http.MethodPost, "/things", gin.H{"n": 1})
+	assert.Equal(t, int32(2), calls.Load())
+}
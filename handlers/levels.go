@@ -9,6 +9,16 @@ import (
     "interview_YangYang_20241010/repository"
 )
 
+// LevelHandler serves the level endpoints.
+type LevelHandler struct {
+    levels repository.LevelStore
+}
+
+// NewLevelHandler creates a LevelHandler backed by the given stores.
+func NewLevelHandler(levels repository.LevelStore) *LevelHandler {
+    return &LevelHandler{levels: levels}
+}
+
 // @Summary Get all levels
 // @Description Retrieve a list of all levels
 // @Tags levels
@@ -17,8 +27,8 @@ import (
 // @Success 200 {array} models.Level "A list of levels"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /levels [get]
-func GetLevels(c *gin.Context) {
-    levels, err := repository.GetAllLevels()
+func (h *LevelHandler) GetLevels(c *gin.Context) {
+    levels, err := h.levels.GetAllLevels()
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -36,7 +46,7 @@ func GetLevels(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /levels [post]
-func CreateLevel(c *gin.Context) {
+func (h *LevelHandler) CreateLevel(c *gin.Context) {
     var level models.Level
     if err := c.ShouldBindJSON(&level); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
@@ -49,7 +59,7 @@ func CreateLevel(c *gin.Context) {
         return
     }
 
-    id, err := repository.CreateLevel(level)
+    id, err := h.levels.CreateLevel(level)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -11,6 +11,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// LogHandler serves the log endpoints.
+type LogHandler struct {
+	logs repository.LogStore
+}
+
+// NewLogHandler creates a LogHandler backed by the given stores.
+func NewLogHandler(logs repository.LogStore) *LogHandler {
+	return &LogHandler{logs: logs}
+}
+
 // LogRequest represents the request body for creating a new log.
 type LogRequest struct {
 	PlayerID uint   `json:"player_id" binding:"required"`
@@ -36,7 +46,7 @@ type LogResponse struct {
 // @Success 200 {array} models.Log "A list of game logs"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /logs [get]
-func GetLogs(c *gin.Context) {
+func (h *LogHandler) GetLogs(c *gin.Context) {
 	var playerID *uint
 	var action *string
 	var startTime *time.Time
@@ -78,7 +88,7 @@ func GetLogs(c *gin.Context) {
 	}
 
 	// Query logs from the repository
-	logs, err := repository.QueryLogs(playerID, action, startTime, endTime, limit)
+	logs, err := h.logs.QueryLogs(playerID, action, startTime, endTime, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve logs"})
 		return
@@ -98,7 +108,7 @@ func GetLogs(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /logs [post]
-func CreateLog(c *gin.Context) {
+func (h *LogHandler) CreateLog(c *gin.Context) {
 	var req LogRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -113,7 +123,7 @@ func CreateLog(c *gin.Context) {
 		Timestamp: time.Now(),
 	}
 
-	logID, err := repository.CreateLog(logEntry)
+	logID, err := h.logs.CreateLog(logEntry)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create log"})
 		return
@@ -0,0 +1,234 @@
+// handlers/matches.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/realtime"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchHandler serves the OXO match endpoints.
+type MatchHandler struct {
+	matches repository.MatchStore
+	rooms   repository.RoomStore
+	players repository.PlayerStore
+	events  realtime.Publisher
+}
+
+// NewMatchHandler creates a MatchHandler backed by the given stores. New
+// matches and moves are broadcast to the match's room through events.
+func NewMatchHandler(matches repository.MatchStore, rooms repository.RoomStore, players repository.PlayerStore, events realtime.Publisher) *MatchHandler {
+	return &MatchHandler{matches: matches, rooms: rooms, players: players, events: events}
+}
+
+// MatchRequest represents the request body for creating a match.
+type MatchRequest struct {
+	RoomID    uint   `json:"room_id" binding:"required"`
+	PlayerXID string `json:"player_x_id" binding:"required"` // Moves first
+	PlayerOID string `json:"player_o_id" binding:"required"`
+	Size      int    `json:"size"`       // Board size, defaults to 3
+	WinLength int    `json:"win_length"` // Marks in a row needed to win, defaults to min(size, 5)
+}
+
+// MoveRequest represents the request body for submitting a move.
+type MoveRequest struct {
+	PlayerID string `json:"player_id" binding:"required"`
+	Row      *int   `json:"row" binding:"required"`
+	Col      *int   `json:"col" binding:"required"`
+}
+
+// MatchResponse is a match together with its board split into rows.
+type MatchResponse struct {
+	models.Match
+	Rows []string `json:"rows"`
+}
+
+// @Summary Create a match
+// @Description Start a new OXO match between two players seated in a room. X moves first. A room holds one match in progress at a time.
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param match body MatchRequest true "Match Information"
+// @Success 201 {object} MatchResponse "Created match"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 409 {object} models.ErrorResponse "Players not seated, room closed or already in a match"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /matches [post]
+func (h *MatchHandler) CreateMatch(c *gin.Context) {
+	var req MatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.PlayerXID == req.PlayerOID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "A match needs two different players"})
+		return
+	}
+
+	// Apply the classic 3x3 defaults
+	if req.Size == 0 {
+		req.Size = 3
+	}
+	if req.WinLength == 0 {
+		req.WinLength = game.DefaultWinLength(req.Size)
+	}
+	g, err := game.New(game.Rules{Size: req.Size, WinLength: req.WinLength})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Check the room and both players exist
+	if _, err := h.rooms.GetRoomByID(req.RoomID); err != nil {
+		if errors.Is(err, repository.ErrRoomNotFound) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	for _, playerID := range []string{req.PlayerXID, req.PlayerOID} {
+		if _, err := h.players.GetPlayerByID(playerID); err != nil {
+			if errors.Is(err, repository.ErrPlayerNotFound) {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid player ID " + playerID})
+			} else {
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+	}
+
+	match := models.Match{
+		RoomID:    req.RoomID,
+		PlayerXID: req.PlayerXID,
+		PlayerOID: req.PlayerOID,
+	}
+	repository.ApplyGameState(&match, g)
+
+	id, err := h.matches.CreateMatch(match)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRoomClosed), errors.Is(err, repository.ErrRoomBusy), errors.Is(err, repository.ErrNotInRoom):
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	match.ID = id
+	resp := newMatchResponse(&match)
+	h.events.Publish(realtime.Event{Type: realtime.EventMatchCreated, RoomID: match.RoomID, Data: resp})
+	c.JSON(http.StatusCreated, resp)
+}
+
+// @Summary Get match state
+// @Description Retrieve the board, turn, outcome and move history of a match
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path uint true "Match ID"
+// @Success 200 {object} MatchResponse "Match state"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Match not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /matches/{id} [get]
+func (h *MatchHandler) GetMatch(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid match ID"})
+		return
+	}
+
+	match, err := h.matches.GetMatchByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrMatchNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Match not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, newMatchResponse(match))
+}
+
+// @Summary Submit a move
+// @Description Place the player's mark on the board. Rows and columns start at 0.
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path uint true "Match ID"
+// @Param move body MoveRequest true "Move"
+// @Success 200 {object} MatchResponse "Match state after the move"
+// @Failure 400 {object} models.ErrorResponse "Move outside the board"
+// @Failure 403 {object} models.ErrorResponse "Player is not part of the match"
+// @Failure 404 {object} models.ErrorResponse "Match not found"
+// @Failure 409 {object} models.ErrorResponse "Not the player's turn, cell occupied or match over"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /matches/{id}/moves [post]
+func (h *MatchHandler) SubmitMove(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid match ID"})
+		return
+	}
+
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resp, err := playMove(h.matches, h.events, id, req)
+	if err != nil {
+		c.JSON(moveErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// playMove applies a move and broadcasts the new state to the match's room.
+// Both the REST endpoint and the room WebSocket submit moves through it.
+func playMove(matches repository.MatchStore, events realtime.Publisher, matchID uint, req MoveRequest) (MatchResponse, error) {
+	if req.PlayerID == "" || req.Row == nil || req.Col == nil {
+		return MatchResponse{}, errIncompleteMove
+	}
+	match, err := matches.ApplyMove(matchID, req.PlayerID, *req.Row, *req.Col)
+	if err != nil {
+		return MatchResponse{}, err
+	}
+	resp := newMatchResponse(match)
+	events.Publish(realtime.Event{Type: realtime.EventMatchMove, RoomID: match.RoomID, Data: resp})
+	return resp, nil
+}
+
+var errIncompleteMove = errors.New("player_id, row and col are required")
+
+// moveErrorStatus maps a move error to its HTTP status code.
+func moveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrMatchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrNotMatchPlayer):
+		return http.StatusForbidden
+	case errors.Is(err, game.ErrOutOfBounds), errors.Is(err, errIncompleteMove):
+		return http.StatusBadRequest
+	case errors.Is(err, game.ErrNotYourTurn), errors.Is(err, game.ErrCellOccupied), errors.Is(err, game.ErrGameOver):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func newMatchResponse(match *models.Match) MatchResponse {
+	rows := make([]string, 0, match.Size)
+	for i := 0; i+match.Size <= len(match.Board) && match.Size > 0; i += match.Size {
+		rows = append(rows, match.Board[i:i+match.Size])
+	}
+	return MatchResponse{Match: *match, Rows: rows}
+}
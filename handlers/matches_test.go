@@ -0,0 +1,103 @@
+// handlers/matches_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMatchRouter() (*gin.Engine, *fakeMatchStore, *fakePublisher) {
+	matches := newFakeMatchStore()
+	rooms := newFakeRoomStore()
+	rooms.CreateRoom(models.Room{Name: "Room A"})
+	players := newFakePlayerStore()
+	players.CreatePlayer(models.Player{Name: "Alice"})
+	players.CreatePlayer(models.Player{Name: "Bob"})
+	events := &fakePublisher{}
+	h := NewMatchHandler(matches, rooms, players, events)
+
+	r := gin.New()
+	r.POST("/matches", h.CreateMatch)
+	r.GET("/matches/:id", h.GetMatch)
+	r.POST("/matches/:id/moves", h.SubmitMove)
+	return r, matches, events
+}
+
+func move(playerID string, row, col int) MoveRequest {
+	return MoveRequest{PlayerID: playerID, Row: &row, Col: &col}
+}
+
+func TestCreateMatchHandler(t *testing.T) {
+	r, matches, events := newMatchRouter()
+
+	w := performRequest(r, http.MethodPost, "/matches", MatchRequest{RoomID: 1, PlayerXID: "1", PlayerOID: "2"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp MatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.Size)
+	assert.Equal(t, 3, resp.WinLength)
+	assert.Equal(t, "X", resp.NextTurn)
+	assert.Equal(t, []string{"...", "...", "..."}, resp.Rows)
+
+	// Larger boards default to five in a row
+	w = performRequest(r, http.MethodPost, "/matches", MatchRequest{RoomID: 1, PlayerXID: "1", PlayerOID: "2", Size: 15})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 5, matches.matches[2].WinLength)
+
+	for _, req := range []MatchRequest{
+		{RoomID: 1, PlayerXID: "1", PlayerOID: "1"},
+		{RoomID: 9, PlayerXID: "1", PlayerOID: "2"},
+		{RoomID: 1, PlayerXID: "1", PlayerOID: "9"},
+		{RoomID: 1, PlayerXID: "1", PlayerOID: "2", Size: 3, WinLength: 4},
+	} {
+		w = performRequest(r, http.MethodPost, "/matches", req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%+v", req)
+	}
+	assert.Len(t, matches.matches, 2)
+	assert.Equal(t, []string{realtime.EventMatchCreated, realtime.EventMatchCreated}, events.types())
+}
+
+func TestSubmitMoveHandler(t *testing.T) {
+	r, _, events := newMatchRouter()
+	performRequest(r, http.MethodPost, "/matches", MatchRequest{RoomID: 1, PlayerXID: "1", PlayerOID: "2"})
+
+	w := performRequest(r, http.MethodPost, "/matches/1/moves", move("2", 0, 0))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = performRequest(r, http.MethodPost, "/matches/1/moves", move("3", 0, 0))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = performRequest(r, http.MethodPost, "/matches/1/moves", move("1", 3, 0))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performRequest(r, http.MethodPost, "/matches/2/moves", move("1", 0, 0))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// X takes the top row
+	for _, m := range []MoveRequest{move("1", 0, 0), move("2", 1, 0), move("1", 0, 1), move("2", 1, 1), move("1", 0, 2)} {
+		w = performRequest(r, http.MethodPost, "/matches/1/moves", m)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	w = performRequest(r, http.MethodPost, "/matches/1/moves", move("2", 1, 2))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = performRequest(r, http.MethodGet, "/matches/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp MatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "won", resp.Status)
+	assert.Equal(t, "X", resp.Winner)
+	assert.Empty(t, resp.NextTurn)
+	assert.Equal(t, []string{"XXX", "OO.", "..."}, resp.Rows)
+	assert.Len(t, resp.Moves, 5)
+
+	// Only accepted moves are broadcast
+	assert.Len(t, events.events, 6)
+	last := events.events[5]
+	assert.Equal(t, realtime.EventMatchMove, last.Type)
+	assert.Equal(t, uint(1), last.RoomID)
+}
@@ -2,60 +2,109 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/gateway"
 	"interview_YangYang_20241010/models"
 	"interview_YangYang_20241010/repository"
+	"interview_YangYang_20241010/webhook"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// paymentPollInterval is how often a long-poll rereads a pending payment
+	// in case it was updated by another server.
+	paymentPollInterval = time.Second
+	// maxCallbackBody caps the size of a provider callback.
+	maxCallbackBody = 1 << 20
+)
+
+// callbackStatuses maps the transaction states providers report in
+// callbacks to payment statuses.
+var callbackStatuses = map[string]string{
+	gateway.StatusAuthorized: "Pending",
+	gateway.StatusPending:    "Pending",
+	gateway.StatusCaptured:   "Success",
+	gateway.StatusDeclined:   "Failed",
+	gateway.StatusRefunded:   "Refunded",
+}
+
+// PaymentHandler serves the payment endpoints. Accepted payments are
+// charged by the jobs.PaymentProcessor.
+type PaymentHandler struct {
+	payments repository.PaymentStore
+	cfg      config.PaymentConfig
+	updates  *paymentUpdates
+}
+
+// NewPaymentHandler creates a PaymentHandler backed by the given store and
+// settings.
+func NewPaymentHandler(payments repository.PaymentStore, cfg config.PaymentConfig) *PaymentHandler {
+	return &PaymentHandler{payments: payments, cfg: cfg, updates: newPaymentUpdates()}
+}
+
 // PaymentRequest represents the request body for creating a new payment.
 type PaymentRequest struct {
-	PlayerID uint          `json:"player_id" binding:"required"`
-	Method   string        `json:"method" binding:"required"` // e.g., CreditCard, BankTransfer, ThirdParty, Blockchain
-	Amount   float64       `json:"amount" binding:"required,gt=0"`
-	Details  json.RawMessage `json:"details" binding:"required"` // Specific details based on payment method
+	PlayerID uint            `json:"player_id" binding:"required"`
+	Method   string          `json:"method" binding:"required"`      // e.g., CreditCard, BankTransfer, ThirdParty, Blockchain
+	Amount   int64           `json:"amount" binding:"required,gt=0"` // In cents
+	Details  json.RawMessage `json:"details" binding:"required"`     // Specific details based on payment method
 }
 
 // PaymentResponse represents the response after processing a payment.
 type PaymentResponse struct {
+	ID            uint   `json:"id,omitempty"`
 	Status        string `json:"status"`
 	TransactionID string `json:"transaction_id,omitempty"`
 	ErrorMessage  string `json:"error_message,omitempty"`
 }
 
 // @Summary Process a Payment
-// @Description Process a payment using various payment methods.
+// @Description Accept a payment for processing with the gateway of its method. The payment is created as Pending and charged in the background; follow the Location header, optionally with ?wait=, to learn the outcome.
 // @Tags Payments
 // @Accept json
 // @Produce json
 // @Param payment body PaymentRequest true "Payment Information"
-// @Success 200 {object} models.Payment "Payment Status"
-// @Failure 400 {object} models.Payment "Bad Request"
-// @Failure 500 {object} models.Payment "Internal Server Error"
+// @Param Idempotency-Key header string false "Makes retries safe: a retry with the same key and body gets the first response replayed"
+// @Success 202 {object} PaymentResponse "Payment accepted"
+// @Header 202 {string} Location "URL of the payment"
+// @Failure 400 {object} PaymentResponse "Bad Request"
+// @Failure 409 {object} models.ErrorResponse "A request with the same Idempotency-Key is still in progress"
+// @Failure 422 {object} models.ErrorResponse "Idempotency-Key was used for a different request"
+// @Failure 500 {object} PaymentResponse "Internal Server Error"
 // @Router /payments [post]
-func ProcessPayment(c *gin.Context) {
+func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	var req PaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, PaymentResponse{ErrorMessage: err.Error()})
 		return
 	}
 
-	// Validate payment method
-	switch req.Method {
-	case "CreditCard", "BankTransfer", "ThirdParty", "Blockchain":
-		// Valid methods
-	default:
+	// Validate payment method against the enabled methods
+	if !slices.Contains(h.cfg.Methods, req.Method) {
 		c.JSON(http.StatusBadRequest, PaymentResponse{ErrorMessage: "Invalid payment method"})
 		return
 	}
 
-	// Create a new payment record
+	// Validate the amount against the configured limits
+	if req.Amount < h.cfg.MinAmount || req.Amount > h.cfg.MaxAmount {
+		c.JSON(http.StatusBadRequest, PaymentResponse{ErrorMessage: fmt.Sprintf("Amount must be between %d and %d cents", h.cfg.MinAmount, h.cfg.MaxAmount)})
+		return
+	}
+
+	// Create a new payment record, due to be charged in the background
 	payment := models.Payment{
 		PlayerID: req.PlayerID,
 		Method:   req.Method,
@@ -64,40 +113,50 @@ func ProcessPayment(c *gin.Context) {
 		Status:   "Pending",
 	}
 
-	paymentID, err := repository.CreatePayment(payment)
+	paymentID, err := h.payments.CreatePayment(payment)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, PaymentResponse{ErrorMessage: "Failed to create payment record"})
 		return
 	}
 
-	// Simulate payment processing asynchronously
-	go handlePaymentProcessing(paymentID)
-
-	// Respond with payment status
-	c.JSON(http.StatusOK, PaymentResponse{
-		Status: "Payment processing initiated",
+	// Point the client at the payment to follow its outcome
+	c.Header("Location", fmt.Sprintf("/payments/%d", paymentID))
+	c.JSON(http.StatusAccepted, PaymentResponse{
+		ID:     paymentID,
+		Status: "Pending",
 	})
 }
 
 // @Summary Get Payment Details
-// @Description Retrieve detailed information about a specific payment.
+// @Description Retrieve detailed information about a specific payment. With wait, a Pending payment is held until it leaves Pending or the wait is over, whichever comes first, and then returned as it stands.
 // @Tags Payments
 // @Accept json
 // @Produce json
 // @Param id path uint true "Payment ID"
+// @Param wait query string false "Long-poll for up to this long while the payment is Pending, e.g. 30s; capped at payment.max_wait"
 // @Success 200 {object} models.Payment "Payment Details"
+// @Failure 400 {object} models.ErrorResponse "Invalid payment ID or wait"
 // @Failure 404 {object} models.ErrorResponse "Payment Not Found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /payments/{id} [get]
-func GetPaymentDetails(c *gin.Context) {
+func (h *PaymentHandler) GetPaymentDetails(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := parseUint(idParam)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
 		return
 	}
+	wait, err := parseWait(c.Query("wait"), h.cfg.MaxWait)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wait, use a duration such as 30s"})
+		return
+	}
+	if wait > 0 {
+		// Keep the server's write timeout from cutting the wait short
+		http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + 5*time.Second))
+	}
 
-	payment, err := repository.GetPaymentByID(id)
+	payment, err := h.awaitPayment(c.Request.Context(), id, wait)
 	if err != nil {
 		if errors.Is(err, repository.ErrPaymentNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
@@ -110,83 +169,208 @@ func GetPaymentDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, payment)
 }
 
-// parseUint converts a string to uint, handling errors.
-func parseUint(s string) (uint, error) {
-	var i uint64
-	_, err := fmt.Sscanf(s, "%d", &i)
-	return uint(i), err
-}
-
-// handlePaymentProcessing simulates payment processing based on the payment method.
-func handlePaymentProcessing(paymentID uint) {
-	// Retrieve the payment record
-	payment, err := repository.GetPaymentByID(paymentID)
+// @Summary Receive a provider callback
+// @Description Providers that confirm payments later report the outcome here, signed in the X-Gateway-Signature header as t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed with the callback secret configured for the provider>. The payment is found by the provider's transaction ID. Repeated callbacks are acknowledged without effect; callbacks that would move a payment backwards, such as from Failed to Success, are rejected.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param provider path string true "Payment method of the provider, in lower case, e.g. banktransfer"
+// @Param X-Gateway-Signature header string true "Signature of the body"
+// @Param callback body gateway.Result true "The transaction's state at the provider"
+// @Success 200 {object} PaymentResponse "The payment's status after the callback"
+// @Failure 400 {object} models.ErrorResponse "Malformed callback"
+// @Failure 401 {object} models.ErrorResponse "Invalid signature"
+// @Failure 404 {object} models.ErrorResponse "Unknown provider or transaction"
+// @Failure 409 {object} models.ErrorResponse "The payment cannot make the transition"
+// @Failure 422 {object} models.ErrorResponse "The callback does not match the payment"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /payments/callbacks/{provider} [post]
+func (h *PaymentHandler) HandleProviderCallback(c *gin.Context) {
+	method, secret, ok := h.callbackProvider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Unknown payment provider"})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBody))
 	if err != nil {
-		// Handle error (logging can be added here)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Failed to read the callback"})
+		return
+	}
+	if err := webhook.Verify(secret, c.GetHeader(gateway.CallbackSignatureHeader), body, time.Now(), h.cfg.CallbackTolerance); err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Invalid callback signature"})
 		return
 	}
 
-	// Simulate different payment methods
-	var transactionID string
-	var status string
-	var errorMessage string
+	var result gateway.Result
+	if err := json.Unmarshal(body, &result); err != nil || result.TransactionID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Callback must name a transaction_id and status"})
+		return
+	}
+	status, ok := callbackStatuses[result.Status]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Unknown transaction status %q", result.Status)})
+		return
+	}
 
-	switch payment.Method {
-	case "CreditCard":
-		transactionID, status, errorMessage = processCreditCardPayment(payment)
-	case "BankTransfer":
-		transactionID, status, errorMessage = processBankTransfer(payment)
-	case "ThirdParty":
-		transactionID, status, errorMessage = processThirdPartyPayment(payment)
-	case "Blockchain":
-		transactionID, status, errorMessage = processBlockchainPayment(payment)
-	default:
-		status = "Failed"
-		errorMessage = "Unsupported payment method"
+	payment, err := h.payments.GetPaymentByTransactionID(method, result.TransactionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPaymentNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Unknown transaction"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve payment"})
+		return
+	}
+	if result.Reference != "" && result.Reference != strconv.FormatUint(uint64(payment.ID), 10) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: "Reference does not match the payment"})
+		return
+	}
+	if result.Amount != 0 && result.Amount != payment.Amount {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: "Amount does not match the payment"})
+		return
 	}
 
-	// Update the payment record based on processing result
-	if status == "Success" {
-		payment.Status = "Success"
-		payment.TransactionID = transactionID
-	} else {
-		payment.Status = "Failed"
-		payment.ErrorMessage = errorMessage
+	// Progress reports and repeats leave the payment as it is
+	if status != "Pending" && status != payment.Status {
+		if !payment.CanMoveTo(status) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: fmt.Sprintf("Payment is %s and cannot become %s", payment.Status, status)})
+			return
+		}
+		payment.Status = status
+		if status == "Failed" {
+			payment.ErrorMessage = fmt.Sprintf("%v: %s", gateway.ErrDeclined, result.Message)
+		}
+		if err := h.payments.UpdatePayment(*payment); err != nil {
+			switch {
+			case errors.Is(err, repository.ErrPaymentTransition):
+				// Another callback or the charge settled it first
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Payment was settled differently"})
+			case errors.Is(err, repository.ErrInsufficientFunds):
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "The deposit was already spent and cannot be refunded"})
+			default:
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update payment"})
+			}
+			return
+		}
+		h.updates.notify(payment.ID)
 	}
 
-	repository.UpdatePayment(*payment)
+	c.JSON(http.StatusOK, PaymentResponse{
+		ID:            payment.ID,
+		Status:        payment.Status,
+		TransactionID: payment.TransactionID,
+		ErrorMessage:  payment.ErrorMessage,
+	})
 }
 
-// Simulated payment processing functions
+// callbackProvider finds the enabled payment method a callback path names,
+// case-insensitively, along with its callback secret. Methods without a
+// secret take no callbacks.
+func (h *PaymentHandler) callbackProvider(provider string) (string, string, bool) {
+	for _, method := range h.cfg.Methods {
+		if strings.EqualFold(method, provider) {
+			secret := h.cfg.Gateways[method].CallbackSecret
+			return method, secret, secret != ""
+		}
+	}
+	return "", "", false
+}
 
-func processCreditCardPayment(payment *models.Payment) (string, string, string) {
-	// Simulate processing delay
-	time.Sleep(2 * time.Second)
+// awaitPayment returns the payment once it leaves Pending, or as it stands
+// once wait is over or the client went away.
+func (h *PaymentHandler) awaitPayment(ctx context.Context, id uint, wait time.Duration) (*models.Payment, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		// Watch for updates before reading, so none is missed in between
+		updated, done := h.updates.watch(id)
+		payment, err := h.payments.GetPaymentByID(id)
+		remaining := time.Until(deadline)
+		if err != nil || payment.Status != "Pending" || remaining <= 0 {
+			done()
+			return payment, err
+		}
+		select {
+		case <-updated:
+		case <-time.After(min(remaining, paymentPollInterval)):
+		case <-ctx.Done():
+		}
+		done()
+		if ctx.Err() != nil {
+			return payment, nil
+		}
+	}
+}
 
-	// Simulate success
-	return "CC1234567890", "Success", ""
+// parseWait reads the wait query parameter, a duration or a number of
+// seconds, capped at limit.
+func parseWait(s string, limit time.Duration) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(s)
+	if err != nil {
+		seconds, convErr := strconv.ParseUint(s, 10, 32)
+		if convErr != nil {
+			return 0, err
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	if wait < 0 {
+		return 0, errors.New("wait must not be negative")
+	}
+	return min(wait, limit), nil
 }
 
-func processBankTransfer(payment *models.Payment) (string, string, string) {
-	// Simulate processing delay
-	time.Sleep(3 * time.Second)
+// parseUint converts a string to uint, handling errors.
+func parseUint(s string) (uint, error) {
+	i, err := strconv.ParseUint(s, 10, 0)
+	return uint(i), err
+}
 
-	// Simulate failure
-	return "", "Failed", "Insufficient funds"
+// paymentUpdates wakes the long-polls waiting on a payment when it is
+// updated. Each payment being waited on has one channel, closed and
+// replaced on update.
+type paymentUpdates struct {
+	mu       sync.Mutex
+	channels map[uint]*paymentWatch
 }
 
-func processThirdPartyPayment(payment *models.Payment) (string, string, string) {
-	// Simulate processing delay
-	time.Sleep(1 * time.Second)
+type paymentWatch struct {
+	updated  chan struct{}
+	watchers int
+}
 
-	// Simulate success
-	return "TP0987654321", "Success", ""
+func newPaymentUpdates() *paymentUpdates {
+	return &paymentUpdates{channels: map[uint]*paymentWatch{}}
 }
 
-func processBlockchainPayment(payment *models.Payment) (string, string, string) {
-	// Simulate processing delay
-	time.Sleep(4 * time.Second)
+// watch returns a channel closed on the payment's next update, and a func
+// to call once no longer watching.
+func (u *paymentUpdates) watch(id uint) (<-chan struct{}, func()) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	w, ok := u.channels[id]
+	if !ok {
+		w = &paymentWatch{updated: make(chan struct{})}
+		u.channels[id] = w
+	}
+	w.watchers++
+	return w.updated, func() {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		w.watchers--
+		if w.watchers == 0 && u.channels[id] == w {
+			delete(u.channels, id)
+		}
+	}
+}
 
-	// Simulate success
-	return "BC5678901234", "Success", ""
+// notify wakes everyone watching the payment.
+func (u *paymentUpdates) notify(id uint) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if w, ok := u.channels[id]; ok {
+		close(w.updated)
+		delete(u.channels, id)
+	}
 }
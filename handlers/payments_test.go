@@ -0,0 +1,354 @@
+// handlers/payments_test.go
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/gateway"
+	"interview_YangYang_20241010/jobs"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPaymentRouter serves the payment endpoints with a stub provider behind
+// every enabled method, and charges accepted payments in the background.
+func newPaymentRouter(t *testing.T, cfg config.PaymentConfig) (*gin.Engine, *fakePaymentStore, map[string]*gateway.Stub) {
+	t.Helper()
+	payments := newFakePaymentStore()
+	gateways := gateway.NewRegistry()
+	stubs := map[string]*gateway.Stub{}
+	for _, method := range cfg.Methods {
+		stubs[method] = gateway.NewStub("")
+		srv := httptest.NewServer(stubs[method])
+		t.Cleanup(srv.Close)
+		gateways.Register(method, gateway.NewHTTPGateway(config.GatewayConfig{URL: srv.URL, Timeout: 200 * time.Millisecond}))
+	}
+	h := NewPaymentHandler(payments, cfg)
+	cfg.ProcessInterval = 10 * time.Millisecond
+	ctx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+	go jobs.NewPaymentProcessor(payments, gateways, cfg).Run(ctx)
+	idempotency := NewIdempotency(newFakeIdempotencyStore(), config.Default().Idempotency)
+
+	r := gin.New()
+	r.POST("/payments", idempotency.Middleware(), h.ProcessPayment)
+	r.GET("/payments/:id", h.GetPaymentDetails)
+	r.POST("/payments/callbacks/:provider", h.HandleProviderCallback)
+	return r, payments, stubs
+}
+
+// awaitPayment waits for a payment to leave Pending and returns it.
+func awaitPayment(t *testing.T, payments *fakePaymentStore, id uint) models.Payment {
+	t.Helper()
+	var payment models.Payment
+	assert.Eventually(t, func() bool {
+		p, err := payments.GetPaymentByID(id)
+		if err != nil {
+			return false
+		}
+		payment = *p
+		return payment.Status != "Pending"
+	}, 2*time.Second, 10*time.Millisecond)
+	return payment
+}
+
+func TestProcessPaymentHandlerValidatesInput(t *testing.T) {
+	cfg := config.Default().Payment
+	cfg.Methods = []string{"CreditCard"}
+	cfg.MaxAmount = 10000
+	r, payments, _ := newPaymentRouter(t, cfg)
+
+	details := json.RawMessage(`{"card_number":"4111111111111111"}`)
+
+	w := performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: "Blockchain", Amount: 1000, Details: details})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: "CreditCard", Amount: 50000, Details: details})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Empty(t, payments.payments)
+}
+
+func TestGetPaymentDetailsHandler(t *testing.T) {
+	r, payments, _ := newPaymentRouter(t, config.Default().Payment)
+	id, _ := payments.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Success"})
+
+	w := performRequest(r, http.MethodGet, "/payments/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var payment models.Payment
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payment))
+	assert.Equal(t, id, payment.ID)
+	assert.Equal(t, "Success", payment.Status)
+
+	w = performRequest(r, http.MethodGet, "/payments/99", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performRequest(r, http.MethodGet, "/payments/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProcessPaymentChargesThroughTheGateway(t *testing.T) {
+	r, payments, stubs := newPaymentRouter(t, config.Default().Payment)
+
+	w := performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: "CreditCard", Amount: 1234, Details: json.RawMessage(`{"card_number":"4111111111111111"}`)})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/payments/1", w.Header().Get("Location"))
+	var resp PaymentResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, PaymentResponse{ID: 1, Status: "Pending"}, resp)
+
+	payment := awaitPayment(t, payments, 1)
+	assert.Equal(t, "Success", payment.Status)
+	assert.Empty(t, payment.ErrorMessage)
+	tx, ok := stubs["CreditCard"].Transaction(payment.TransactionID)
+	if assert.True(t, ok) {
+		assert.Equal(t, gateway.StatusCaptured, tx.Status)
+		assert.Equal(t, int64(1234), tx.Amount)
+		assert.Equal(t, "1", tx.Reference)
+	}
+	// Only the provider of the method was charged
+	assert.Zero(t, stubs["BankTransfer"].Calls(gateway.OpAuthorize))
+}
+
+func TestProcessPaymentRecordsGatewayFailures(t *testing.T) {
+	r, payments, stubs := newPaymentRouter(t, config.Default().Payment)
+	stubs["BankTransfer"].Script(gateway.OpAuthorize, gateway.Behavior{Outcome: gateway.OutcomeDecline, Message: "Insufficient funds"})
+	stubs["ThirdParty"].Script(gateway.OpCapture, gateway.Behavior{Outcome: gateway.OutcomeFail, Message: "maintenance"})
+	stubs["Blockchain"].Script(gateway.OpAuthorize, gateway.Behavior{Latency: time.Second})
+
+	for _, method := range []string{"BankTransfer", "ThirdParty", "Blockchain"} {
+		w := performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: method, Amount: 1000, Details: json.RawMessage(`{}`)})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	}
+
+	declined := awaitPayment(t, payments, 1)
+	assert.Equal(t, "Failed", declined.Status)
+	assert.Contains(t, declined.ErrorMessage, "Insufficient funds")
+	assert.Empty(t, declined.TransactionID)
+
+	// The authorization is kept for reconciliation when the capture fails
+	uncaptured := awaitPayment(t, payments, 2)
+	assert.Equal(t, "Failed", uncaptured.Status)
+	assert.Equal(t, "Payment provider unavailable", uncaptured.ErrorMessage)
+	tx, ok := stubs["ThirdParty"].Transaction(uncaptured.TransactionID)
+	if assert.True(t, ok) {
+		assert.Equal(t, gateway.StatusAuthorized, tx.Status)
+	}
+
+	// A provider slower than the timeout counts as unavailable
+	timedOut := awaitPayment(t, payments, 3)
+	assert.Equal(t, "Failed", timedOut.Status)
+	assert.Equal(t, "Payment provider unavailable", timedOut.ErrorMessage)
+}
+
+func TestGetPaymentDetailsLongPolls(t *testing.T) {
+	cfg := callbackConfig("CreditCard")
+	r, payments, stubs := newPaymentRouter(t, cfg)
+	stubs["CreditCard"].Script(gateway.OpAuthorize, gateway.Behavior{Latency: 100 * time.Millisecond})
+
+	w := performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: "CreditCard", Amount: 1000, Details: json.RawMessage(`{}`)})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// Without wait the payment is returned as it stands
+	w = performRequest(r, http.MethodGet, w.Header().Get("Location"), nil)
+	var payment models.Payment
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payment))
+	assert.Equal(t, "Pending", payment.Status)
+
+	// With it the request returns once a reread finds the payment charged
+	started := time.Now()
+	w = performRequest(r, http.MethodGet, "/payments/1?wait=30s", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payment))
+	assert.Equal(t, "Success", payment.Status)
+	assert.Less(t, time.Since(started), 2*paymentPollInterval)
+
+	// Payments that stay pending are returned once the wait is over
+	id, _ := payments.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Pending", TransactionID: "tx_waiting"})
+	started = time.Now()
+	w = performRequest(r, http.MethodGet, fmt.Sprintf("/payments/%d?wait=50ms", id), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payment))
+	assert.Equal(t, "Pending", payment.Status)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+
+	// A callback settling the payment answers the wait right away
+	settled := make(chan models.Payment)
+	go func() {
+		var p models.Payment
+		w := performRequest(r, http.MethodGet, fmt.Sprintf("/payments/%d?wait=30s", id), nil)
+		json.Unmarshal(w.Body.Bytes(), &p)
+		settled <- p
+	}()
+	time.Sleep(20 * time.Millisecond)
+	started = time.Now()
+	w = sendCallback(r, "creditcard", "cb_CreditCard", time.Now(), gateway.Result{TransactionID: "tx_waiting", Status: gateway.StatusCaptured})
+	assert.Equal(t, http.StatusOK, w.Code)
+	payment = <-settled
+	assert.Equal(t, "Success", payment.Status)
+	assert.Less(t, time.Since(started), paymentPollInterval)
+
+	w = performRequest(r, http.MethodGet, "/payments/1?wait=soon", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performRequest(r, http.MethodGet, "/payments/99?wait=1s", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestParseWait(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"30s", 30 * time.Second},
+		{"15", 15 * time.Second},
+		{"1h", time.Minute},
+	} {
+		wait, err := parseWait(tc.in, time.Minute)
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, wait, tc.in)
+	}
+	for _, in := range []string{"-1s", "soon", "-5"} {
+		_, err := parseWait(in, time.Minute)
+		assert.Error(t, err, in)
+	}
+}
+
+// sendCallback posts a provider callback signed with secret at t.
+func sendCallback(r http.Handler, provider, secret string, t time.Time, result gateway.Result) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(result)
+	req := httptest.NewRequest(http.MethodPost, "/payments/callbacks/"+provider, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(gateway.CallbackSignatureHeader, webhook.Sign(secret, t, body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// callbackConfig enables the given methods with a callback secret each.
+func callbackConfig(methods ...string) config.PaymentConfig {
+	cfg := config.Default().Payment
+	cfg.Methods = methods
+	for _, method := range methods {
+		gc := cfg.Gateways[method]
+		gc.CallbackSecret = "cb_" + method
+		cfg.Gateways[method] = gc
+	}
+	return cfg
+}
+
+func TestProviderCallbacksSettlePendingPayments(t *testing.T) {
+	r, payments, stubs := newPaymentRouter(t, callbackConfig("BankTransfer", "Blockchain"))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	bank, chain := stubs["BankTransfer"], stubs["Blockchain"]
+	bank.Script(gateway.OpCapture, gateway.Behavior{Outcome: gateway.OutcomePending})
+	bank.SetCallback(srv.URL+"/payments/callbacks/banktransfer", "cb_BankTransfer")
+	chain.Script(gateway.OpAuthorize, gateway.Behavior{Outcome: gateway.OutcomePending})
+	chain.SetCallback(srv.URL+"/payments/callbacks/blockchain", "cb_Blockchain")
+
+	for _, method := range []string{"BankTransfer", "Blockchain"} {
+		w := performRequest(r, http.MethodPost, "/payments", PaymentRequest{PlayerID: 1, Method: method, Amount: 1000, Details: json.RawMessage(`{}`)})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	}
+
+	// The payments wait for their providers with the transactions recorded
+	var pending []models.Payment
+	for id := uint(1); id <= 2; id++ {
+		var payment models.Payment
+		assert.Eventually(t, func() bool {
+			p, _ := payments.GetPaymentByID(id)
+			payment = *p
+			return p.TransactionID != ""
+		}, 2*time.Second, 10*time.Millisecond)
+		pending = append(pending, payment)
+	}
+	assert.Eventually(t, func() bool { return bank.Calls(gateway.OpCapture) == 1 }, 2*time.Second, 10*time.Millisecond)
+	for id := uint(1); id <= 2; id++ {
+		payment, _ := payments.GetPaymentByID(id)
+		assert.Equal(t, "Pending", payment.Status)
+	}
+	assert.Zero(t, chain.Calls(gateway.OpCapture))
+
+	_, err := bank.Settle(pending[0].TransactionID, gateway.StatusCaptured)
+	assert.NoError(t, err)
+	_, err = chain.Settle(pending[1].TransactionID, gateway.StatusDeclined)
+	assert.NoError(t, err)
+
+	settled, _ := payments.GetPaymentByID(1)
+	assert.Equal(t, "Success", settled.Status)
+	declined, _ := payments.GetPaymentByID(2)
+	assert.Equal(t, "Failed", declined.Status)
+	assert.NotEmpty(t, declined.ErrorMessage)
+
+	// A repeated callback is acknowledged without effect
+	w := sendCallback(r, "BankTransfer", "cb_BankTransfer", time.Now(), gateway.Result{
+		TransactionID: pending[0].TransactionID, Reference: "1", Status: gateway.StatusCaptured, Amount: 1000,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp PaymentResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, PaymentResponse{ID: 1, Status: "Success", TransactionID: pending[0].TransactionID}, resp)
+
+	// A failed payment cannot succeed later
+	w = sendCallback(r, "blockchain", "cb_Blockchain", time.Now(), gateway.Result{
+		TransactionID: pending[1].TransactionID, Status: gateway.StatusCaptured,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	declined, _ = payments.GetPaymentByID(2)
+	assert.Equal(t, "Failed", declined.Status)
+}
+
+func TestProviderCallbacksAreVerified(t *testing.T) {
+	cfg := callbackConfig("BankTransfer", "CreditCard")
+	cfg.Gateways["CreditCard"] = config.GatewayConfig{}
+	r, payments, _ := newPaymentRouter(t, cfg)
+	id, _ := payments.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 1000, TransactionID: "tx_1"})
+	captured := gateway.Result{TransactionID: "tx_1", Reference: fmt.Sprint(id), Status: gateway.StatusCaptured, Amount: 1000}
+	now := time.Now()
+
+	cases := map[string]struct {
+		provider, secret string
+		at               time.Time
+		result           gateway.Result
+		code             int
+	}{
+		"wrong secret":        {"banktransfer", "guess", now, captured, http.StatusUnauthorized},
+		"stale signature":     {"banktransfer", "cb_BankTransfer", now.Add(-time.Hour), captured, http.StatusUnauthorized},
+		"unknown provider":    {"paypal", "cb_BankTransfer", now, captured, http.StatusNotFound},
+		"no callback secret":  {"creditcard", "", now, captured, http.StatusNotFound},
+		"unknown transaction": {"banktransfer", "cb_BankTransfer", now, gateway.Result{TransactionID: "tx_2", Status: gateway.StatusCaptured}, http.StatusNotFound},
+		"other provider's":    {"banktransfer", "cb_BankTransfer", now, gateway.Result{Status: gateway.StatusCaptured}, http.StatusBadRequest},
+		"unknown status":      {"banktransfer", "cb_BankTransfer", now, gateway.Result{TransactionID: "tx_1", Status: "settled"}, http.StatusBadRequest},
+		"wrong reference":     {"banktransfer", "cb_BankTransfer", now, gateway.Result{TransactionID: "tx_1", Reference: "9", Status: gateway.StatusCaptured}, http.StatusUnprocessableEntity},
+		"wrong amount":        {"banktransfer", "cb_BankTransfer", now, gateway.Result{TransactionID: "tx_1", Status: gateway.StatusCaptured, Amount: 1}, http.StatusUnprocessableEntity},
+	}
+	for name, tc := range cases {
+		w := sendCallback(r, tc.provider, tc.secret, tc.at, tc.result)
+		assert.Equal(t, tc.code, w.Code, name)
+	}
+	payment, _ := payments.GetPaymentByID(id)
+	assert.Equal(t, "Pending", payment.Status)
+
+	// Progress reports leave the payment pending
+	w := sendCallback(r, "banktransfer", "cb_BankTransfer", now, gateway.Result{TransactionID: "tx_1", Status: gateway.StatusAuthorized})
+	assert.Equal(t, http.StatusOK, w.Code)
+	payment, _ = payments.GetPaymentByID(id)
+	assert.Equal(t, "Pending", payment.Status)
+
+	w = sendCallback(r, "banktransfer", "cb_BankTransfer", now, captured)
+	assert.Equal(t, http.StatusOK, w.Code)
+	payment, _ = payments.GetPaymentByID(id)
+	assert.Equal(t, "Success", payment.Status)
+}
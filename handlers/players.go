@@ -8,6 +8,17 @@ import (
     "interview_YangYang_20241010/repository"
 )
 
+// PlayerHandler serves the player endpoints.
+type PlayerHandler struct {
+    players repository.PlayerStore
+    levels  repository.LevelStore
+}
+
+// NewPlayerHandler creates a PlayerHandler backed by the given stores.
+func NewPlayerHandler(players repository.PlayerStore, levels repository.LevelStore) *PlayerHandler {
+    return &PlayerHandler{players: players, levels: levels}
+}
+
 // @Summary Get all players
 // @Description Retrieve a list of all players with their level information
 // @Tags players
@@ -16,8 +27,8 @@ import (
 // @Success 200 {array} models.Player "A list of players"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /players [get]
-func GetPlayers(c *gin.Context) {
-    players, err := repository.GetAllPlayers()
+func (h *PlayerHandler) GetPlayers(c *gin.Context) {
+    players, err := h.players.GetAllPlayers()
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -35,7 +46,7 @@ func GetPlayers(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /players [post]
-func CreatePlayer(c *gin.Context) {
+func (h *PlayerHandler) CreatePlayer(c *gin.Context) {
     var player models.Player
     if err := c.ShouldBindJSON(&player); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
@@ -43,12 +54,12 @@ func CreatePlayer(c *gin.Context) {
     }
 
     // Validate that the level exists
-    if _, err := repository.GetLevelByID(player.LevelID); err != nil {
+    if _, err := h.levels.GetLevelByID(player.LevelID); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid level ID"})
         return
     }
 
-    id, err := repository.CreatePlayer(player)
+    id, err := h.players.CreatePlayer(player)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -66,9 +77,9 @@ func CreatePlayer(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse "Player not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /players/{id} [get]
-func GetPlayerByID(c *gin.Context) {
+func (h *PlayerHandler) GetPlayerByID(c *gin.Context) {
     id := c.Param("id")
-    player, err := repository.GetPlayerByID(id)
+    player, err := h.players.GetPlayerByID(id)
     if err != nil {
         c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
         return
@@ -87,7 +98,7 @@ func GetPlayerByID(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /players/{id} [put]
-func UpdatePlayer(c *gin.Context) {
+func (h *PlayerHandler) UpdatePlayer(c *gin.Context) {
     id := c.Param("id")
     var player models.Player
     if err := c.ShouldBindJSON(&player); err != nil {
@@ -96,12 +107,12 @@ func UpdatePlayer(c *gin.Context) {
     }
 
     // Validate that the level exists
-    if _, err := repository.GetLevelByID(player.LevelID); err != nil {
+    if _, err := h.levels.GetLevelByID(player.LevelID); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid level ID"})
         return
     }
 
-    err := repository.UpdatePlayer(id, player)
+    err := h.players.UpdatePlayer(id, player)
     if err != nil {
         if err == repository.ErrPlayerNotFound {
             c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
@@ -122,9 +133,9 @@ func UpdatePlayer(c *gin.Context) {
 // @Success 200 {object} models.SuccessResponse "Deletion status"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /players/{id} [delete]
-func DeletePlayer(c *gin.Context) {
+func (h *PlayerHandler) DeletePlayer(c *gin.Context) {
     id := c.Param("id")
-    err := repository.DeletePlayer(id)
+    err := h.players.DeletePlayer(id)
     if err != nil {
         if err == repository.ErrPlayerNotFound {
             c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
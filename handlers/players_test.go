@@ -0,0 +1,76 @@
+// handlers/players_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPlayerRouter() (*gin.Engine, *fakePlayerStore) {
+	players := newFakePlayerStore()
+	h := NewPlayerHandler(players, newFakeLevelStore(models.Level{ID: "1", Name: "Beginner"}))
+
+	r := gin.New()
+	r.GET("/players", h.GetPlayers)
+	r.POST("/players", h.CreatePlayer)
+	r.GET("/players/:id", h.GetPlayerByID)
+	r.PUT("/players/:id", h.UpdatePlayer)
+	r.DELETE("/players/:id", h.DeletePlayer)
+	return r, players
+}
+
+func TestCreatePlayerHandler(t *testing.T) {
+	r, players := newPlayerRouter()
+
+	w := performRequest(r, http.MethodPost, "/players", models.Player{Name: "Alice", LevelID: "1"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Alice", players.players[resp["id"]].Name)
+}
+
+func TestCreatePlayerHandlerRejectsUnknownLevel(t *testing.T) {
+	r, players := newPlayerRouter()
+
+	w := performRequest(r, http.MethodPost, "/players", models.Player{Name: "Alice", LevelID: "42"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, players.players)
+}
+
+func TestGetPlayerByIDHandler(t *testing.T) {
+	r, players := newPlayerRouter()
+	id, _ := players.CreatePlayer(models.Player{Name: "Bob", LevelID: "1"})
+
+	w := performRequest(r, http.MethodGet, "/players/"+id, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var player models.Player
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &player))
+	assert.Equal(t, "Bob", player.Name)
+
+	w = performRequest(r, http.MethodGet, "/players/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateAndDeletePlayerHandler(t *testing.T) {
+	r, players := newPlayerRouter()
+	id, _ := players.CreatePlayer(models.Player{Name: "Carol", LevelID: "1"})
+
+	w := performRequest(r, http.MethodPut, "/players/"+id, models.Player{Name: "Caroline", LevelID: "1"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Caroline", players.players[id].Name)
+
+	w = performRequest(r, http.MethodDelete, "/players/"+id, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, players.players)
+
+	w = performRequest(r, http.MethodDelete, "/players/"+id, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,35 +2,68 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "strconv"
     "time"
 
     "github.com/gin-gonic/gin"
+    "interview_YangYang_20241010/config"
     "interview_YangYang_20241010/models"
     "interview_YangYang_20241010/repository"
 )
 
+// ReservationHandler serves the reservation endpoints.
+type ReservationHandler struct {
+    reservations repository.ReservationStore
+    rooms        repository.RoomStore
+    cfg          config.ReservationConfig
+}
+
+// NewReservationHandler creates a ReservationHandler backed by the given
+// stores and reservation policies.
+func NewReservationHandler(reservations repository.ReservationStore, rooms repository.RoomStore, cfg config.ReservationConfig) *ReservationHandler {
+    return &ReservationHandler{reservations: reservations, rooms: rooms, cfg: cfg}
+}
+
 // ReservationInput represents the expected input for creating a reservation
 type ReservationInput struct {
-    RoomID     uint      `json:"room_id" binding:"required"`
-    Date       string    `json:"date" binding:"required"`       // Expected format: "YYYY-MM-DD"
-    Time       string    `json:"time" binding:"required"`       // Expected format: "HH:MM-HH:MM"
-    PlayerInfo string    `json:"player_info" binding:"required"` // JSON or string
+    RoomID   uint      `json:"room_id" binding:"required"`
+    StartAt  time.Time `json:"start_at" binding:"required"` // RFC 3339, e.g. "2024-10-10T14:00:00+08:00"
+    EndAt    time.Time `json:"end_at" binding:"required"`   // RFC 3339, must be after start_at
+    TimeZone string    `json:"time_zone"`                   // IANA zone such as "Asia/Taipei", defaults to UTC
+    HostID   string    `json:"host_id" binding:"required"`  // Registered player making the booking
+    GuestIDs []string  `json:"guest_ids"`                   // Registered players invited by the host
+    Note     string    `json:"note"`
+}
+
+// ReservationUpdate represents the expected input for changing a reservation
+type ReservationUpdate struct {
+    StartAt  *time.Time `json:"start_at"`  // RFC 3339; keeps the current start when omitted
+    EndAt    *time.Time `json:"end_at"`    // RFC 3339, must be after the start; keeps the current end when omitted
+    TimeZone string     `json:"time_zone"` // Keeps the current zone when empty
+    GuestIDs []string   `json:"guest_ids"` // Replaces the guests when given; [] removes them all
+    Note     string     `json:"note"`      // Keeps the current note when empty
+}
+
+// ReservationConflictResponse is returned when a slot overlaps an existing reservation.
+type ReservationConflictResponse struct {
+    Error    string             `json:"error"`
+    Conflict models.Reservation `json:"conflict"`
 }
 
 // @Summary Get reservations
-// @Description Retrieve reservations with optional filters for room ID, date, and limit
+// @Description Retrieve reservations with their room, host and guests, with optional filters for room ID, date, and limit
 // @Tags reservations
 // @Accept json
 // @Produce json
 // @Param room_id query uint false "Room ID to filter reservations"
-// @Param date query string false "Date to filter reservations (YYYY-MM-DD)"
+// @Param date query string false "Only reservations overlapping this UTC day (YYYY-MM-DD)"
 // @Param limit query int false "Maximum number of reservations to return"
 // @Success 200 {array} models.Reservation "A list of reservations"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /reservations [get]
-func GetReservations(c *gin.Context) {
+func (h *ReservationHandler) GetReservations(c *gin.Context) {
     roomIDStr := c.Query("room_id")
     dateStr := c.Query("date")
     limitStr := c.Query("limit")
@@ -65,7 +98,7 @@ func GetReservations(c *gin.Context) {
         }
     }
 
-    reservations, err := repository.GetReservations(roomID, date, limit)
+    reservations, err := h.reservations.GetReservations(roomID, date, limit)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -74,34 +107,38 @@ func GetReservations(c *gin.Context) {
 }
 
 // @Summary Create a new reservation
-// @Description Add a new reservation for a game room
+// @Description Book a room from start_at up to (but not including) end_at for a host player and their invited guests. Slots that start in the past or overlap another reservation of the same room are rejected.
 // @Tags reservations
 // @Accept json
 // @Produce json
 // @Param reservation body ReservationInput true "Reservation Information"
 // @Success 201 {object} map[string]uint "Successfully created reservation ID"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 409 {object} ReservationConflictResponse "Slot overlaps an existing reservation"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /reservations [post]
-func CreateReservation(c *gin.Context) {
+func (h *ReservationHandler) CreateReservation(c *gin.Context) {
     var input ReservationInput
     if err := c.ShouldBindJSON(&input); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
         return
     }
 
-    // Validate date format
-    date, err := time.Parse("2006-01-02", input.Date)
-    if err != nil {
-        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid date format. Expected YYYY-MM-DD"})
+    // Validate the slot
+    if !input.EndAt.After(input.StartAt) {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "end_at must be after start_at"})
+        return
+    }
+    if input.TimeZone == "" {
+        input.TimeZone = "UTC"
+    }
+    if _, err := time.LoadLocation(input.TimeZone); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid time zone " + input.TimeZone})
         return
     }
-
-    // Optional: Validate time format (e.g., "14:00-16:00")
-    // You can implement proper time validation here
 
     // Check if the room exists
-    if _, err := repository.GetRoomByID(input.RoomID); err != nil {
+    if _, err := h.rooms.GetRoomByID(input.RoomID); err != nil {
         if err == repository.ErrRoomNotFound {
             c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
         } else {
@@ -110,19 +147,242 @@ func CreateReservation(c *gin.Context) {
         return
     }
 
-    // Optionally, you can check for overlapping reservations here
+    guests, err := guestList(input.HostID, input.GuestIDs)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+        return
+    }
 
     reservation := models.Reservation{
-        RoomID:     input.RoomID,
-        Date:       date,
-        Time:       input.Time,
-        PlayerInfo: input.PlayerInfo,
+        RoomID:   input.RoomID,
+        HostID:   input.HostID,
+        Guests:   guests,
+        StartAt:  input.StartAt,
+        EndAt:    input.EndAt,
+        TimeZone: input.TimeZone,
+        Note:     input.Note,
     }
 
-    id, err := repository.CreateReservation(reservation)
+    id, err := h.reservations.CreateReservation(reservation, time.Now())
     if err != nil {
-        c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        var conflict *repository.ReservationConflictError
+        switch {
+        case errors.As(err, &conflict):
+            c.JSON(http.StatusConflict, ReservationConflictResponse{Error: err.Error(), Conflict: conflict.Existing})
+        case errors.Is(err, repository.ErrReservationConflict):
+            c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+        case errors.Is(err, repository.ErrInvalidSlot), errors.Is(err, repository.ErrSlotTooSoon), errors.Is(err, repository.ErrPlayerNotFound):
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        }
         return
     }
     c.JSON(http.StatusCreated, map[string]uint{"id": id})
-}
\ No newline at end of file
+}
+
+// @Summary Get a player's reservations
+// @Description Retrieve the reservations a player hosts or is invited to, ordered by start time
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Player ID"
+// @Success 200 {array} models.Reservation "The player's reservations"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/reservations [get]
+func (h *ReservationHandler) GetPlayerReservations(c *gin.Context) {
+    reservations, err := h.reservations.GetPlayerReservations(c.Param("id"))
+    if err != nil {
+        if errors.Is(err, repository.ErrPlayerNotFound) {
+            c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
+        } else {
+            c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+        }
+        return
+    }
+    c.JSON(http.StatusOK, reservations)
+}
+
+// @Summary Get reservation details by ID
+// @Description Retrieve a reservation with its room, host and guests, including its status: booked, checked_in, cancelled, no_show or completed
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Success 200 {object} models.Reservation "Reservation details"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Reservation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /reservations/{id} [get]
+func (h *ReservationHandler) GetReservationByID(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid reservation ID"})
+        return
+    }
+
+    reservation, err := h.reservations.GetReservationByID(id)
+    if err != nil {
+        c.JSON(reservationErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, reservation)
+}
+
+// @Summary Update a reservation
+// @Description Move a booked reservation to a new slot or change its details; omitted fields keep their current value. Changes close once the reservation is within the cancellation window of its start, and it cannot be moved into that window.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Param reservation body ReservationUpdate true "Updated Reservation Information"
+// @Success 200 {object} models.Reservation "Updated reservation"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Reservation not found"
+// @Failure 409 {object} ReservationConflictResponse "Slot overlaps another reservation, or the reservation can no longer be changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /reservations/{id} [put]
+func (h *ReservationHandler) UpdateReservation(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid reservation ID"})
+        return
+    }
+
+    var input ReservationUpdate
+    if err := c.ShouldBindJSON(&input); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    if input.StartAt != nil && input.EndAt != nil && !input.EndAt.After(*input.StartAt) {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "end_at must be after start_at"})
+        return
+    }
+    if input.TimeZone != "" {
+        if _, err := time.LoadLocation(input.TimeZone); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid time zone " + input.TimeZone})
+            return
+        }
+    }
+
+    changes := models.Reservation{
+        TimeZone: input.TimeZone,
+        Note:     input.Note,
+    }
+    if input.StartAt != nil {
+        changes.StartAt = *input.StartAt
+    }
+    if input.EndAt != nil {
+        changes.EndAt = *input.EndAt
+    }
+    if input.GuestIDs != nil {
+        current, err := h.reservations.GetReservationByID(id)
+        if err != nil {
+            c.JSON(reservationErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+            return
+        }
+        if changes.Guests, err = guestList(current.HostID, input.GuestIDs); err != nil {
+            c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+            return
+        }
+    }
+    reservation, err := h.reservations.UpdateReservation(id, changes, time.Now().Add(h.cfg.CancellationWindow))
+    if err != nil {
+        var conflict *repository.ReservationConflictError
+        if errors.As(err, &conflict) {
+            c.JSON(http.StatusConflict, ReservationConflictResponse{Error: err.Error(), Conflict: conflict.Existing})
+            return
+        }
+        c.JSON(reservationErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, reservation)
+}
+
+// @Summary Cancel a reservation
+// @Description Cancel a booked reservation and release its slot. Cancellations close once the reservation is within the cancellation window of its start.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Success 200 {object} models.Reservation "Cancelled reservation"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Reservation not found"
+// @Failure 409 {object} models.ErrorResponse "Reservation can no longer be cancelled"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /reservations/{id} [delete]
+func (h *ReservationHandler) CancelReservation(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid reservation ID"})
+        return
+    }
+
+    reservation, err := h.reservations.CancelReservation(id, time.Now().Add(h.cfg.CancellationWindow))
+    if err != nil {
+        c.JSON(reservationErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, reservation)
+}
+
+// @Summary Check in to a reservation
+// @Description Mark a booked reservation as used. Check-in is open from the no-show grace period before the start until the same period after it; unclaimed reservations then become no_show.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path uint true "Reservation ID"
+// @Success 200 {object} models.Reservation "Checked-in reservation"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Reservation not found"
+// @Failure 409 {object} models.ErrorResponse "Reservation is not booked or check-in is closed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /reservations/{id}/check-in [post]
+func (h *ReservationHandler) CheckInReservation(c *gin.Context) {
+    id, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid reservation ID"})
+        return
+    }
+
+    reservation, err := h.reservations.CheckInReservation(id, time.Now(), h.cfg.NoShowGrace)
+    if err != nil {
+        c.JSON(reservationErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, reservation)
+}
+
+// reservationErrorStatus maps a reservation lifecycle error to its HTTP status code.
+func reservationErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, repository.ErrReservationNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, repository.ErrInvalidSlot), errors.Is(err, repository.ErrSlotTooSoon), errors.Is(err, repository.ErrPlayerNotFound):
+        return http.StatusBadRequest
+    case errors.Is(err, repository.ErrReservationConflict), errors.Is(err, repository.ErrReservationClosed),
+        errors.Is(err, repository.ErrCheckInClosed):
+        return http.StatusConflict
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+// guestList turns the invited player IDs into guests, dropping repeats. The
+// host cannot also be a guest.
+func guestList(hostID string, guestIDs []string) ([]models.Player, error) {
+    guests := make([]models.Player, 0, len(guestIDs))
+    seen := map[string]bool{}
+    for _, id := range guestIDs {
+        if id == hostID {
+            return nil, errors.New("The host cannot also be a guest")
+        }
+        if id == "" || seen[id] {
+            continue
+        }
+        seen[id] = true
+        guests = append(guests, models.Player{ID: id})
+    }
+    return guests, nil
+}
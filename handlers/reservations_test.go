@@ -0,0 +1,223 @@
+// handlers/reservations_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReservationRouter() (*gin.Engine, *fakeReservationStore) {
+	players := newFakePlayerStore()
+	for _, name := range []string{"Player 1", "Player 2", "Player 3"} {
+		players.CreatePlayer(models.Player{Name: name})
+	}
+	reservations := &fakeReservationStore{players: players}
+	rooms := newFakeRoomStore()
+	rooms.CreateRoom(models.Room{Name: "Room A"})
+	h := NewReservationHandler(reservations, rooms, config.Default().Reservation)
+
+	r := gin.New()
+	r.GET("/reservations", h.GetReservations)
+	r.POST("/reservations", h.CreateReservation)
+	r.GET("/reservations/:id", h.GetReservationByID)
+	r.PUT("/reservations/:id", h.UpdateReservation)
+	r.DELETE("/reservations/:id", h.CancelReservation)
+	r.POST("/reservations/:id/check-in", h.CheckInReservation)
+	r.GET("/players/:id/reservations", h.GetPlayerReservations)
+	return r, reservations
+}
+
+func reservationInput(start, end string) map[string]any {
+	return map[string]any{
+		"room_id":   1,
+		"start_at":  "2099-10-10T" + start + ":00+08:00",
+		"end_at":    "2099-10-10T" + end + ":00+08:00",
+		"time_zone": "Asia/Taipei",
+		"host_id":   "1",
+		"guest_ids": []string{"2"},
+	}
+}
+
+func TestCreateReservationHandler(t *testing.T) {
+	r, reservations := newReservationRouter()
+
+	w := performRequest(r, http.MethodPost, "/reservations", reservationInput("14:00", "16:00"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	if assert.Len(t, reservations.reservations, 1) {
+		assert.Equal(t, time.Date(2099, 10, 10, 6, 0, 0, 0, time.UTC), reservations.reservations[0].StartAt.UTC())
+	}
+
+	// The clash is named in the response
+	w = performRequest(r, http.MethodPost, "/reservations", reservationInput("15:00", "17:00"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict ReservationConflictResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, uint(1), conflict.Conflict.ID)
+	assert.Contains(t, conflict.Error, "reservation 1")
+
+	for _, input := range []map[string]any{
+		reservationInput("16:00", "16:00"),
+		reservationInput("17:00", "16:00"),
+		func() map[string]any {
+			in := reservationInput("18:00", "19:00")
+			in["time_zone"] = "Mars/Olympus"
+			return in
+		}(),
+		func() map[string]any { in := reservationInput("18:00", "19:00"); in["room_id"] = 9; return in }(),
+		func() map[string]any { in := reservationInput("18:00", "19:00"); in["start_at"] = "14:00"; return in }(),
+		func() map[string]any {
+			in := reservationInput("18:00", "19:00")
+			in["start_at"], in["end_at"] = "2024-10-10T18:00:00+08:00", "2024-10-10T19:00:00+08:00"
+			return in
+		}(),
+	} {
+		w = performRequest(r, http.MethodPost, "/reservations", input)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%v", input)
+	}
+	assert.Len(t, reservations.reservations, 1)
+}
+
+// bookAt stores a booked reservation of room 1 starting at start and lasting an hour.
+func bookAt(reservations *fakeReservationStore, start time.Time) uint {
+	id, _ := reservations.CreateReservation(models.Reservation{RoomID: 1, StartAt: start, EndAt: start.Add(time.Hour), HostID: "1"}, time.Now())
+	return id
+}
+
+func TestReservationLifecycleHandlers(t *testing.T) {
+	r, reservations := newReservationRouter()
+	now := time.Now().Truncate(time.Second)
+	later := bookAt(reservations, now.Add(24*time.Hour))
+	soon := bookAt(reservations, now.Add(time.Hour))
+	bookAt(reservations, now.Add(26*time.Hour))
+
+	w := performRequest(r, http.MethodGet, "/reservations/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.Reservation
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, models.ReservationStatusBooked, got.Status)
+	assert.Equal(t, http.StatusNotFound, performRequest(r, http.MethodGet, "/reservations/9", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, performRequest(r, http.MethodGet, "/reservations/x", nil).Code)
+
+	// Moving onto another booking names the clash
+	move := map[string]any{"start_at": now.Add(26 * time.Hour).Format(time.RFC3339), "end_at": now.Add(27 * time.Hour).Format(time.RFC3339)}
+	w = performRequest(r, http.MethodPut, "/reservations/1", move)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict ReservationConflictResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, uint(3), conflict.Conflict.ID)
+
+	move = map[string]any{"start_at": now.Add(48 * time.Hour).Format(time.RFC3339), "end_at": now.Add(49 * time.Hour).Format(time.RFC3339), "note": "Moved"}
+	w = performRequest(r, http.MethodPut, "/reservations/1", move)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Moved", reservations.reservations[later-1].Note)
+	assert.True(t, reservations.reservations[later-1].StartAt.Equal(now.Add(48*time.Hour)))
+
+	move["end_at"] = move["start_at"]
+	assert.Equal(t, http.StatusBadRequest, performRequest(r, http.MethodPut, "/reservations/1", move).Code)
+
+	// Nor can it be moved into the cancellation window
+	move = map[string]any{"start_at": now.Add(time.Hour).Format(time.RFC3339)}
+	assert.Equal(t, http.StatusBadRequest, performRequest(r, http.MethodPut, "/reservations/1", move).Code)
+
+	// Changing only the note keeps the slot
+	w = performRequest(r, http.MethodPut, "/reservations/1", map[string]any{"note": "Renamed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Renamed", reservations.reservations[later-1].Note)
+	assert.True(t, reservations.reservations[later-1].StartAt.Equal(now.Add(48*time.Hour)))
+	assert.True(t, reservations.reservations[later-1].EndAt.Equal(now.Add(49*time.Hour)))
+
+	// Inside the two hour cancellation window nothing can change
+	move = map[string]any{"start_at": now.Add(5 * time.Hour).Format(time.RFC3339), "end_at": now.Add(6 * time.Hour).Format(time.RFC3339)}
+	assert.Equal(t, http.StatusConflict, performRequest(r, http.MethodPut, "/reservations/2", move).Code)
+	assert.Equal(t, http.StatusConflict, performRequest(r, http.MethodDelete, "/reservations/2", nil).Code)
+
+	w = performRequest(r, http.MethodDelete, "/reservations/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ReservationStatusCancelled, reservations.reservations[later-1].Status)
+	assert.Equal(t, http.StatusConflict, performRequest(r, http.MethodDelete, "/reservations/1", nil).Code)
+
+	// Check-in opens 15 minutes before the start
+	assert.Equal(t, http.StatusConflict, performRequest(r, http.MethodPost, "/reservations/2/check-in", nil).Code)
+	reservations.reservations[soon-1].StartAt = now.Add(10 * time.Minute)
+	w = performRequest(r, http.MethodPost, "/reservations/2/check-in", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ReservationStatusCheckedIn, reservations.reservations[soon-1].Status)
+	assert.Equal(t, http.StatusConflict, performRequest(r, http.MethodPost, "/reservations/2/check-in", nil).Code)
+	assert.Equal(t, http.StatusNotFound, performRequest(r, http.MethodPost, "/reservations/9/check-in", nil).Code)
+}
+
+func TestReservationPlayersHandlers(t *testing.T) {
+	r, reservations := newReservationRouter()
+
+	w := performRequest(r, http.MethodPost, "/reservations", reservationInput("14:00", "16:00"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	if assert.Len(t, reservations.reservations, 1) {
+		assert.Equal(t, "1", reservations.reservations[0].HostID)
+		assert.Equal(t, []string{"1", "2"}, reservations.reservations[0].PlayerIDs())
+	}
+
+	for _, input := range []map[string]any{
+		func() map[string]any { in := reservationInput("18:00", "19:00"); delete(in, "host_id"); return in }(),
+		func() map[string]any { in := reservationInput("18:00", "19:00"); in["host_id"] = "9"; return in }(),
+		func() map[string]any {
+			in := reservationInput("18:00", "19:00")
+			in["guest_ids"] = []string{"9"}
+			return in
+		}(),
+		func() map[string]any {
+			in := reservationInput("18:00", "19:00")
+			in["guest_ids"] = []string{"1"}
+			return in
+		}(),
+	} {
+		w = performRequest(r, http.MethodPost, "/reservations", input)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%v", input)
+	}
+
+	// Repeated guests are invited once
+	in := reservationInput("18:00", "19:00")
+	in["guest_ids"] = []string{"2", "3", "2"}
+	assert.Equal(t, http.StatusCreated, performRequest(r, http.MethodPost, "/reservations", in).Code)
+	assert.Equal(t, []string{"1", "2", "3"}, reservations.reservations[1].PlayerIDs())
+
+	for playerID, want := range map[string]int{"1": 2, "2": 2, "3": 1} {
+		w = performRequest(r, http.MethodGet, "/players/"+playerID+"/reservations", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var got []models.Reservation
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Len(t, got, want, playerID)
+	}
+	assert.Equal(t, http.StatusNotFound, performRequest(r, http.MethodGet, "/players/9/reservations", nil).Code)
+}
+
+func TestUpdateReservationGuests(t *testing.T) {
+	r, reservations := newReservationRouter()
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	id := bookAt(reservations, start)
+	slot := map[string]any{"start_at": start.Format(time.RFC3339), "end_at": start.Add(time.Hour).Format(time.RFC3339)}
+
+	slot["guest_ids"] = []string{"2", "3"}
+	assert.Equal(t, http.StatusOK, performRequest(r, http.MethodPut, "/reservations/1", slot).Code)
+	assert.Equal(t, []string{"1", "2", "3"}, reservations.reservations[id-1].PlayerIDs())
+
+	// Leaving guest_ids out keeps the guests, an empty list removes them
+	delete(slot, "guest_ids")
+	assert.Equal(t, http.StatusOK, performRequest(r, http.MethodPut, "/reservations/1", slot).Code)
+	assert.Len(t, reservations.reservations[id-1].Guests, 2)
+	slot["guest_ids"] = []string{}
+	assert.Equal(t, http.StatusOK, performRequest(r, http.MethodPut, "/reservations/1", slot).Code)
+	assert.Empty(t, reservations.reservations[id-1].Guests)
+
+	for _, guests := range [][]string{{"1"}, {"9"}} {
+		slot["guest_ids"] = guests
+		assert.Equal(t, http.StatusBadRequest, performRequest(r, http.MethodPut, "/reservations/1", slot).Code, "%v", guests)
+	}
+}
@@ -0,0 +1,122 @@
+// handlers/room_socket.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/realtime"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// RoomSocketHandler streams room events over WebSocket and accepts moves
+// from connected players.
+type RoomSocketHandler struct {
+	rooms    repository.RoomStore
+	matches  repository.MatchStore
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewRoomSocketHandler creates a RoomSocketHandler that subscribes clients
+// to hub.
+func NewRoomSocketHandler(rooms repository.RoomStore, matches repository.MatchStore, hub *realtime.Hub) *RoomSocketHandler {
+	return &RoomSocketHandler{rooms: rooms, matches: matches, hub: hub}
+}
+
+// SocketMessage is a message sent by a client over the room WebSocket.
+type SocketMessage struct {
+	Type    string `json:"type"` // Only "move" is supported
+	MatchID uint   `json:"match_id"`
+	MoveRequest
+}
+
+// SocketError is sent back to a client whose message was rejected. Status
+// matches the code the REST endpoint would have returned.
+type SocketError struct {
+	Type   string `json:"type"` // Always "error"
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+// @Summary Follow a room in real time
+// @Description Upgrade to a WebSocket that receives room updates, players joining, leaving, connecting and disconnecting, and match moves.
+// @Description Send {"type":"move","match_id":1,"player_id":"...","row":0,"col":0} to play; rejected moves are answered with an error message.
+// @Tags rooms
+// @Param id path uint true "Room ID"
+// @Param player_id query string false "Player announced to the room while connected"
+// @Success 101 "Switching Protocols"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Room not found"
+// @Router /rooms/{id}/ws [get]
+func (h *RoomSocketHandler) ServeRoom(c *gin.Context) {
+	roomID, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
+		return
+	}
+	if _, err := h.rooms.GetRoomByID(roomID); err != nil {
+		if errors.Is(err, repository.ErrRoomNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Room not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response
+		log.Printf("WebSocket upgrade failed for room %d: %v", roomID, err)
+		return
+	}
+
+	client := h.hub.Subscribe(roomID)
+	playerID := c.Query("player_id")
+	if playerID != "" {
+		h.hub.Publish(realtime.Event{Type: realtime.EventPlayerConnected, RoomID: roomID, Data: gin.H{"player_id": playerID}})
+	}
+
+	h.hub.Serve(conn, client, func(msg []byte) any {
+		return h.handleMessage(roomID, msg)
+	})
+
+	if playerID != "" {
+		h.hub.Publish(realtime.Event{Type: realtime.EventPlayerDisconnected, RoomID: roomID, Data: gin.H{"player_id": playerID}})
+	}
+}
+
+// handleMessage applies a client message and returns the reply for the
+// sender, or nil when the outcome is broadcast to the whole room instead.
+func (h *RoomSocketHandler) handleMessage(roomID uint, msg []byte) any {
+	var m SocketMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return socketError(http.StatusBadRequest, "Invalid message: "+err.Error())
+	}
+	if m.Type != "move" {
+		return socketError(http.StatusBadRequest, "Unsupported message type "+m.Type)
+	}
+
+	// Only moves for matches in this room may be played here
+	match, err := h.matches.GetMatchByID(m.MatchID)
+	if err == nil && match.RoomID != roomID {
+		err = repository.ErrMatchNotFound
+	}
+	if err == nil {
+		_, err = playMove(h.matches, h.hub, m.MatchID, m.MoveRequest)
+	}
+	if err != nil {
+		return socketError(moveErrorStatus(err), err.Error())
+	}
+	return nil
+}
+
+func socketError(status int, msg string) SocketError {
+	return SocketError{Type: "error", Status: status, Error: msg}
+}
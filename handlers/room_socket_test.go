@@ -0,0 +1,109 @@
+// handlers/room_socket_test.go
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/realtime"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSocketServer(t *testing.T) (*httptest.Server, *fakeMatchStore) {
+	rooms := newFakeRoomStore()
+	rooms.CreateRoom(models.Room{Name: "Room A"})
+	rooms.CreateRoom(models.Room{Name: "Room B"})
+	matches := newFakeMatchStore()
+	g, _ := game.New(game.Rules{Size: 3, WinLength: 3})
+	match := models.Match{RoomID: 1, PlayerXID: "1", PlayerOID: "2"}
+	repository.ApplyGameState(&match, g)
+	matches.CreateMatch(match)
+
+	hub := realtime.NewHub(config.Default().Realtime)
+	h := NewRoomSocketHandler(rooms, matches, hub)
+	r := gin.New()
+	r.GET("/rooms/:id/ws", h.ServeRoom)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, matches
+}
+
+func dialRoom(t *testing.T, srv *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial %s: %v", path, err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRoomSocketRejectsUnknownRoom(t *testing.T) {
+	srv, _ := newSocketServer(t)
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/rooms/9/ws", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestRoomSocketMoves(t *testing.T) {
+	srv, matches := newSocketServer(t)
+	watcher := dialRoom(t, srv, "/rooms/1/ws")
+	player := dialRoom(t, srv, "/rooms/1/ws?player_id=1")
+
+	var event realtime.Event
+	for _, conn := range []*websocket.Conn{watcher, player} {
+		assert.NoError(t, conn.ReadJSON(&event))
+		assert.Equal(t, realtime.EventPlayerConnected, event.Type)
+	}
+
+	// Rejected moves are answered to the sender with the REST status code
+	assert.NoError(t, player.WriteJSON(SocketMessage{Type: "move", MatchID: 1, MoveRequest: move("2", 0, 0)}))
+	var reply SocketError
+	assert.NoError(t, player.ReadJSON(&reply))
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, http.StatusConflict, reply.Status)
+
+	assert.NoError(t, player.WriteJSON(map[string]any{"type": "chat"}))
+	assert.NoError(t, player.ReadJSON(&reply))
+	assert.Equal(t, http.StatusBadRequest, reply.Status)
+
+	// Accepted moves are broadcast to everyone in the room
+	assert.NoError(t, player.WriteJSON(SocketMessage{Type: "move", MatchID: 1, MoveRequest: move("1", 1, 1)}))
+	for _, conn := range []*websocket.Conn{watcher, player} {
+		assert.NoError(t, conn.ReadJSON(&event))
+		assert.Equal(t, realtime.EventMatchMove, event.Type)
+		assert.Equal(t, uint(1), event.RoomID)
+	}
+	m, _ := matches.GetMatchByID(1)
+	assert.Equal(t, "....X....", m.Board)
+
+	player.Close()
+	assert.NoError(t, watcher.ReadJSON(&event))
+	assert.Equal(t, realtime.EventPlayerDisconnected, event.Type)
+}
+
+func TestRoomSocketOnlyPlaysMatchesInTheRoom(t *testing.T) {
+	srv, matches := newSocketServer(t)
+	conn := dialRoom(t, srv, "/rooms/2/ws")
+
+	assert.NoError(t, conn.WriteJSON(SocketMessage{Type: "move", MatchID: 1, MoveRequest: move("1", 0, 0)}))
+	var reply SocketError
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, http.StatusNotFound, reply.Status)
+	m, _ := matches.GetMatchByID(1)
+	assert.Equal(t, ".........", m.Board)
+}
@@ -2,13 +2,29 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "interview_YangYang_20241010/availability"
     "interview_YangYang_20241010/models"
+    "interview_YangYang_20241010/realtime"
     "interview_YangYang_20241010/repository"
 )
 
+// RoomHandler serves the room endpoints.
+type RoomHandler struct {
+    rooms  repository.RoomStore
+    events realtime.Publisher
+}
+
+// NewRoomHandler creates a RoomHandler backed by the given stores. Room
+// changes are broadcast through events.
+func NewRoomHandler(rooms repository.RoomStore, events realtime.Publisher) *RoomHandler {
+    return &RoomHandler{rooms: rooms, events: events}
+}
+
 // @Summary Get all game rooms
 // @Description Retrieve a list of all game rooms with their details
 // @Tags rooms
@@ -17,8 +33,8 @@ import (
 // @Success 200 {array} models.Room "A list of game rooms"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /rooms [get]
-func GetRooms(c *gin.Context) {
-    rooms, err := repository.GetAllRooms()
+func (h *RoomHandler) GetRooms(c *gin.Context) {
+    rooms, err := h.rooms.GetAllRooms()
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -27,7 +43,7 @@ func GetRooms(c *gin.Context) {
 }
 
 // @Summary Create a new game room
-// @Description Add a new game room with specified name and description
+// @Description Add a new game room with specified name, description, capacity (default 2) and opening hours (default 00:00-24:00 UTC). New rooms are open unless created closed or under maintenance.
 // @Tags rooms
 // @Accept json
 // @Produce json
@@ -36,7 +52,7 @@ func GetRooms(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /rooms [post]
-func CreateRoom(c *gin.Context) {
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
     var room models.Room
     if err := c.ShouldBindJSON(&room); err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
@@ -48,8 +64,12 @@ func CreateRoom(c *gin.Context) {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Room name is required"})
         return
     }
+    if msg := validateRoomSettings(room); msg != "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: msg})
+        return
+    }
 
-    id, err := repository.CreateRoom(room)
+    id, err := h.rooms.CreateRoom(room)
     if err != nil {
         c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         return
@@ -67,14 +87,14 @@ func CreateRoom(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse "Room not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /rooms/{id} [get]
-func GetRoomByID(c *gin.Context) {
-    var roomID uint
-    if err := c.ShouldBindUri(&roomID); err != nil {
+func (h *RoomHandler) GetRoomByID(c *gin.Context) {
+    roomID, err := parseUint(c.Param("id"))
+    if err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
         return
     }
 
-    room, err := repository.GetRoomByID(roomID)
+    room, err := h.rooms.GetRoomByID(roomID)
     if err != nil {
         if err == repository.ErrRoomNotFound {
             c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Room not found"})
@@ -87,7 +107,7 @@ func GetRoomByID(c *gin.Context) {
 }
 
 // @Summary Update room information
-// @Description Update the information of an existing game room
+// @Description Update the information of an existing game room. Status may only be set to closed, maintenance or back to open; other statuses follow from the room's members and matches.
 // @Tags rooms
 // @Accept json
 // @Produce json
@@ -96,11 +116,12 @@ func GetRoomByID(c *gin.Context) {
 // @Success 200 {object} models.SuccessResponse "Update status"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 404 {object} models.ErrorResponse "Room not found"
+// @Failure 409 {object} models.ErrorResponse "Capacity below the seated players"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /rooms/{id} [put]
-func UpdateRoom(c *gin.Context) {
-    var roomID uint
-    if err := c.ShouldBindUri(&roomID); err != nil {
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+    roomID, err := parseUint(c.Param("id"))
+    if err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
         return
     }
@@ -116,16 +137,26 @@ func UpdateRoom(c *gin.Context) {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Room name is required"})
         return
     }
+    if msg := validateRoomSettings(room); msg != "" {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: msg})
+        return
+    }
 
-    err := repository.UpdateRoom(roomID, room)
+    err = h.rooms.UpdateRoom(roomID, room)
     if err != nil {
-        if err == repository.ErrRoomNotFound {
+        switch {
+        case errors.Is(err, repository.ErrRoomNotFound):
             c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Room not found"})
-        } else {
+        case errors.Is(err, repository.ErrCapacityTooSmall):
+            c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+        default:
             c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
         }
         return
     }
+    if updated, err := h.rooms.GetRoomByID(roomID); err == nil {
+        h.events.Publish(realtime.Event{Type: realtime.EventRoomUpdated, RoomID: roomID, Data: updated})
+    }
     c.JSON(http.StatusOK, models.SuccessResponse{Status: "updated"})
 }
 
@@ -139,14 +170,14 @@ func UpdateRoom(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse "Room not found"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /rooms/{id} [delete]
-func DeleteRoom(c *gin.Context) {
-    var roomID uint
-    if err := c.ShouldBindUri(&roomID); err != nil {
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+    roomID, err := parseUint(c.Param("id"))
+    if err != nil {
         c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
         return
     }
 
-    err := repository.DeleteRoom(roomID)
+    err = h.rooms.DeleteRoom(roomID)
     if err != nil {
         if err == repository.ErrRoomNotFound {
             c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Room not found"})
@@ -155,5 +186,109 @@ func DeleteRoom(c *gin.Context) {
         }
         return
     }
+    h.events.Publish(realtime.Event{Type: realtime.EventRoomDeleted, RoomID: roomID})
     c.JSON(http.StatusOK, models.SuccessResponse{Status: "deleted"})
-}
\ No newline at end of file
+}
+// validateRoomSettings checks the client-settable room fields and returns
+// the problem found, if any. Empty values are left to their defaults.
+func validateRoomSettings(room models.Room) string {
+    if room.Capacity < 0 {
+        return "Room capacity must not be negative"
+    }
+    switch room.Status {
+    case "", models.RoomStatusOpen, models.RoomStatusClosed, models.RoomStatusMaintenance:
+    default:
+        return "Room status can only be set to open, closed or maintenance"
+    }
+    for _, clock := range []string{room.OpensAt, room.ClosesAt} {
+        if clock == "" {
+            continue
+        }
+        if _, err := availability.ParseClock(clock); err != nil {
+            return err.Error()
+        }
+    }
+    if room.TimeZone != "" {
+        if _, err := time.LoadLocation(room.TimeZone); err != nil {
+            return "Invalid time zone " + room.TimeZone
+        }
+    }
+    return ""
+}
+
+// MembershipRequest names the player joining or leaving a room.
+type MembershipRequest struct {
+    PlayerID string `json:"player_id" binding:"required"`
+}
+
+// @Summary Join a room
+// @Description Seat a player in the room. A player can sit in one room at a time, and closed or full rooms cannot be joined.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param id path uint true "Room ID"
+// @Param member body MembershipRequest true "Joining player"
+// @Success 200 {object} models.Room "Room after joining"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Room not found"
+// @Failure 409 {object} models.ErrorResponse "Room full or closed, or player already in a room"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /rooms/{id}/join [post]
+func (h *RoomHandler) JoinRoom(c *gin.Context) {
+    h.changeMembership(c, realtime.EventPlayerJoined, h.rooms.JoinRoom)
+}
+
+// @Summary Leave a room
+// @Description Free the player's seat in the room
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Param id path uint true "Room ID"
+// @Param member body MembershipRequest true "Leaving player"
+// @Success 200 {object} models.Room "Room after leaving"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Room not found"
+// @Failure 409 {object} models.ErrorResponse "Player is not in the room"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /rooms/{id}/leave [post]
+func (h *RoomHandler) LeaveRoom(c *gin.Context) {
+    h.changeMembership(c, realtime.EventPlayerLeft, h.rooms.LeaveRoom)
+}
+
+// changeMembership runs a join or leave and broadcasts the outcome to the room.
+func (h *RoomHandler) changeMembership(c *gin.Context, eventType string, change func(roomID uint, playerID string) (*models.Room, error)) {
+    roomID, err := parseUint(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid room ID"})
+        return
+    }
+
+    var req MembershipRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+        return
+    }
+
+    room, err := change(roomID, req.PlayerID)
+    if err != nil {
+        c.JSON(membershipErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+        return
+    }
+    h.events.Publish(realtime.Event{Type: eventType, RoomID: roomID, Data: gin.H{"player_id": req.PlayerID, "room": room}})
+    c.JSON(http.StatusOK, room)
+}
+
+// membershipErrorStatus maps a join or leave error to its HTTP status code.
+func membershipErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, repository.ErrRoomNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, repository.ErrPlayerNotFound):
+        return http.StatusBadRequest
+    case errors.Is(err, repository.ErrRoomFull), errors.Is(err, repository.ErrRoomClosed),
+        errors.Is(err, repository.ErrAlreadyInRoom), errors.Is(err, repository.ErrNotInRoom):
+        return http.StatusConflict
+    default:
+        return http.StatusInternalServerError
+    }
+}
@@ -0,0 +1,105 @@
+// handlers/rooms_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRoomRouter() (*gin.Engine, *fakeRoomStore, *fakePublisher) {
+	rooms := newFakeRoomStore()
+	events := &fakePublisher{}
+	h := NewRoomHandler(rooms, events)
+
+	r := gin.New()
+	r.GET("/rooms", h.GetRooms)
+	r.POST("/rooms", h.CreateRoom)
+	r.GET("/rooms/:id", h.GetRoomByID)
+	r.PUT("/rooms/:id", h.UpdateRoom)
+	r.DELETE("/rooms/:id", h.DeleteRoom)
+	r.POST("/rooms/:id/join", h.JoinRoom)
+	r.POST("/rooms/:id/leave", h.LeaveRoom)
+	return r, rooms, events
+}
+
+func TestCreateRoomHandlerRequiresName(t *testing.T) {
+	r, rooms, _ := newRoomRouter()
+
+	w := performRequest(r, http.MethodPost, "/rooms", models.Room{Description: "No name"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, rooms.rooms)
+
+	w = performRequest(r, http.MethodPost, "/rooms", models.Room{Name: "Room A"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, rooms.rooms, 1)
+}
+
+func TestRoomByIDHandlers(t *testing.T) {
+	r, rooms, events := newRoomRouter()
+	rooms.CreateRoom(models.Room{Name: "Room A"})
+	id, _ := rooms.CreateRoom(models.Room{Name: "Room B"})
+
+	w := performRequest(r, http.MethodGet, "/rooms/2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var room models.Room
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &room))
+	assert.Equal(t, "Room B", room.Name)
+
+	w = performRequest(r, http.MethodPut, "/rooms/2", models.Room{Name: "Room B2", Status: "occupied"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(r, http.MethodPut, "/rooms/2", models.Room{Name: "Room B2", Status: models.RoomStatusClosed})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.RoomStatusClosed, rooms.rooms[id].Status)
+
+	w = performRequest(r, http.MethodDelete, "/rooms/2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(r, http.MethodGet, "/rooms/2", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performRequest(r, http.MethodGet, "/rooms/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, []string{realtime.EventRoomUpdated, realtime.EventRoomDeleted}, events.types())
+}
+
+func TestJoinAndLeaveRoomHandlers(t *testing.T) {
+	r, rooms, events := newRoomRouter()
+	rooms.CreateRoom(models.Room{Name: "Room A", Capacity: 1})
+	rooms.CreateRoom(models.Room{Name: "Room B"})
+
+	w := performRequest(r, http.MethodPost, "/rooms/1/join", MembershipRequest{PlayerID: "7"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var room models.Room
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &room))
+	if assert.Len(t, room.Members, 1) {
+		assert.Equal(t, 1, room.Members[0].Seat)
+	}
+
+	for path, body := range map[string]MembershipRequest{
+		"/rooms/1/join":  {PlayerID: "8"}, // full
+		"/rooms/2/join":  {PlayerID: "7"}, // already seated elsewhere
+		"/rooms/2/leave": {PlayerID: "7"}, // not in that room
+	} {
+		w = performRequest(r, http.MethodPost, path, body)
+		assert.Equal(t, http.StatusConflict, w.Code, path)
+	}
+	w = performRequest(r, http.MethodPost, "/rooms/9/join", MembershipRequest{PlayerID: "8"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodPost, "/rooms/1/join", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(r, http.MethodPost, "/rooms/1/leave", MembershipRequest{PlayerID: "7"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, rooms.rooms[1].Members)
+
+	assert.Equal(t, []string{realtime.EventPlayerJoined, realtime.EventPlayerLeft}, events.types())
+}
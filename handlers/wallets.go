@@ -0,0 +1,111 @@
+// handlers/wallets.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ledger pages hold ledgerLimit entries unless the client asks for fewer,
+// or up to maxLedgerLimit.
+const (
+	ledgerLimit    = 50
+	maxLedgerLimit = 500
+)
+
+// WalletHandler serves player wallets and their ledgers.
+type WalletHandler struct {
+	wallets repository.WalletStore
+	players repository.PlayerStore
+}
+
+// NewWalletHandler creates a WalletHandler backed by the given stores.
+func NewWalletHandler(wallets repository.WalletStore, players repository.PlayerStore) *WalletHandler {
+	return &WalletHandler{wallets: wallets, players: players}
+}
+
+// @Summary Get a player's wallet
+// @Description Retrieve the player's wallet balance in cents. Successful payments are deposited into it, challenge entry fees are paid from it and jackpot winnings are credited to it.
+// @Tags players
+// @Produce json
+// @Param id path string true "Player ID"
+// @Success 200 {object} models.Wallet "The player's wallet"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/wallet [get]
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	playerID, ok := h.player(c)
+	if !ok {
+		return
+	}
+	wallet, err := h.wallets.GetWallet(playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// @Summary Get a player's ledger
+// @Description Retrieve the ledger entries of the player's wallet, newest first. Amounts are in cents; positive amounts credit the wallet. Each entry belongs to a transfer whose entries sum to zero.
+// @Tags players
+// @Produce json
+// @Param id path string true "Player ID"
+// @Param limit query int false "Maximum number of entries to return (default 50, at most 500)"
+// @Success 200 {array} models.LedgerEntry "The player's ledger entries"
+// @Failure 404 {object} models.ErrorResponse "Player not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /players/{id}/ledger [get]
+func (h *WalletHandler) GetLedger(c *gin.Context) {
+	playerID, ok := h.player(c)
+	if !ok {
+		return
+	}
+	limit := ledgerLimit
+	if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, maxLedgerLimit)
+	}
+
+	entries, err := h.wallets.GetLedger(playerID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if entries == nil {
+		entries = []models.LedgerEntry{}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// player looks up the player named in the path and returns the ID their
+// wallet is kept under. It writes the error response when there is none.
+func (h *WalletHandler) player(c *gin.Context) (uint, bool) {
+	return numericPlayer(c, h.players)
+}
+
+// numericPlayer looks up the player named in the path and returns their ID
+// as the number wallets and challenges are kept under. It writes the error
+// response when there is no such player.
+func numericPlayer(c *gin.Context, players repository.PlayerStore) (uint, bool) {
+	if _, err := players.GetPlayerByID(c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrPlayerNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		}
+		return 0, false
+	}
+	playerID, err := parseUint(c.Param("id"))
+	if err != nil {
+		// Only numeric player IDs can hold a wallet or enter challenges
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Player not found"})
+		return 0, false
+	}
+	return playerID, true
+}
@@ -0,0 +1,66 @@
+// handlers/wallets_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWalletRouter() (*gin.Engine, *fakeWalletStore, *fakePlayerStore) {
+	wallets := &fakeWalletStore{wallets: map[uint]models.Wallet{}}
+	players := newFakePlayerStore()
+	h := NewWalletHandler(wallets, players)
+
+	r := gin.New()
+	r.GET("/players/:id/wallet", h.GetWallet)
+	r.GET("/players/:id/ledger", h.GetLedger)
+	return r, wallets, players
+}
+
+func TestGetWalletHandler(t *testing.T) {
+	r, wallets, players := newWalletRouter()
+	id, _ := players.CreatePlayer(models.Player{Name: "Alice"})
+	wallets.wallets[1] = models.Wallet{PlayerID: 1, Balance: 2501}
+
+	var wallet models.Wallet
+	w := performRequest(r, http.MethodGet, "/players/"+id+"/wallet", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wallet))
+	assert.Equal(t, int64(2501), wallet.Balance)
+
+	w = performRequest(r, http.MethodGet, "/players/99/wallet", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLedgerHandler(t *testing.T) {
+	r, wallets, players := newWalletRouter()
+	id, _ := players.CreatePlayer(models.Player{Name: "Alice"})
+	for i := 1; i <= 3; i++ {
+		wallets.entries = append(wallets.entries, models.LedgerEntry{ID: uint(i), Transfer: fmt.Sprintf("payment:%d", i), Account: "player:1", Kind: models.LedgerKindDeposit, Amount: 100})
+	}
+	wallets.entries = append(wallets.entries, models.LedgerEntry{ID: 4, Transfer: "payment:4", Account: "player:3", Amount: 100})
+
+	var entries []models.LedgerEntry
+	w := performRequest(r, http.MethodGet, "/players/"+id+"/ledger?limit=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, uint(3), entries[0].ID)
+	}
+
+	// Players without entries get an empty list
+	id, _ = players.CreatePlayer(models.Player{Name: "Bob"})
+	w = performRequest(r, http.MethodGet, "/players/"+id+"/ledger", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	w = performRequest(r, http.MethodGet, "/players/abc/ledger", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,246 @@
+// handlers/webhooks.go
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// deliveryLimit and maxDeliveryLimit bound the delivery log page.
+	deliveryLimit    = 50
+	maxDeliveryLimit = 500
+
+	maxWebhookURLLength = 2048
+	// minWebhookSecretLength keeps chosen secrets from being guessable.
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 255
+)
+
+// WebhookHandler serves the webhook subscription endpoints.
+type WebhookHandler struct {
+	webhooks repository.WebhookStore
+}
+
+// NewWebhookHandler creates a WebhookHandler backed by the given store.
+func NewWebhookHandler(webhooks repository.WebhookStore) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// WebhookRequest represents the request body for creating a webhook.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required" example:"https://example.com/hooks/oxo"`
+	Events []string `json:"events" binding:"required" example:"payment.succeeded,challenge.resolved"` // payment.succeeded, payment.failed, challenge.resolved, jackpot.won
+	Secret string   `json:"secret,omitempty"`                                                         // Signs the deliveries; generated when left out
+}
+
+// @Summary Subscribe a webhook
+// @Description Subscribe a URL to events. Every event is POSTed to it as JSON, signed in the X-Webhook-Signature header as t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed with the secret>. Deliveries answered with anything but a 2xx are retried with exponential backoff. The secret is only shown in this response.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body WebhookRequest true "Webhook subscription"
+// @Success 201 {object} models.Webhook "The webhook, including its secret"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if msg := validateWebhook(req); msg != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: msg})
+		return
+	}
+	if req.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to generate a secret"})
+			return
+		}
+		req.Secret = secret
+	}
+
+	webhook := models.Webhook{URL: req.URL, Events: slices.Compact(slices.Sorted(slices.Values(req.Events))), Secret: req.Secret}
+	id, err := h.webhooks.CreateWebhook(webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	created, err := h.webhooks.GetWebhookByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// @Summary List webhooks
+// @Description Retrieve every webhook, without their secrets
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook "A list of webhooks"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooks.GetWebhooks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if webhooks == nil {
+		webhooks = []models.Webhook{}
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary Get a webhook
+// @Description Retrieve a webhook by its ID, without its secret
+// @Tags Webhooks
+// @Produce json
+// @Param id path uint true "Webhook ID"
+// @Success 200 {object} models.Webhook "The webhook"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+	webhook.Secret = ""
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Delete a webhook
+// @Description Unsubscribe a webhook. Its deliveries, including the ones not yet sent, are deleted with it.
+// @Tags Webhooks
+// @Produce json
+// @Param id path uint true "Webhook ID"
+// @Success 200 {object} models.SuccessResponse "Deletion status"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid webhook ID"})
+		return
+	}
+	if err := h.webhooks.DeleteWebhook(id); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{Status: "deleted"})
+}
+
+// @Summary Get a webhook's deliveries
+// @Description Retrieve the most recent deliveries to a webhook, newest first, with the payload sent and the outcome of the latest attempt
+// @Tags Webhooks
+// @Produce json
+// @Param id path uint true "Webhook ID"
+// @Param status query string false "Only deliveries with this status: pending, succeeded or failed"
+// @Param limit query int false "Maximum number of deliveries to return (default 50, at most 500)"
+// @Success 200 {array} models.WebhookDelivery "The webhook's deliveries"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 404 {object} models.ErrorResponse "Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.DeliveryStatusPending, models.DeliveryStatusSucceeded, models.DeliveryStatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid status, use pending, succeeded or failed"})
+		return
+	}
+	limit := deliveryLimit
+	if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, maxDeliveryLimit)
+	}
+	webhook, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhooks.GetWebhookDeliveries(webhook.ID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// webhook looks up the webhook named in the path. It writes the error
+// response when there is none.
+func (h *WebhookHandler) webhook(c *gin.Context) (*models.Webhook, bool) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid webhook ID"})
+		return nil, false
+	}
+	webhook, err := h.webhooks.GetWebhookByID(id)
+	if err != nil {
+		writeWebhookError(c, err)
+		return nil, false
+	}
+	return webhook, true
+}
+
+// writeWebhookError maps webhook store errors to responses.
+func writeWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+}
+
+// validateWebhook checks a webhook subscription and returns the problem
+// found, if any.
+func validateWebhook(req WebhookRequest) string {
+	u, err := url.Parse(req.URL)
+	switch {
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > maxWebhookURLLength:
+		return "URL must be an absolute http or https URL of at most 2048 characters"
+	case len(req.Events) == 0:
+		return "At least one event is required"
+	case req.Secret != "" && (len(req.Secret) < minWebhookSecretLength || len(req.Secret) > maxWebhookSecretLength):
+		return "Secret must be between 16 and 255 characters"
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return "Unknown event " + strconv.Quote(event) + ", use payment.succeeded, payment.failed, challenge.resolved or jackpot.won"
+		}
+	}
+	return ""
+}
+
+// newWebhookSecret returns a random secret for signing deliveries.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
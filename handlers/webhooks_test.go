@@ -0,0 +1,125 @@
+// handlers/webhooks_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebhookRouter() (*gin.Engine, *fakeWebhookStore) {
+	webhooks := newFakeWebhookStore()
+	h := NewWebhookHandler(webhooks)
+
+	r := gin.New()
+	r.GET("/webhooks", h.GetWebhooks)
+	r.POST("/webhooks", h.CreateWebhook)
+	r.GET("/webhooks/:id", h.GetWebhook)
+	r.DELETE("/webhooks/:id", h.DeleteWebhook)
+	r.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+	return r, webhooks
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	r, _ := newWebhookRouter()
+
+	w := performRequest(r, http.MethodGet, "/webhooks", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	w = performRequest(r, http.MethodPost, "/webhooks", WebhookRequest{
+		URL:    "https://example.com/hooks",
+		Events: []string{models.EventPaymentSucceeded, models.EventJackpotWon, models.EventPaymentSucceeded},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Webhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, uint(1), created.ID)
+	assert.Equal(t, []string{models.EventJackpotWon, models.EventPaymentSucceeded}, created.Events)
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, created.Secret)
+
+	// A chosen secret is kept
+	w = performRequest(r, http.MethodPost, "/webhooks", WebhookRequest{
+		URL: "http://localhost:8000/hook", Events: []string{models.EventChallengeResolved}, Secret: "my-own-long-secret",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"secret":"my-own-long-secret"`)
+
+	// Secrets are only shown on creation
+	w = performRequest(r, http.MethodGet, "/webhooks", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listed []models.Webhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+	assert.NotContains(t, w.Body.String(), "secret")
+	w = performRequest(r, http.MethodGet, "/webhooks/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = performRequest(r, http.MethodDelete, "/webhooks/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = performRequest(r, http.MethodGet, "/webhooks/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodDelete, "/webhooks/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(r, http.MethodGet, "/webhooks/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookValidation(t *testing.T) {
+	r, webhooks := newWebhookRouter()
+	valid := WebhookRequest{URL: "https://example.com/hooks", Events: []string{models.EventPaymentFailed}}
+
+	cases := map[string]func(req *WebhookRequest){
+		"missing url":   func(req *WebhookRequest) { req.URL = "" },
+		"relative url":  func(req *WebhookRequest) { req.URL = "/hooks" },
+		"other scheme":  func(req *WebhookRequest) { req.URL = "ftp://example.com/hooks" },
+		"no events":     func(req *WebhookRequest) { req.Events = []string{} },
+		"unknown event": func(req *WebhookRequest) { req.Events = []string{"payment.refunded"} },
+		"short secret":  func(req *WebhookRequest) { req.Secret = "short" },
+	}
+	for name, mutate := range cases {
+		req := valid
+		mutate(&req)
+		w := performRequest(r, http.MethodPost, "/webhooks", req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+	list, _ := webhooks.GetWebhooks()
+	assert.Empty(t, list)
+}
+
+func TestWebhookDeliveryLog(t *testing.T) {
+	r, webhooks := newWebhookRouter()
+	id, _ := webhooks.CreateWebhook(models.Webhook{URL: "https://example.com/hooks", Events: models.WebhookEvents, Secret: "whsec"})
+	webhooks.deliveries = []models.WebhookDelivery{
+		{ID: 1, WebhookID: id, Event: models.EventPaymentSucceeded, Status: models.DeliveryStatusSucceeded, Attempts: 1, ResponseStatus: 200},
+		{ID: 2, WebhookID: id, Event: models.EventPaymentFailed, Status: models.DeliveryStatusPending, Attempts: 2, ResponseStatus: 503, LastError: "receiver answered 503"},
+		{ID: 3, WebhookID: id + 1, Event: models.EventJackpotWon, Status: models.DeliveryStatusPending},
+	}
+
+	w := performRequest(r, http.MethodGet, "/webhooks/1/deliveries", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deliveries []models.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, uint(2), deliveries[0].ID)
+		assert.Equal(t, "receiver answered 503", deliveries[0].LastError)
+	}
+
+	w = performRequest(r, http.MethodGet, "/webhooks/1/deliveries?status=succeeded&limit=5", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, uint(1), deliveries[0].ID)
+	}
+
+	w = performRequest(r, http.MethodGet, "/webhooks/1/deliveries?status=bounced", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performRequest(r, http.MethodGet, "/webhooks/9/deliveries", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
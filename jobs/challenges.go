@@ -0,0 +1,85 @@
+// jobs/challenges.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/repository"
+)
+
+// resolveBatch caps how many due challenges one pass picks up.
+const resolveBatch = 100
+
+// ChallengeResolver decides the outcome of challenges once their duration
+// is over. Pending challenges live in the database, so challenges entered
+// before a restart are resolved when the resolver starts again, and several
+// API replicas can run a resolver side by side.
+type ChallengeResolver struct {
+	challenges repository.ChallengeStore
+	cfg        config.ChallengeConfig
+}
+
+// NewChallengeResolver creates a resolver using the given store and settings.
+func NewChallengeResolver(challenges repository.ChallengeStore, cfg config.ChallengeConfig) *ChallengeResolver {
+	return &ChallengeResolver{challenges: challenges, cfg: cfg}
+}
+
+// Run resolves due challenges right away and then every ResolveInterval
+// until ctx is cancelled.
+func (r *ChallengeResolver) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ResolveInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.ResolveDue(time.Now()); err != nil {
+			log.Printf("Challenge resolution failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ResolveDue resolves the challenges due at now and returns how many this
+// resolver settled. Challenges taken by another resolver are skipped. A
+// challenge that fails to resolve is left pending for the next pass without
+// holding up the others; the failures are returned together at the end.
+func (r *ChallengeResolver) ResolveDue(now time.Time) (int, error) {
+	resolved := 0
+	failed := map[uint]bool{}
+	var failures []error
+	for {
+		due, err := r.challenges.GetDueChallenges(now, resolveBatch)
+		if err != nil {
+			return resolved, errors.Join(append(failures, err)...)
+		}
+		settled := 0
+		for _, challenge := range due {
+			_, err := r.challenges.ResolveChallenge(challenge.ID, now, r.cfg)
+			if errors.Is(err, repository.ErrChallengeResolved) {
+				continue
+			}
+			if err != nil {
+				// Failed challenges come up again in the next batch; report each once
+				if !failed[challenge.ID] {
+					failed[challenge.ID] = true
+					failures = append(failures, fmt.Errorf("challenge %d: %w", challenge.ID, err))
+				}
+				continue
+			}
+			settled++
+		}
+		resolved += settled
+		// Stop once a batch runs short, or when every row is held by other
+		// resolvers or failing
+		if len(due) < resolveBatch || settled == 0 {
+			return resolved, errors.Join(failures...)
+		}
+	}
+}
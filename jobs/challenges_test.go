@@ -0,0 +1,117 @@
+// jobs/challenges_test.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolveStore holds pending challenges and settles each at most once.
+type fakeResolveStore struct {
+	repository.ChallengeStore
+	mu         sync.Mutex
+	challenges []models.Challenge
+	taken      map[uint]bool // Challenges another replica is resolving
+}
+
+func (f *fakeResolveStore) GetDueChallenges(now time.Time, limit int) ([]models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []models.Challenge
+	for _, ch := range f.challenges {
+		if ch.Status == models.ChallengeStatusPending && !ch.ResolveAt.After(now) && len(due) < limit {
+			due = append(due, ch)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeResolveStore) ResolveChallenge(id uint, now time.Time, cfg config.ChallengeConfig) (*models.Challenge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := &f.challenges[id-1]
+	if f.taken[id] || ch.Status != models.ChallengeStatusPending {
+		return nil, repository.ErrChallengeResolved
+	}
+	ch.Status = models.ChallengeStatusResolved
+	return ch, nil
+}
+
+func (f *fakeResolveStore) pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, ch := range f.challenges {
+		if ch.Status == models.ChallengeStatusPending {
+			n++
+		}
+	}
+	return n
+}
+
+func newFakeResolveStore(n int, resolveAt time.Time) *fakeResolveStore {
+	f := &fakeResolveStore{taken: map[uint]bool{}}
+	for id := 1; id <= n; id++ {
+		f.challenges = append(f.challenges, models.Challenge{ID: uint(id), Status: models.ChallengeStatusPending, ResolveAt: resolveAt})
+	}
+	return f
+}
+
+func TestResolveDueSkipsTakenChallenges(t *testing.T) {
+	now := time.Date(2024, 10, 10, 14, 0, 0, 0, time.UTC)
+	store := newFakeResolveStore(resolveBatch+5, now)
+	store.challenges = append(store.challenges, models.Challenge{ID: resolveBatch + 6, Status: models.ChallengeStatusPending, ResolveAt: now.Add(time.Second)})
+	store.taken[3] = true
+
+	resolver := NewChallengeResolver(store, config.ChallengeConfig{ResolveInterval: time.Second})
+	resolved, err := resolver.ResolveDue(now)
+	assert.NoError(t, err)
+	assert.Equal(t, resolveBatch+4, resolved)
+	assert.Equal(t, 2, store.pending()) // The taken one and the one not yet due
+}
+
+func TestResolverPicksUpChallengesOnStart(t *testing.T) {
+	store := newFakeResolveStore(3, time.Now().Add(-time.Hour))
+	resolver := NewChallengeResolver(store, config.ChallengeConfig{ResolveInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go resolver.Run(ctx)
+
+	assert.Eventually(t, func() bool { return store.pending() == 0 }, time.Second, time.Millisecond)
+}
+
+// failingResolveStore fails to resolve some of its challenges.
+type failingResolveStore struct {
+	*fakeResolveStore
+	failing map[uint]bool
+}
+
+func (f *failingResolveStore) ResolveChallenge(id uint, now time.Time, cfg config.ChallengeConfig) (*models.Challenge, error) {
+	if f.failing[id] {
+		return nil, errors.New("unknown odds model")
+	}
+	return f.fakeResolveStore.ResolveChallenge(id, now, cfg)
+}
+
+func TestResolveDueGoesPastFailingChallenges(t *testing.T) {
+	now := time.Date(2024, 10, 10, 14, 0, 0, 0, time.UTC)
+	store := &failingResolveStore{fakeResolveStore: newFakeResolveStore(resolveBatch+5, now), failing: map[uint]bool{1: true, 4: true}}
+
+	resolver := NewChallengeResolver(store, config.ChallengeConfig{ResolveInterval: time.Second})
+	resolved, err := resolver.ResolveDue(now)
+	assert.Equal(t, resolveBatch+3, resolved)
+	assert.Equal(t, 2, store.pending())
+	if assert.Error(t, err) {
+		assert.Equal(t, "challenge 1: unknown odds model\nchallenge 4: unknown odds model", err.Error())
+	}
+}
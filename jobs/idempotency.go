@@ -0,0 +1,51 @@
+// jobs/idempotency.go
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/repository"
+)
+
+// IdempotencyPurger periodically deletes expired idempotency keys. Expired
+// keys are already ignored when claimed; purging only keeps the table small.
+type IdempotencyPurger struct {
+	keys repository.IdempotencyStore
+	cfg  config.IdempotencyConfig
+}
+
+// NewIdempotencyPurger creates a purger using the given store and settings.
+func NewIdempotencyPurger(keys repository.IdempotencyStore, cfg config.IdempotencyConfig) *IdempotencyPurger {
+	return &IdempotencyPurger{keys: keys, cfg: cfg}
+}
+
+// Run purges right away and then every PurgeInterval until ctx is cancelled.
+func (p *IdempotencyPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Purge(time.Now().UTC()); err != nil {
+			log.Printf("Idempotency key purge failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the keys expired by now and returns how many there were.
+func (p *IdempotencyPurger) Purge(now time.Time) (int64, error) {
+	purged, err := p.keys.PurgeIdempotencyKeys(now)
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		log.Printf("Purged %d expired idempotency keys", purged)
+	}
+	return purged, nil
+}
@@ -0,0 +1,38 @@
+// jobs/idempotency_test.go
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIdempotencyStore records the purges asked of it.
+type fakeIdempotencyStore struct {
+	repository.IdempotencyStore
+	purgedAt []time.Time
+	err      error
+}
+
+func (f *fakeIdempotencyStore) PurgeIdempotencyKeys(now time.Time) (int64, error) {
+	f.purgedAt = append(f.purgedAt, now)
+	return 3, f.err
+}
+
+func TestPurgeDeletesExpiredKeys(t *testing.T) {
+	store := &fakeIdempotencyStore{}
+	now := time.Now()
+
+	purged, err := NewIdempotencyPurger(store, config.Default().Idempotency).Purge(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.Equal(t, []time.Time{now}, store.purgedAt)
+
+	_, err = NewIdempotencyPurger(&fakeIdempotencyStore{err: errors.New("boom")}, config.Default().Idempotency).Purge(now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+// jobs/ledger.go
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+)
+
+// LedgerAuditor periodically checks that the ledger still adds up: every
+// transfer balances, and the wallets and jackpot hold what their ledger
+// accounts sum to. Broken invariants are logged for an operator to look
+// into; the auditor never corrects balances itself.
+type LedgerAuditor struct {
+	wallets repository.WalletStore
+	cfg     config.PaymentConfig
+}
+
+// NewLedgerAuditor creates an auditor using the given store and settings.
+func NewLedgerAuditor(wallets repository.WalletStore, cfg config.PaymentConfig) *LedgerAuditor {
+	return &LedgerAuditor{wallets: wallets, cfg: cfg}
+}
+
+// Run audits the ledger right away and then every AuditInterval until ctx
+// is cancelled.
+func (a *LedgerAuditor) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.AuditInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.Audit(); err != nil {
+			log.Printf("Ledger audit failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Audit checks the ledger once, logs every discrepancy found and returns them.
+func (a *LedgerAuditor) Audit() ([]models.LedgerDiscrepancy, error) {
+	discrepancies, err := a.wallets.CheckLedger()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range discrepancies {
+		if d.Transfer != "" {
+			log.Printf("Ledger transfer %s does not balance: off by %d", d.Transfer, d.Actual)
+		} else {
+			log.Printf("Ledger account %s sums to %d but its balance is %d", d.Account, d.Expected, d.Actual)
+		}
+	}
+	return discrepancies, nil
+}
@@ -0,0 +1,38 @@
+// jobs/ledger_test.go
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLedgerStore reports a fixed ledger check result.
+type fakeLedgerStore struct {
+	repository.WalletStore
+	discrepancies []models.LedgerDiscrepancy
+	err           error
+}
+
+func (f *fakeLedgerStore) CheckLedger() ([]models.LedgerDiscrepancy, error) {
+	return f.discrepancies, f.err
+}
+
+func TestAuditReportsDiscrepancies(t *testing.T) {
+	cfg := config.Default().Payment
+	found := []models.LedgerDiscrepancy{
+		{Transfer: "payment:3", Actual: 5},
+		{Account: "player:1", Expected: 1000, Actual: 1500},
+	}
+	discrepancies, err := NewLedgerAuditor(&fakeLedgerStore{discrepancies: found}, cfg).Audit()
+	assert.NoError(t, err)
+	assert.Equal(t, found, discrepancies)
+
+	_, err = NewLedgerAuditor(&fakeLedgerStore{err: errors.New("boom")}, cfg).Audit()
+	assert.Error(t, err)
+}
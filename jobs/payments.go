@@ -0,0 +1,163 @@
+// jobs/payments.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/gateway"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+)
+
+const (
+	// paymentBatchSize caps how many payments one pass charges at once.
+	paymentBatchSize = 20
+	// paymentLeaseMargin is added to the time the gateway calls of a charge
+	// may take when leasing a payment, so the lease outlasts the charge.
+	paymentLeaseMargin = time.Minute
+)
+
+// PaymentProcessor charges accepted payments through the gateway of their
+// method. Pending payments are claimed from the database, so payments
+// accepted before a restart are charged when the processor starts again,
+// and several API replicas can run a processor side by side. Payments the
+// provider confirms later stay Pending until its callback arrives.
+type PaymentProcessor struct {
+	payments repository.PaymentStore
+	gateways *gateway.Registry
+	cfg      config.PaymentConfig
+	lease    time.Duration
+}
+
+// NewPaymentProcessor creates a processor charging through the given
+// gateways, using the given store and settings.
+func NewPaymentProcessor(payments repository.PaymentStore, gateways *gateway.Registry, cfg config.PaymentConfig) *PaymentProcessor {
+	// A charge makes up to two calls, an authorize and a capture
+	var timeout time.Duration
+	for _, g := range cfg.Gateways {
+		timeout = max(timeout, g.Timeout)
+	}
+	return &PaymentProcessor{payments: payments, gateways: gateways, cfg: cfg, lease: 2*timeout + paymentLeaseMargin}
+}
+
+// Run charges due payments right away and then every ProcessInterval until
+// ctx is cancelled.
+func (p *PaymentProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.ProcessInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.ProcessDue(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Payment processing failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue charges the payments due by now, a batch at a time until none
+// is left, and returns how many were attempted.
+func (p *PaymentProcessor) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	attempted := 0
+	for ctx.Err() == nil {
+		due, err := p.payments.ClaimDuePayments(now, p.lease, paymentBatchSize)
+		if err != nil {
+			return attempted, err
+		}
+		if len(due) == 0 {
+			break
+		}
+		var wg sync.WaitGroup
+		for _, payment := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.process(ctx, payment)
+			}()
+		}
+		wg.Wait()
+		attempted += len(due)
+	}
+	return attempted, nil
+}
+
+// process charges one payment and records the outcome.
+func (p *PaymentProcessor) process(ctx context.Context, payment models.Payment) {
+	status, err := p.charge(ctx, &payment)
+	switch {
+	case ctx.Err() != nil:
+		log.Printf("Payment %d was interrupted and is charged again once its lease is over", payment.ID)
+		return
+	case err != nil:
+		payment.Status = "Failed"
+		payment.ErrorMessage = paymentErrorMessage(err)
+		log.Printf("Payment %d failed: %v", payment.ID, err)
+	case status == gateway.StatusPending:
+		log.Printf("Payment %d awaits confirmation of transaction %s", payment.ID, payment.TransactionID)
+	default:
+		payment.Status = "Success"
+	}
+
+	// A callback may have settled the payment meanwhile; its outcome stands
+	payment.ProcessAt = nil
+	if err := p.payments.UpdatePayment(payment); err != nil {
+		log.Printf("Payment %d could not be updated to %s: %v", payment.ID, payment.Status, err)
+	}
+}
+
+// charge authorizes the payment and captures it in full, and returns the
+// provider's status for it: captured, or pending when the provider confirms
+// later by callback. The transaction ID is recorded on the payment as soon
+// as the provider issues it, so callbacks can find the payment, and a
+// payment taken up again after an interrupted charge is only captured.
+func (p *PaymentProcessor) charge(ctx context.Context, payment *models.Payment) (string, error) {
+	g, err := p.gateways.Get(payment.Method)
+	if err != nil {
+		return "", err
+	}
+	if payment.TransactionID == "" {
+		req := gateway.Request{Reference: strconv.FormatUint(uint64(payment.ID), 10), Amount: payment.Amount}
+		if payment.Details != "" {
+			req.Details = json.RawMessage(payment.Details)
+		}
+		auth, err := g.Authorize(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		payment.TransactionID = auth.TransactionID
+		if auth.Status == gateway.StatusPending {
+			// The provider's callback settles the payment from here
+			return auth.Status, nil
+		}
+		if err := p.payments.UpdatePayment(*payment); err != nil {
+			return "", err
+		}
+	}
+	capture, err := g.Capture(ctx, payment.TransactionID, payment.Amount)
+	if err != nil {
+		return "", err
+	}
+	return capture.Status, nil
+}
+
+// paymentErrorMessage is what the player sees about a failed payment. The
+// provider's reason is shown for declines; other failures stay generic.
+func paymentErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, gateway.ErrDeclined):
+		return err.Error()
+	case errors.Is(err, gateway.ErrUnknownMethod):
+		return "Unsupported payment method"
+	default:
+		return "Payment provider unavailable"
+	}
+}
@@ -0,0 +1,139 @@
+// jobs/payments_test.go
+package jobs
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/gateway"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePaymentStore holds payments in memory.
+type fakePaymentStore struct {
+	repository.PaymentStore
+	mu       sync.Mutex
+	payments map[uint]models.Payment
+}
+
+func (f *fakePaymentStore) ClaimDuePayments(now time.Time, lease time.Duration, limit int) ([]models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []models.Payment
+	for id, payment := range f.payments {
+		if len(due) < limit && payment.Status == "Pending" && payment.ProcessAt != nil && !payment.ProcessAt.After(now) {
+			next := now.Add(lease)
+			payment.ProcessAt = &next
+			f.payments[id] = payment
+			due = append(due, payment)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakePaymentStore) UpdatePayment(payment models.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := f.payments[payment.ID]
+	if !stored.CanMoveTo(payment.Status) {
+		return repository.ErrPaymentTransition
+	}
+	stored.Status, stored.TransactionID, stored.ErrorMessage, stored.ProcessAt = payment.Status, payment.TransactionID, payment.ErrorMessage, payment.ProcessAt
+	if stored.Status != "Pending" {
+		stored.ProcessAt = nil
+	}
+	f.payments[payment.ID] = stored
+	return nil
+}
+
+// newPaymentTest returns a processor charging CreditCard payments through
+// a stub provider, and a store holding one payment due at now.
+func newPaymentTest(t *testing.T, now time.Time) (*PaymentProcessor, *fakePaymentStore, *gateway.Stub) {
+	t.Helper()
+	stub := gateway.NewStub("")
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	cfg := config.PaymentConfig{
+		ProcessInterval: time.Second,
+		Gateways:        map[string]config.GatewayConfig{"CreditCard": {URL: srv.URL, Timeout: 200 * time.Millisecond}},
+	}
+	gateways := gateway.NewRegistry()
+	gateways.Register("CreditCard", gateway.NewHTTPGateway(cfg.Gateways["CreditCard"]))
+	store := &fakePaymentStore{payments: map[uint]models.Payment{
+		1: {ID: 1, PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Pending", ProcessAt: &now},
+	}}
+	return NewPaymentProcessor(store, gateways, cfg), store, stub
+}
+
+func TestProcessChargesPaymentsLeftPending(t *testing.T) {
+	now := time.Now().UTC()
+	processor, store, stub := newPaymentTest(t, now)
+
+	// The payment was accepted before the processor started
+	attempted, err := processor.ProcessDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	payment := store.payments[1]
+	assert.Equal(t, "Success", payment.Status)
+	assert.Nil(t, payment.ProcessAt)
+	tx, ok := stub.Transaction(payment.TransactionID)
+	if assert.True(t, ok) {
+		assert.Equal(t, gateway.StatusCaptured, tx.Status)
+		assert.Equal(t, "1", tx.Reference)
+	}
+
+	attempted, err = processor.ProcessDue(context.Background(), now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, attempted)
+}
+
+func TestProcessTakesUpInterruptedCharges(t *testing.T) {
+	now := time.Now().UTC()
+	processor, store, stub := newPaymentTest(t, now)
+	stub.Script(gateway.OpCapture, gateway.Behavior{Latency: 100 * time.Millisecond})
+
+	// Shutting down midway leaves the payment to the next processor
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	attempted, err := processor.ProcessDue(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	payment := store.payments[1]
+	assert.Equal(t, "Pending", payment.Status)
+	assert.NotEmpty(t, payment.TransactionID)
+
+	// It is not charged twice while its lease holds
+	attempted, err = processor.ProcessDue(context.Background(), now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Zero(t, attempted)
+
+	// Once it is over the authorization is captured rather than repeated
+	stub.Script(gateway.OpCapture, gateway.Behavior{})
+	attempted, err = processor.ProcessDue(context.Background(), now.Add(processor.lease))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, "Success", store.payments[1].Status)
+	assert.Equal(t, 1, stub.Calls(gateway.OpAuthorize))
+}
+
+func TestProcessLeavesPendingConfirmationsToCallbacks(t *testing.T) {
+	now := time.Now().UTC()
+	processor, store, stub := newPaymentTest(t, now)
+	stub.Script(gateway.OpAuthorize, gateway.Behavior{Outcome: gateway.OutcomePending})
+
+	_, err := processor.ProcessDue(context.Background(), now)
+	assert.NoError(t, err)
+	payment := store.payments[1]
+	assert.Equal(t, "Pending", payment.Status)
+	assert.NotEmpty(t, payment.TransactionID)
+	assert.Nil(t, payment.ProcessAt)
+	assert.Zero(t, stub.Calls(gateway.OpCapture))
+}
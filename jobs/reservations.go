@@ -0,0 +1,55 @@
+// jobs/reservations.go
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/repository"
+)
+
+// ReservationSweeper periodically settles reservations whose time has
+// passed: unclaimed bookings become no_show once the grace period after
+// their start is over, and checked-in bookings become completed once they
+// end.
+type ReservationSweeper struct {
+	reservations repository.ReservationStore
+	cfg          config.ReservationConfig
+}
+
+// NewReservationSweeper creates a sweeper using the given store and policies.
+func NewReservationSweeper(reservations repository.ReservationStore, cfg config.ReservationConfig) *ReservationSweeper {
+	return &ReservationSweeper{reservations: reservations, cfg: cfg}
+}
+
+// Run sweeps every SweepInterval until ctx is cancelled.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+	for {
+		if _, _, err := s.Sweep(time.Now()); err != nil {
+			log.Printf("Reservation sweep failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep settles the reservations due at now and returns how many became
+// no_show and completed.
+func (s *ReservationSweeper) Sweep(now time.Time) (noShows, completed int64, err error) {
+	noShows, err = s.reservations.MarkNoShows(now.Add(-s.cfg.NoShowGrace))
+	if err != nil {
+		return 0, 0, err
+	}
+	completed, err = s.reservations.CompleteReservations(now)
+	if err != nil {
+		return noShows, 0, err
+	}
+	return noShows, completed, nil
+}
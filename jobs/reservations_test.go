@@ -0,0 +1,82 @@
+// jobs/reservations_test.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSweepStore records the cutoffs it is swept with.
+type fakeSweepStore struct {
+	repository.ReservationStore
+	mu        sync.Mutex
+	noShowAt  []time.Time
+	completed []time.Time
+	err       error
+}
+
+func (f *fakeSweepStore) MarkNoShows(cutoff time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.noShowAt = append(f.noShowAt, cutoff)
+	return 2, f.err
+}
+
+func (f *fakeSweepStore) CompleteReservations(now time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed = append(f.completed, now)
+	return 1, nil
+}
+
+func (f *fakeSweepStore) sweeps() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.noShowAt)
+}
+
+func TestSweepUsesGracePeriod(t *testing.T) {
+	store := &fakeSweepStore{}
+	sweeper := NewReservationSweeper(store, config.ReservationConfig{NoShowGrace: 15 * time.Minute, SweepInterval: time.Minute})
+	now := time.Date(2024, 10, 10, 14, 0, 0, 0, time.UTC)
+
+	noShows, completed, err := sweeper.Sweep(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), noShows)
+	assert.Equal(t, int64(1), completed)
+	assert.Equal(t, []time.Time{now.Add(-15 * time.Minute)}, store.noShowAt)
+	assert.Equal(t, []time.Time{now}, store.completed)
+
+	store.err = errors.New("database is down")
+	_, _, err = sweeper.Sweep(now)
+	assert.EqualError(t, err, "database is down")
+	assert.Len(t, store.completed, 1)
+}
+
+func TestRunSweepsUntilCancelled(t *testing.T) {
+	store := &fakeSweepStore{}
+	sweeper := NewReservationSweeper(store, config.ReservationConfig{NoShowGrace: time.Minute, SweepInterval: 5 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sweeper.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return store.sweeps() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop")
+	}
+}
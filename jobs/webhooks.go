@@ -0,0 +1,140 @@
+// jobs/webhooks.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+	"interview_YangYang_20241010/webhook"
+)
+
+const (
+	// webhookBatchSize caps how many deliveries one dispatch sends at once.
+	webhookBatchSize = 20
+	// webhookLeaseMargin is added to the attempt timeout when leasing a
+	// delivery, so the lease outlasts the attempt.
+	webhookLeaseMargin = time.Minute
+	// maxWebhookErrorLength caps the response excerpt kept on a failure.
+	maxWebhookErrorLength = 512
+)
+
+// WebhookDispatcher periodically sends the queued webhook deliveries that
+// are due. A delivery answered with a 2xx succeeds; any other outcome is
+// retried with exponential backoff until MaxAttempts, when the delivery is
+// given up as failed.
+type WebhookDispatcher struct {
+	webhooks repository.WebhookStore
+	cfg      config.WebhookConfig
+	client   *http.Client
+}
+
+// NewWebhookDispatcher creates a dispatcher using the given store and settings.
+func NewWebhookDispatcher(webhooks repository.WebhookStore, cfg config.WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{webhooks: webhooks, cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Run dispatches right away and then every DispatchInterval until ctx is
+// cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.DispatchInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx, time.Now().UTC()); err != nil {
+			log.Printf("Webhook dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends the deliveries due by now, a batch at a time until none
+// is left, and returns how many were attempted. now only picks the due
+// deliveries; each attempt is signed and timed when it is made, since a
+// slow batch can run well past now.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	attempted := 0
+	for ctx.Err() == nil {
+		due, err := d.webhooks.ClaimDueWebhookDeliveries(now, d.cfg.Timeout+webhookLeaseMargin, webhookBatchSize)
+		if err != nil {
+			return attempted, err
+		}
+		if len(due) == 0 {
+			break
+		}
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+		attempted += len(due)
+	}
+	return attempted, nil
+}
+
+// attempt sends one delivery and records the outcome.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	hook, err := d.webhooks.GetWebhookByID(delivery.WebhookID)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		return // Deleted along with its deliveries
+	}
+	if err != nil {
+		log.Printf("Webhook %d could not be loaded for delivery %d: %v", delivery.WebhookID, delivery.ID, err)
+		return // Retried once the lease is over
+	}
+
+	attemptedAt := time.Now().UTC()
+	status, err := d.send(ctx, hook, delivery, attemptedAt)
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.ResponseStatus = status
+	delivery.LastError = ""
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryStatusSucceeded
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+		log.Printf("Webhook delivery %d to %s failed for good after %d attempts: %v", delivery.ID, hook.URL, delivery.Attempts, err)
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = attemptedAt.Add(webhook.Backoff(delivery.Attempts, d.cfg.BackoffBase, d.cfg.BackoffMax))
+	}
+	if err := d.webhooks.UpdateWebhookDelivery(delivery); err != nil {
+		log.Printf("Webhook delivery %d could not be updated: %v", delivery.ID, err)
+	}
+}
+
+// send posts the delivery signed at sentAt and returns the response status,
+// failing unless it is a 2xx.
+func (d *WebhookDispatcher) send(ctx context.Context, hook *models.Webhook, delivery models.WebhookDelivery, sentAt time.Time) (int, error) {
+	req, err := webhook.NewRequest(ctx, hook.URL, hook.Secret, delivery.Event, delivery.ID, []byte(delivery.Payload), sentAt)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLength))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s: %s", resp.Status, excerpt)
+	}
+	return resp.StatusCode, nil
+}
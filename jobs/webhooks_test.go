@@ -0,0 +1,176 @@
+// jobs/webhooks_test.go
+package jobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+	"interview_YangYang_20241010/repository"
+	"interview_YangYang_20241010/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhookStore holds webhooks and their deliveries in memory.
+type fakeWebhookStore struct {
+	repository.WebhookStore
+	mu         sync.Mutex
+	webhooks   map[uint]models.Webhook
+	deliveries map[uint]models.WebhookDelivery
+}
+
+func (f *fakeWebhookStore) GetWebhookByID(id uint) (*models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hook, ok := f.webhooks[id]
+	if !ok {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return &hook, nil
+}
+
+func (f *fakeWebhookStore) ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []models.WebhookDelivery
+	for id, delivery := range f.deliveries {
+		if len(due) < limit && delivery.Status == models.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+			delivery.NextAttemptAt = now.Add(lease)
+			f.deliveries[id] = delivery
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeWebhookStore) UpdateWebhookDelivery(delivery models.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func newWebhookTest(url string, now time.Time) *fakeWebhookStore {
+	return &fakeWebhookStore{
+		webhooks: map[uint]models.Webhook{1: {ID: 1, URL: url, Secret: "whsec"}},
+		deliveries: map[uint]models.WebhookDelivery{7: {
+			ID: 7, WebhookID: 1, EventID: "payment.succeeded:3", Event: models.EventPaymentSucceeded,
+			Payload: `{"id":"payment.succeeded:3"}`, Status: models.DeliveryStatusPending, NextAttemptAt: now,
+		}},
+	}
+}
+
+var webhookTestConfig = config.WebhookConfig{
+	DispatchInterval: time.Second, Timeout: time.Second, MaxAttempts: 3, BackoffBase: 10 * time.Second, BackoffMax: time.Hour,
+}
+
+func TestDispatchSendsSignedDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	store := newWebhookTest(receiver.URL, now)
+
+	attempted, err := NewWebhookDispatcher(store, webhookTestConfig).DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	if assert.NotNil(t, received) {
+		assert.Equal(t, models.EventPaymentSucceeded, received.Header.Get(webhook.EventHeader))
+		assert.Equal(t, "7", received.Header.Get(webhook.DeliveryHeader))
+		assert.Equal(t, `{"id":"payment.succeeded:3"}`, string(body))
+		assert.NoError(t, webhook.Verify("whsec", received.Header.Get(webhook.SignatureHeader), body, now, time.Minute))
+	}
+	delivery := store.deliveries[7]
+	assert.Equal(t, models.DeliveryStatusSucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Empty(t, delivery.LastError)
+}
+
+func TestDispatchRetriesWithBackoffUntilGivingUp(t *testing.T) {
+	now := time.Now().UTC()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	store := newWebhookTest(receiver.URL, now)
+	dispatcher := NewWebhookDispatcher(store, webhookTestConfig)
+
+	_, err := dispatcher.DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+	delivery := store.deliveries[7]
+	assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "down for maintenance")
+	first := *delivery.LastAttemptAt
+	assert.Equal(t, first.Add(10*time.Second), delivery.NextAttemptAt)
+
+	// Not due again before the backoff is over
+	attempted, err := dispatcher.DispatchDue(context.Background(), first.Add(5*time.Second))
+	assert.NoError(t, err)
+	assert.Zero(t, attempted)
+
+	_, err = dispatcher.DispatchDue(context.Background(), first.Add(10*time.Second))
+	assert.NoError(t, err)
+	delivery = store.deliveries[7]
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, delivery.LastAttemptAt.Add(20*time.Second), delivery.NextAttemptAt)
+
+	_, err = dispatcher.DispatchDue(context.Background(), delivery.NextAttemptAt)
+	assert.NoError(t, err)
+	delivery = store.deliveries[7]
+	assert.Equal(t, models.DeliveryStatusFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+}
+
+func TestDispatchTimesEachAttempt(t *testing.T) {
+	var signature string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook.SignatureHeader)
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	// The tick that picked the delivery is long past by the time it is sent
+	tick := time.Now().UTC().Add(-time.Hour)
+	store := newWebhookTest(receiver.URL, tick)
+
+	before := time.Now().UTC()
+	_, err := NewWebhookDispatcher(store, webhookTestConfig).DispatchDue(context.Background(), tick)
+	assert.NoError(t, err)
+	delivery := store.deliveries[7]
+	if assert.NotNil(t, delivery.LastAttemptAt) {
+		assert.WithinRange(t, *delivery.LastAttemptAt, before, time.Now().UTC())
+		assert.Equal(t, delivery.LastAttemptAt.Add(10*time.Second), delivery.NextAttemptAt)
+	}
+	body := []byte(delivery.Payload)
+	assert.NoError(t, webhook.Verify("whsec", signature, body, time.Now(), 5*time.Second))
+	assert.Error(t, webhook.Verify("whsec", signature, body, tick, 5*time.Second))
+}
+
+func TestDispatchRecordsUnreachableReceivers(t *testing.T) {
+	now := time.Now().UTC()
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+	store := newWebhookTest(receiver.URL, now)
+
+	_, err := NewWebhookDispatcher(store, webhookTestConfig).DispatchDue(context.Background(), now)
+	assert.NoError(t, err)
+	delivery := store.deliveries[7]
+	assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+	assert.Zero(t, delivery.ResponseStatus)
+	assert.NotEmpty(t, delivery.LastError)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/config"
+)
+
+// errDoubleEntries is returned when the load test finds a player who got
+// into the endless challenge more than once within the cooldown.
+var errDoubleEntries = errors.New("players entered more than once within the cooldown")
+
+// loadTest drives a running server over HTTP.
+type loadTest struct {
+	client  *http.Client
+	baseURL string
+}
+
+// entryResult is the outcome of one challenge entry request.
+type entryResult struct {
+	playerID string
+	status   int
+	latency  time.Duration
+	err      error
+}
+
+// runLoadTest implements the `loadtest` subcommand. It creates players,
+// funds their wallets so each could afford several entries, fires a burst
+// of concurrent entries per player at once and then checks through the
+// player history that nobody got in more than once. Run it against a
+// server whose challenge cooldown outlasts the test.
+func runLoadTest(cfg *config.Config, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	flags.SetOutput(out)
+	baseURL := flags.String("url", "http://localhost"+cfg.Server.Addr, "base URL of the running server")
+	players := flags.Int("players", 10, "players to create")
+	burst := flags.Int("burst", 50, "concurrent entries fired per player")
+	entries := flags.Int("fund-entries", 3, "entry fees to deposit into each wallet")
+	fundTimeout := flags.Duration("fund-timeout", 30*time.Second, "how long to wait for deposits to settle")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *players <= 0 || *burst <= 0 || *entries < 2 {
+		return errors.New("players and burst must be positive and fund-entries at least 2")
+	}
+
+	lt := &loadTest{client: &http.Client{Timeout: 30 * time.Second}, baseURL: strings.TrimRight(*baseURL, "/")}
+	deposit := min(cfg.Challenge.EntryFee*int64(*entries), cfg.Payment.MaxAmount)
+
+	fmt.Fprintf(out, "creating and funding %d players with %d cents each\n", *players, deposit)
+	run := fmt.Sprintf("loadtest-%d", time.Now().Unix())
+	levelID, err := lt.create("/levels", fmt.Sprintf(`{"name": %q}`, run))
+	if err != nil {
+		return err
+	}
+	ids := make([]string, *players)
+	for i := range ids {
+		id, err := lt.create("/players", fmt.Sprintf(`{"name": "%s-%d", "level_id": %q}`, run, i, levelID))
+		if err != nil {
+			return err
+		}
+		if err := lt.deposit(id, deposit); err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *fundTimeout)
+	defer cancel()
+	for _, id := range ids {
+		if err := lt.awaitBalance(ctx, id, deposit); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "firing %d concurrent entries per player (%d requests)\n", *burst, *players**burst)
+	started := time.Now()
+	results := lt.burst(ids, *burst)
+	elapsed := time.Since(started)
+
+	report(out, results, elapsed)
+
+	var doubled []string
+	for _, id := range ids {
+		total, err := lt.entryCount(id)
+		if err != nil {
+			return err
+		}
+		if total > 1 {
+			doubled = append(doubled, fmt.Sprintf("%s (%d entries)", id, total))
+		}
+	}
+	if elapsed >= cfg.Challenge.Cooldown {
+		fmt.Fprintf(out, "warning: the burst took %s, longer than the %s cooldown\n", elapsed.Round(time.Millisecond), cfg.Challenge.Cooldown)
+	}
+	if len(doubled) > 0 {
+		return fmt.Errorf("%w: %s", errDoubleEntries, strings.Join(doubled, ", "))
+	}
+	fmt.Fprintln(out, "no player entered more than once")
+	return nil
+}
+
+// burst fires count entries per player at once and collects the results.
+func (lt *loadTest) burst(ids []string, count int) []entryResult {
+	results := make([]entryResult, 0, len(ids)*count)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, id := range ids {
+		body := fmt.Sprintf(`{"player_id": %s}`, id)
+		for range count {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				sent := time.Now()
+				status, err := lt.post("/challenges", body, nil)
+				mu.Lock()
+				results = append(results, entryResult{playerID: id, status: status, latency: time.Since(sent), err: err})
+				mu.Unlock()
+			}()
+		}
+	}
+	close(start)
+	wg.Wait()
+	return results
+}
+
+// report prints the status codes and latency percentiles of a burst.
+func report(out io.Writer, results []entryResult, elapsed time.Duration) {
+	statuses := map[int]int{}
+	failures := 0
+	latencies := make([]time.Duration, 0, len(results))
+	for _, r := range results {
+		if r.err != nil {
+			failures++
+			continue
+		}
+		statuses[r.status]++
+		latencies = append(latencies, r.latency)
+	}
+	slices.Sort(latencies)
+	percentile := func(p float64) time.Duration {
+		if len(latencies) == 0 {
+			return 0
+		}
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+
+	fmt.Fprintf(out, "%d requests in %s (%.0f/s)\n", len(results), elapsed.Round(time.Millisecond), float64(len(results))/elapsed.Seconds())
+	codes := make([]int, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	for _, code := range codes {
+		fmt.Fprintf(out, "  %d %s: %d\n", code, http.StatusText(code), statuses[code])
+	}
+	if failures > 0 {
+		fmt.Fprintf(out, "  transport errors: %d\n", failures)
+	}
+	fmt.Fprintf(out, "latency p50 %s, p95 %s, p99 %s\n",
+		percentile(0.50).Round(time.Microsecond), percentile(0.95).Round(time.Microsecond), percentile(0.99).Round(time.Microsecond))
+}
+
+// create posts a resource and returns the ID it was created with.
+func (lt *loadTest) create(path, body string) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	status, err := lt.post(path, body, &created)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusCreated {
+		return "", fmt.Errorf("POST %s: unexpected status %d", path, status)
+	}
+	return created.ID, nil
+}
+
+func (lt *loadTest) deposit(playerID string, amount int64) error {
+	body := fmt.Sprintf(`{"player_id": %s, "method": "ThirdParty", "amount": %d, "details": {}}`, playerID, amount)
+	status, err := lt.post("/payments", body, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusAccepted {
+		return fmt.Errorf("deposit for player %s: unexpected status %d", playerID, status)
+	}
+	return nil
+}
+
+// awaitBalance polls the player's wallet until it holds at least cents.
+func (lt *loadTest) awaitBalance(ctx context.Context, playerID string, cents int64) error {
+	for {
+		var wallet struct {
+			Balance int64 `json:"balance"`
+		}
+		if err := lt.get("/players/"+playerID+"/wallet", &wallet); err != nil {
+			return err
+		}
+		if wallet.Balance >= cents {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wallet of player %s holds %d cents, waiting for %d: %w", playerID, wallet.Balance, cents, ctx.Err())
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// entryCount returns how many challenges the player has entered.
+func (lt *loadTest) entryCount(playerID string) (int64, error) {
+	var page struct {
+		Total int64 `json:"total"`
+	}
+	if err := lt.get("/players/"+playerID+"/challenges?limit=1", &page); err != nil {
+		return 0, err
+	}
+	return page.Total, nil
+}
+
+// post sends body as JSON and decodes the response into dst when given.
+func (lt *loadTest) post(path, body string, dst any) (int, error) {
+	resp, err := lt.client.Post(lt.baseURL+path, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if dst != nil {
+		if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+			return resp.StatusCode, fmt.Errorf("POST %s: %w", path, err)
+		}
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+	return resp.StatusCode, nil
+}
+
+// get decodes the JSON response of a successful GET into dst.
+func (lt *loadTest) get(path string, dst any) error {
+	resp, err := lt.client.Get(lt.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -1,8 +1,21 @@
 package main
 
 import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    _ "time/tzdata" // reservation time zones must resolve on hosts without zoneinfo
+
     "github.com/gin-gonic/gin"
+    "interview_YangYang_20241010/config"
+    "interview_YangYang_20241010/gateway"
     "interview_YangYang_20241010/handlers"
+    "interview_YangYang_20241010/jobs"
+    "interview_YangYang_20241010/realtime"
     "interview_YangYang_20241010/repository"
     _ "interview_YangYang_20241010/docs"
 
@@ -11,68 +24,224 @@ import (
 )
 
 func main() {
-    // init db
-    repository.InitDB()
+    // load configuration
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatalf("Failed to load configuration: %v", err)
+    }
+    log.Printf("Loaded configuration:\n%s", cfg)
+
+    // run the migrate subcommand instead of the server when requested
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(cfg, os.Args[2:], os.Stdout); err != nil {
+            log.Fatalf("Migration failed: %v", err)
+        }
+        return
+    }
+
+    // or the load test against a running server
+    if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+        if err := runLoadTest(cfg, os.Args[2:], os.Stdout); err != nil {
+            log.Fatalf("Load test failed: %v", err)
+        }
+        return
+    }
+
+    // or local stub payment providers
+    if len(os.Args) > 1 && os.Args[1] == "stubgateway" {
+        if err := runStubGateway(cfg, os.Args[2:], os.Stdout); err != nil {
+            log.Fatalf("Stub gateway failed: %v", err)
+        }
+        return
+    }
+
+    // init db and the stores backed by it
+    store := repository.NewGormStore(repository.InitDB(cfg.Database))
+
+    // payment providers by method
+    gateways, err := gateway.NewRegistryFromConfig(cfg.Payment)
+    if err != nil {
+        log.Fatalf("Failed to set up payment gateways: %v", err)
+    }
+
+    // hub broadcasting room events to WebSocket clients
+    hub := realtime.NewHub(cfg.Realtime)
+
+    playerHandler := handlers.NewPlayerHandler(store, store)
+    levelHandler := handlers.NewLevelHandler(store)
+    roomHandler := handlers.NewRoomHandler(store, hub)
+    reservationHandler := handlers.NewReservationHandler(store, store, cfg.Reservation)
+    challengeHandler := handlers.NewChallengeHandler(store, store, store, cfg.Challenge)
+    challengeDefinitionHandler := handlers.NewChallengeDefinitionHandler(store)
+    logHandler := handlers.NewLogHandler(store)
+    paymentHandler := handlers.NewPaymentHandler(store, cfg.Payment)
+    matchHandler := handlers.NewMatchHandler(store, store, store, hub)
+    roomSocketHandler := handlers.NewRoomSocketHandler(store, store, hub)
+    availabilityHandler := handlers.NewAvailabilityHandler(store, store)
+    walletHandler := handlers.NewWalletHandler(store, store)
+    webhookHandler := handlers.NewWebhookHandler(store)
+    idempotency := handlers.NewIdempotency(store, cfg.Idempotency)
 
     router := gin.Default()
 
-    router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+    if cfg.Features.Swagger {
+        router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+    }
 
     // set player management route
     players := router.Group("/players")
     {
-        players.GET("", handlers.GetPlayers)
-        players.POST("", handlers.CreatePlayer)
-        players.GET("/:id", handlers.GetPlayerByID)
-        players.PUT("/:id", handlers.UpdatePlayer)
-        players.DELETE("/:id", handlers.DeletePlayer)
+        players.GET("", playerHandler.GetPlayers)
+        players.POST("", playerHandler.CreatePlayer)
+        players.GET("/:id", playerHandler.GetPlayerByID)
+        players.PUT("/:id", playerHandler.UpdatePlayer)
+        players.DELETE("/:id", playerHandler.DeletePlayer)
+        players.GET("/:id/reservations", reservationHandler.GetPlayerReservations)
+        players.GET("/:id/wallet", walletHandler.GetWallet)
+        players.GET("/:id/ledger", walletHandler.GetLedger)
+        if cfg.Features.Challenges {
+            players.GET("/:id/challenges", challengeHandler.GetPlayerChallenges)
+            players.GET("/:id/challenges/stats", challengeHandler.GetPlayerChallengeStats)
+            players.GET("/:id/seed", challengeHandler.GetPlayerSeed)
+        }
     }
 
     // Set up level management routes
     levels := router.Group("/levels")
     {
-        levels.GET("", handlers.GetLevels)
-        levels.POST("", handlers.CreateLevel)
+        levels.GET("", levelHandler.GetLevels)
+        levels.POST("", levelHandler.CreateLevel)
     }
 
     // Set up room management routes
     rooms := router.Group("/rooms")
     {
-        rooms.GET("", handlers.GetRooms)
-        rooms.POST("", handlers.CreateRoom)
-        rooms.GET("/:id", handlers.GetRoomByID)
-        rooms.PUT("/:id", handlers.UpdateRoom)
-        rooms.DELETE("/:id", handlers.DeleteRoom)
+        rooms.GET("", roomHandler.GetRooms)
+        rooms.POST("", roomHandler.CreateRoom)
+        rooms.GET("/availability", availabilityHandler.GetAvailability)
+        rooms.GET("/:id", roomHandler.GetRoomByID)
+        rooms.PUT("/:id", roomHandler.UpdateRoom)
+        rooms.DELETE("/:id", roomHandler.DeleteRoom)
+        rooms.POST("/:id/join", roomHandler.JoinRoom)
+        rooms.POST("/:id/leave", roomHandler.LeaveRoom)
+        rooms.GET("/:id/ws", roomSocketHandler.ServeRoom)
     }
 
     // Set up reservation management routes
     reservations := router.Group("/reservations")
     {
-        reservations.GET("", handlers.GetReservations)
-        reservations.POST("", handlers.CreateReservation)
+        reservations.GET("", reservationHandler.GetReservations)
+        reservations.POST("", reservationHandler.CreateReservation)
+        reservations.GET("/:id", reservationHandler.GetReservationByID)
+        reservations.PUT("/:id", reservationHandler.UpdateReservation)
+        reservations.DELETE("/:id", reservationHandler.CancelReservation)
+        reservations.POST("/:id/check-in", reservationHandler.CheckInReservation)
     }
 
     // Set up challenge management routes (new)
-    challenges := router.Group("/challenges")
-    {
-        challenges.POST("", handlers.ParticipateChallenge)
-        challenges.GET("/results", handlers.GetChallengeResults)
+    if cfg.Features.Challenges {
+        challenges := router.Group("/challenges")
+        {
+            challenges.POST("", idempotency.Middleware(), challengeHandler.ParticipateChallenge)
+            challenges.GET("/results", challengeHandler.GetChallengeResults)
+            challenges.GET("/jackpot", challengeHandler.GetJackpot)
+            challenges.GET("/jackpot/payouts", challengeHandler.GetJackpotPayouts)
+            challenges.GET("/:id", challengeHandler.GetChallenge)
+            challenges.GET("/:id/verify", challengeHandler.VerifyChallenge)
+        }
+
+        definitions := router.Group("/challenge-definitions")
+        {
+            definitions.GET("", challengeDefinitionHandler.GetChallengeDefinitions)
+            definitions.POST("", challengeDefinitionHandler.CreateChallengeDefinition)
+            definitions.GET("/:id", challengeDefinitionHandler.GetChallengeDefinition)
+            definitions.PUT("/:id", challengeDefinitionHandler.UpdateChallengeDefinition)
+            definitions.DELETE("/:id", challengeDefinitionHandler.DeleteChallengeDefinition)
+        }
     }
 
 	// Set up log management routes (new)
 	logs := router.Group("/logs")
 	{
-		logs.GET("", handlers.GetLogs)
-		logs.POST("", handlers.CreateLog)
+		logs.GET("", logHandler.GetLogs)
+		logs.POST("", logHandler.CreateLog)
 	}
 
 	// Set up payment management routes (new)
-	payments := router.Group("/payments")
+	if cfg.Features.Payments {
+		payments := router.Group("/payments")
+		{
+			payments.POST("", idempotency.Middleware(), paymentHandler.ProcessPayment)
+			payments.GET("/:id", paymentHandler.GetPaymentDetails)
+			payments.POST("/callbacks/:provider", paymentHandler.HandleProviderCallback)
+		}
+	}
+
+	// Set up webhook subscription routes
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.GET("", webhookHandler.GetWebhooks)
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.GET("/:id", webhookHandler.GetWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	}
+
+	// Set up OXO match routes
+	matches := router.Group("/matches")
 	{
-		payments.POST("", handlers.ProcessPayment)
-		payments.GET("/:id", handlers.GetPaymentDetails)
+		matches.POST("", matchHandler.CreateMatch)
+		matches.GET("/:id", matchHandler.GetMatch)
+		matches.POST("/:id/moves", matchHandler.SubmitMove)
 	}
 
-    // start server, listen 8080 port
-    router.Run(":8080")
+    // settle no-shows and finished reservations in the background
+    jobsCtx, stopJobs := context.WithCancel(context.Background())
+    defer stopJobs()
+    go jobs.NewReservationSweeper(store, cfg.Reservation).Run(jobsCtx)
+
+    // resolve challenges whose duration is over, including those left
+    // pending by a restart
+    if cfg.Features.Challenges {
+        go jobs.NewChallengeResolver(store, cfg.Challenge).Run(jobsCtx)
+    }
+
+    // charge accepted payments, including those left pending by a restart
+    if cfg.Features.Payments {
+        go jobs.NewPaymentProcessor(store, gateways, cfg.Payment).Run(jobsCtx)
+    }
+
+    // check that the ledger still adds up to the wallet balances
+    go jobs.NewLedgerAuditor(store, cfg.Payment).Run(jobsCtx)
+
+    // drop idempotency keys whose responses are no longer replayed
+    go jobs.NewIdempotencyPurger(store, cfg.Idempotency).Run(jobsCtx)
+
+    // send queued webhook deliveries, retrying failed ones with backoff
+    go jobs.NewWebhookDispatcher(store, cfg.Webhook).Run(jobsCtx)
+
+    // start server on the configured address
+    srv := &http.Server{
+        Addr:         cfg.Server.Addr,
+        Handler:      router,
+        ReadTimeout:  cfg.Server.ReadTimeout,
+        WriteTimeout: cfg.Server.WriteTimeout,
+    }
+    go func() {
+        if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            log.Fatalf("Failed to start server: %v", err)
+        }
+    }()
+
+    // wait for an interrupt, then shut down gracefully
+    quit := make(chan os.Signal, 1)
+    signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+    <-quit
+    stopJobs()
+
+    ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+    defer cancel()
+    if err := srv.Shutdown(ctx); err != nil {
+        log.Printf("Server forced to shutdown: %v", err)
+    }
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/migrations"
+	"interview_YangYang_20241010/repository"
+)
+
+const migrateUsage = "usage: migrate <up|down|status|to VERSION>"
+
+// runMigrate implements the `migrate` subcommand.
+func runMigrate(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := repository.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	migrator, err := migrations.New(db, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "applied %d migration(s)\n", count)
+	case "down":
+		if err := migrator.Down(); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.To(version); err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d  %-30s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "schema is at version %d of %d\n", version, migrator.Latest())
+	return nil
+}
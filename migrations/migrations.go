@@ -0,0 +1,302 @@
+// migrations/migrations.go
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// scripts holds one directory of migrations per database driver. Files are
+// named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var scripts embed.FS
+
+var (
+	ErrSchemaBehind   = errors.New("database schema is behind")
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// appliedMigration is a row of the schema_migrations table.
+type appliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Load reads the migrations bundled for the given driver, ordered by version.
+func Load(driver string) ([]Migration, error) {
+	files, err := fs.Glob(scripts, path.Join(driver, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migrations bundled for driver %q", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		base := path.Base(file)
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", base)
+		}
+
+		versionPart, name, ok := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s must be named <version>_<name>", base)
+		}
+		version, err := strconv.Atoi(versionPart)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s has an invalid version", base)
+		}
+
+		body, err := scripts.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down scripts", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and rolls back migrations, recording progress in the
+// schema_migrations table.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New returns a migrator for the migrations bundled for driver.
+func New(db *gorm.DB, driver string) (*Migrator, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the version of the newest bundled migration.
+func (m *Migrator) Latest() int {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the newest applied version, or 0 for an empty database.
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Status lists every bundled migration and whether it has been applied.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up() (int, error) {
+	return m.migrateTo(m.Latest())
+}
+
+// Down rolls back the newest applied migration.
+func (m *Migrator) Down() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+	target := 0
+	for _, mig := range m.migrations {
+		if mig.Version < current {
+			target = mig.Version
+		}
+	}
+	_, err = m.migrateTo(target)
+	return err
+}
+
+// To migrates up or down until version is the newest applied migration.
+// Version 0 rolls back everything.
+func (m *Migrator) To(version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	_, err := m.migrateTo(version)
+	return err
+}
+
+// Check returns ErrSchemaBehind when bundled migrations have not been applied.
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", mig.Version, mig.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// migrateTo applies pending migrations up to target and rolls back applied
+// migrations above it. Each migration runs in its own transaction.
+func (m *Migrator) migrateTo(target int) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok || mig.Version > target {
+			continue
+		}
+		if err := m.apply(mig); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= target {
+			continue
+		}
+		if err := m.revert(mig); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (m *Migrator) apply(mig Migration) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mig.Up).Error; err != nil {
+			return fmt.Errorf("apply migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		return tx.Create(&appliedMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now().UTC()}).Error
+	})
+}
+
+func (m *Migrator) revert(mig Migration) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mig.Down).Error; err != nil {
+			return fmt.Errorf("revert migration %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		return tx.Delete(&appliedMigration{}, "version = ?", mig.Version).Error
+	})
+}
+
+// applied returns the recorded migrations keyed by version, creating the
+// bookkeeping table on first use.
+func (m *Migrator) applied() (map[int]appliedMigration, error) {
+	err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []appliedMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+// migrations/migrations_test.go
+package migrations_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/migrations"
+	"interview_YangYang_20241010/repository"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func openEmptyDB(t *testing.T) *gorm.DB {
+	db, err := repository.Open(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "migrations.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestDriversShipTheSameMigrations(t *testing.T) {
+	postgres, err := migrations.Load(config.DriverPostgres)
+	assert.NoError(t, err)
+	sqlite, err := migrations.Load(config.DriverSQLite)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(postgres), len(sqlite))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, m.Check(), migrations.ErrSchemaBehind)
+
+	applied, err := m.Up()
+	assert.NoError(t, err)
+	assert.Positive(t, applied)
+	assert.NoError(t, m.Check())
+	assert.True(t, db.Migrator().HasTable("players"))
+
+	version, err := m.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, m.Latest(), version)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Applied, "migration %d not applied", s.Version)
+	}
+
+	// Running up again is a no-op
+	applied, err = m.Up()
+	assert.NoError(t, err)
+	assert.Zero(t, applied)
+
+	// Rolling back everything leaves only the bookkeeping table
+	assert.NoError(t, m.To(0))
+	assert.False(t, db.Migrator().HasTable("players"))
+	assert.ErrorIs(t, m.Check(), migrations.ErrSchemaBehind)
+}
+
+func TestDownRevertsOneStep(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+	assert.NoError(t, m.Down())
+
+	version, err := m.Version()
+	assert.NoError(t, err)
+	assert.Less(t, version, m.Latest())
+}
+
+func TestToRejectsUnknownVersion(t *testing.T) {
+	m, err := migrations.New(openEmptyDB(t), config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, m.To(9999), migrations.ErrUnknownVersion)
+}
+
+func TestReservationSlotsCarryOverLegacyTimes(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(3))
+
+	legacy := `INSERT INTO reservations (id, room_id, date, time, player_info) VALUES
+		(1, 1, '2024-10-10 00:00:00+00:00', '14:00-16:00', 'slot'),
+		(2, 1, '2024-10-10 00:00:00+00:00', '22:00-01:00', 'overnight'),
+		(3, 2, '2024-10-10 09:30:00+00:00', 'evening', 'free-form')`
+	assert.NoError(t, db.Exec(legacy).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	var slots []struct {
+		ID      uint
+		StartAt string
+		EndAt   string
+	}
+	assert.NoError(t, db.Raw("SELECT id, start_at || '' AS start_at, end_at || '' AS end_at FROM reservations ORDER BY id").Scan(&slots).Error)
+	if assert.Len(t, slots, 3) {
+		assert.Equal(t, "2024-10-10 14:00:00+00:00", slots[0].StartAt)
+		assert.Equal(t, "2024-10-10 16:00:00+00:00", slots[0].EndAt)
+		assert.Equal(t, "2024-10-11 01:00:00+00:00", slots[1].EndAt)
+		assert.Equal(t, "2024-10-10 09:30:00+00:00", slots[2].StartAt)
+		assert.Equal(t, "2024-10-10 10:30:00+00:00", slots[2].EndAt)
+	}
+
+	// And back again
+	assert.NoError(t, m.To(3))
+	var times []string
+	assert.NoError(t, db.Raw("SELECT time FROM reservations ORDER BY id").Scan(&times).Error)
+	assert.Equal(t, []string{"14:00-16:00", "22:00-01:00", "09:30-10:30"}, times)
+}
+
+func TestOverlappingLegacyReservationsAreCancelled(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(3))
+
+	legacy := `INSERT INTO reservations (id, room_id, date, time, player_info) VALUES
+		(1, 1, '2024-10-10 00:00:00+00:00', '14:00-16:00', 'first'),
+		(2, 1, '2024-10-10 00:00:00+00:00', '15:00-17:00', 'double booked'),
+		(3, 1, '2024-10-11 00:00:00+00:00', '15:00-17:00', 'next day'),
+		(4, 2, '2024-10-10 09:00:00+00:00', '09:00-10:00', 'morning'),
+		(5, 2, '2024-10-10 09:30:00+00:00', 'evening', 'free-form')`
+	assert.NoError(t, db.Exec(legacy).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	// The booking made first keeps its slot; the later ones are cancelled
+	var statuses []string
+	assert.NoError(t, db.Raw("SELECT status FROM reservations ORDER BY id").Scan(&statuses).Error)
+	assert.Equal(t, []string{"completed", "cancelled", "completed", "completed", "cancelled"}, statuses)
+	assert.False(t, db.Migrator().HasTable("reservations_overlapping"))
+
+	// And back again, with every booking
+	assert.NoError(t, m.To(3))
+	var times []string
+	assert.NoError(t, db.Raw("SELECT time FROM reservations ORDER BY id").Scan(&times).Error)
+	assert.Equal(t, []string{"14:00-16:00", "15:00-17:00", "15:00-17:00", "09:00-10:00", "09:30-10:30"}, times)
+	assert.False(t, db.Migrator().HasTable("reservations_overlapping"))
+}
+
+func TestReservationPlayerInfoBecomesHost(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(6))
+
+	assert.NoError(t, db.Exec(`INSERT INTO players (id, name) VALUES ('p1', 'Player 1')`).Error)
+	legacy := `INSERT INTO reservations (id, room_id, start_at, end_at, player_info) VALUES
+		(1, 1, '2024-10-10 14:00:00+00:00', '2024-10-10 15:00:00+00:00', 'p1'),
+		(2, 1, '2024-10-10 15:00:00+00:00', '2024-10-10 16:00:00+00:00', 'Alice and Bob')`
+	assert.NoError(t, db.Exec(legacy).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	var rows []struct {
+		HostID *string
+		Note   string
+	}
+	assert.NoError(t, db.Raw("SELECT host_id, note FROM reservations ORDER BY id").Scan(&rows).Error)
+	if assert.Len(t, rows, 2) {
+		if assert.NotNil(t, rows[0].HostID) {
+			assert.Equal(t, "p1", *rows[0].HostID)
+		}
+		assert.Nil(t, rows[1].HostID)
+		assert.Equal(t, "Alice and Bob", rows[1].Note)
+	}
+
+	// And back again
+	assert.NoError(t, m.To(6))
+	var info []string
+	assert.NoError(t, db.Raw("SELECT player_info FROM reservations ORDER BY id").Scan(&info).Error)
+	assert.Equal(t, []string{"p1", "Alice and Bob"}, info)
+}
+
+func TestExistingChallengesStayResolved(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(8))
+
+	assert.NoError(t, db.Exec(`INSERT INTO challenges (id, player_id, amount, won, created_at) VALUES
+		(1, 1, 20.01, true, '2024-10-10 14:00:00+00:00')`).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	var row struct {
+		Status    string
+		ResolveAt string
+	}
+	assert.NoError(t, db.Raw("SELECT status, resolve_at || '' AS resolve_at FROM challenges").Scan(&row).Error)
+	assert.Equal(t, "resolved", row.Status)
+	assert.Equal(t, "2024-10-10 14:00:00+00:00", row.ResolveAt)
+}
+
+func TestLedgerOpensWithExistingBalances(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(9))
+
+	legacy := `INSERT INTO payments (player_id, method, amount, details, status) VALUES
+		(1, 'CreditCard', 20.01, '{}', 'Success'),
+		(1, 'CreditCard', 5, '{}', 'Success'),
+		(2, 'CreditCard', 7.5, '{}', 'Failed')`
+	assert.NoError(t, db.Exec(legacy).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO jackpots (id, amount, entries) VALUES (1, 118.01, 1)`).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	var balance int64
+	assert.NoError(t, db.Raw("SELECT balance FROM wallets WHERE player_id = 1").Scan(&balance).Error)
+	assert.Equal(t, int64(2501), balance)
+
+	var accounts []struct {
+		Account string
+		Total   int64
+	}
+	assert.NoError(t, db.Raw("SELECT account, SUM(amount) AS total FROM ledger_entries GROUP BY account ORDER BY account").Scan(&accounts).Error)
+	assert.Equal(t, []struct {
+		Account string
+		Total   int64
+	}{{"external", -2501}, {"house", -11801}, {"jackpot", 11801}, {"player:1", 2501}}, accounts)
+
+	// And back to balances in currency units
+	assert.NoError(t, m.To(10))
+	var units float64
+	assert.NoError(t, db.Raw("SELECT balance FROM wallets WHERE player_id = 1").Scan(&units).Error)
+	assert.Equal(t, 25.01, units)
+}
+
+func TestCooldownsCarryOverTheLastEntries(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(14))
+
+	assert.NoError(t, db.Exec(`INSERT INTO challenge_definitions (id, name, entry_fee, duration_seconds, cooldown_seconds, odds_model)
+		VALUES (1, 'weekend', 5, 60, 60, 'flat')`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO challenges (player_id, amount, definition_id, created_at) VALUES
+		(1, 20.01, NULL, '2024-10-10 14:00:00+00:00'),
+		(1, 20.01, NULL, '2024-10-10 15:00:00+00:00'),
+		(1, 5, 1, '2024-10-10 14:30:00+00:00')`).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	var rows []struct {
+		Scope     string
+		EnteredAt string
+	}
+	assert.NoError(t, db.Raw("SELECT scope, entered_at || '' AS entered_at FROM challenge_cooldowns WHERE player_id = 1 ORDER BY scope").Scan(&rows).Error)
+	assert.Equal(t, []struct {
+		Scope     string
+		EnteredAt string
+	}{{"definition:1", "2024-10-10 14:30:00+00:00"}, {"endless", "2024-10-10 15:00:00+00:00"}}, rows)
+}
+
+func TestAmountsMoveToCents(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(19))
+
+	assert.NoError(t, db.Exec(`INSERT INTO payments (player_id, method, amount, details, status) VALUES (1, 'CreditCard', 20.01, '{}', 'Pending')`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO challenges (id, player_id, amount, resolve_at) VALUES (1, 1, 20.01, '2024-10-10 14:00:00+00:00')`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO challenge_definitions (id, name, entry_fee, duration_seconds, cooldown_seconds, odds_model)
+		VALUES (1, 'weekend', 2.5, 60, 60, 'flat')`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO jackpots (id, amount, entries) VALUES (1, 118.01, 1)`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO jackpot_payouts (challenge_id, player_id, amount) VALUES (1, 1, 0.29)`).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	queries := []string{
+		"SELECT amount FROM payments", "SELECT amount FROM challenges", "SELECT entry_fee FROM challenge_definitions",
+		"SELECT amount FROM jackpots", "SELECT amount FROM jackpot_payouts",
+	}
+	var cents []int64
+	for _, query := range queries {
+		var amount int64
+		assert.NoError(t, db.Raw(query).Scan(&amount).Error)
+		cents = append(cents, amount)
+	}
+	assert.Equal(t, []int64{2001, 2001, 250, 11801, 29}, cents)
+	var columnType string
+	assert.NoError(t, db.Raw("SELECT type FROM pragma_table_info('payments') WHERE name = 'amount'").Scan(&columnType).Error)
+	assert.Equal(t, "BIGINT", columnType)
+
+	// And back to currency units
+	assert.NoError(t, m.To(19))
+	var units []float64
+	for _, query := range queries {
+		var amount float64
+		assert.NoError(t, db.Raw(query).Scan(&amount).Error)
+		units = append(units, amount)
+	}
+	assert.Equal(t, []float64{20.01, 20.01, 2.5, 118.01, 0.29}, units)
+}
+
+// The schema InitDB used to create with AutoMigrate before there were
+// versioned migrations.
+type (
+	legacyLevel struct {
+		ID   string `gorm:"primaryKey"`
+		Name string `gorm:"unique"`
+	}
+	legacyPlayer struct {
+		ID      string `gorm:"primaryKey"`
+		Name    string
+		LevelID string
+	}
+	legacyRoom struct {
+		ID          uint `gorm:"primaryKey"`
+		Name        string
+		Description string
+		Status      string
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+	legacyReservation struct {
+		ID         uint `gorm:"primaryKey"`
+		RoomID     uint `gorm:"not null"`
+		Date       time.Time
+		Time       string
+		PlayerInfo string
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+	}
+)
+
+func (legacyLevel) TableName() string       { return "levels" }
+func (legacyPlayer) TableName() string      { return "players" }
+func (legacyRoom) TableName() string        { return "rooms" }
+func (legacyReservation) TableName() string { return "reservations" }
+
+func TestUpAdoptsAutoMigratedDatabase(t *testing.T) {
+	db := openEmptyDB(t)
+	assert.NoError(t, db.AutoMigrate(&legacyPlayer{}, &legacyLevel{}, &legacyRoom{}, &legacyReservation{}))
+	assert.NoError(t, db.Create(&legacyLevel{ID: "l1", Name: "Beginner"}).Error)
+	assert.NoError(t, db.Create(&legacyPlayer{ID: "p1", Name: "Player 1", LevelID: "l1"}).Error)
+	assert.NoError(t, db.Create(&legacyRoom{ID: 1, Name: "Room 1", Status: "open"}).Error)
+	assert.NoError(t, db.Create(&legacyReservation{
+		ID: 1, RoomID: 1, Date: time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC), Time: "14:00-16:00", PlayerInfo: "p1",
+	}).Error)
+
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	_, err = m.Up()
+	assert.NoError(t, err)
+	assert.NoError(t, m.Check())
+
+	var names []string
+	assert.NoError(t, db.Raw("SELECT name FROM players").Scan(&names).Error)
+	assert.Equal(t, []string{"Player 1"}, names)
+	var reservation struct {
+		RoomID  uint
+		HostID  string
+		StartAt string
+	}
+	assert.NoError(t, db.Raw("SELECT room_id, host_id, start_at || '' AS start_at FROM reservations").Scan(&reservation).Error)
+	assert.Equal(t, uint(1), reservation.RoomID)
+	assert.Equal(t, "p1", reservation.HostID)
+	assert.Equal(t, "2024-10-10 14:00:00+00:00", reservation.StartAt)
+}
+
+func TestPendingPaymentsAreScheduledForProcessing(t *testing.T) {
+	db := openEmptyDB(t)
+	m, err := migrations.New(db, config.DriverSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.To(23))
+
+	legacy := `INSERT INTO payments (id, player_id, method, amount, status, transaction_id, created_at) VALUES
+		(1, 1, 'CreditCard', 1000, 'Pending', NULL, '2024-10-10 12:00:00+00:00'),
+		(2, 1, 'BankTransfer', 1000, 'Pending', 'tx_1', '2024-10-10 12:00:00+00:00'),
+		(3, 1, 'CreditCard', 1000, 'Success', 'tx_2', '2024-10-10 12:00:00+00:00')`
+	assert.NoError(t, db.Exec(legacy).Error)
+
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	// Only the payment never sent to its provider is charged after the upgrade
+	var due []uint
+	assert.NoError(t, db.Raw("SELECT id FROM payments WHERE process_at IS NOT NULL ORDER BY id").Scan(&due).Error)
+	assert.Equal(t, []uint{1}, due)
+
+	assert.NoError(t, m.To(23))
+}
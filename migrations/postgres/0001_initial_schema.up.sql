@@ -0,0 +1,65 @@
+-- Databases set up before versioned migrations already have levels, players,
+-- rooms and reservations from AutoMigrate, with the same columns; those
+-- tables are adopted as they are rather than created.
+
+CREATE TABLE IF NOT EXISTS levels (
+    id   TEXT PRIMARY KEY,
+    name TEXT UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS players (
+    id       TEXT PRIMARY KEY,
+    name     TEXT,
+    level_id TEXT
+);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT,
+    description TEXT,
+    status      TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id          BIGSERIAL PRIMARY KEY,
+    room_id     BIGINT NOT NULL,
+    date        TIMESTAMPTZ,
+    time        TEXT,
+    player_info TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_room_id ON reservations (room_id);
+
+CREATE TABLE IF NOT EXISTS challenges (
+    id         BIGSERIAL PRIMARY KEY,
+    player_id  BIGINT NOT NULL,
+    amount     NUMERIC(12, 2) NOT NULL,
+    won        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_challenges_player_created ON challenges (player_id, created_at);
+
+CREATE TABLE IF NOT EXISTS logs (
+    id        BIGSERIAL PRIMARY KEY,
+    player_id BIGINT NOT NULL,
+    action    TEXT NOT NULL,
+    timestamp TIMESTAMPTZ,
+    details   TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_logs_player_timestamp ON logs (player_id, timestamp);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id             BIGSERIAL PRIMARY KEY,
+    player_id      BIGINT NOT NULL,
+    method         TEXT NOT NULL,
+    amount         NUMERIC(12, 2) NOT NULL,
+    details        TEXT,
+    status         TEXT NOT NULL,
+    transaction_id TEXT,
+    error_message  TEXT,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ
+);
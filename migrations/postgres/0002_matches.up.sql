@@ -0,0 +1,27 @@
+CREATE TABLE matches (
+    id          BIGSERIAL PRIMARY KEY,
+    room_id     BIGINT NOT NULL,
+    player_x_id TEXT NOT NULL,
+    player_o_id TEXT NOT NULL,
+    size        INTEGER NOT NULL,
+    win_length  INTEGER NOT NULL,
+    board       TEXT NOT NULL,
+    next_turn   TEXT,
+    status      TEXT NOT NULL,
+    winner      TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE INDEX idx_matches_room_id ON matches (room_id);
+
+CREATE TABLE match_moves (
+    id         BIGSERIAL PRIMARY KEY,
+    match_id   BIGINT NOT NULL REFERENCES matches (id) ON DELETE CASCADE,
+    seq        INTEGER NOT NULL,
+    player_id  TEXT NOT NULL,
+    mark       TEXT NOT NULL,
+    row        INTEGER NOT NULL,
+    col        INTEGER NOT NULL,
+    created_at TIMESTAMPTZ,
+    UNIQUE (match_id, seq)
+);
@@ -0,0 +1,15 @@
+ALTER TABLE reservations DROP CONSTRAINT reservations_no_overlap;
+ALTER TABLE reservations DROP CONSTRAINT reservations_valid_slot;
+
+INSERT INTO reservations SELECT * FROM reservations_overlapping;
+DROP TABLE reservations_overlapping;
+
+ALTER TABLE reservations ADD COLUMN date TIMESTAMPTZ;
+ALTER TABLE reservations ADD COLUMN time TEXT;
+UPDATE reservations SET
+    date = start_at,
+    time = to_char(start_at, 'HH24:MI') || '-' || to_char(end_at, 'HH24:MI');
+
+ALTER TABLE reservations DROP COLUMN start_at;
+ALTER TABLE reservations DROP COLUMN end_at;
+ALTER TABLE reservations DROP COLUMN time_zone;
@@ -0,0 +1,39 @@
+-- btree_gist lets the exclusion constraint compare room_id with =
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE reservations ADD COLUMN start_at TIMESTAMPTZ;
+ALTER TABLE reservations ADD COLUMN end_at TIMESTAMPTZ;
+ALTER TABLE reservations ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+
+-- Carry over "HH:MM-HH:MM" slots; anything else becomes a one hour slot
+UPDATE reservations SET
+    start_at = date::date + split_part(time, '-', 1)::time,
+    end_at   = date::date + split_part(time, '-', 2)::time
+WHERE date IS NOT NULL
+  AND time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]-([01][0-9]|2[0-3]):[0-5][0-9]$';
+UPDATE reservations SET end_at = end_at + INTERVAL '1 day' WHERE end_at <= start_at;
+UPDATE reservations SET
+    start_at = COALESCE(date, created_at, now()),
+    end_at   = COALESCE(date, created_at, now()) + INTERVAL '1 hour'
+WHERE start_at IS NULL;
+
+ALTER TABLE reservations ALTER COLUMN start_at SET NOT NULL;
+ALTER TABLE reservations ALTER COLUMN end_at SET NOT NULL;
+ALTER TABLE reservations DROP COLUMN date;
+ALTER TABLE reservations DROP COLUMN time;
+
+-- Legacy bookings may overlap, and the one hour fallback adds to that. A
+-- booking overlapping one booked before it in the same room is set aside
+-- here so the constraint below holds; 0006 brings these back as cancelled
+CREATE TABLE reservations_overlapping (LIKE reservations INCLUDING DEFAULTS);
+INSERT INTO reservations_overlapping
+SELECT * FROM reservations r
+WHERE EXISTS (
+    SELECT 1 FROM reservations o
+    WHERE o.room_id = r.room_id AND o.id < r.id AND o.start_at < r.end_at AND r.start_at < o.end_at
+);
+DELETE FROM reservations WHERE id IN (SELECT id FROM reservations_overlapping);
+
+ALTER TABLE reservations ADD CONSTRAINT reservations_valid_slot CHECK (end_at > start_at);
+ALTER TABLE reservations ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (room_id WITH =, tstzrange(start_at, end_at) WITH &&);
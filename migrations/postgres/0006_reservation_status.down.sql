@@ -0,0 +1,13 @@
+ALTER TABLE reservations DROP CONSTRAINT reservations_no_overlap;
+-- Released slots may overlap; they go back aside, as 0004 left them
+CREATE TABLE reservations_overlapping (LIKE reservations INCLUDING DEFAULTS);
+ALTER TABLE reservations_overlapping DROP COLUMN status;
+INSERT INTO reservations_overlapping (id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at)
+SELECT id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at
+FROM reservations WHERE status IN ('cancelled', 'no_show');
+DELETE FROM reservations WHERE status IN ('cancelled', 'no_show');
+ALTER TABLE reservations ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (room_id WITH =, tstzrange(start_at, end_at) WITH &&);
+
+DROP INDEX idx_reservations_status_start;
+ALTER TABLE reservations DROP COLUMN status;
@@ -0,0 +1,15 @@
+ALTER TABLE reservations ADD COLUMN status TEXT NOT NULL DEFAULT 'booked';
+UPDATE reservations SET status = 'completed' WHERE end_at <= now();
+CREATE INDEX idx_reservations_status_start ON reservations (status, start_at);
+
+-- Cancelled bookings and no-shows release their slot
+ALTER TABLE reservations DROP CONSTRAINT reservations_no_overlap;
+ALTER TABLE reservations ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (room_id WITH =, tstzrange(start_at, end_at) WITH &&)
+    WHERE (status NOT IN ('cancelled', 'no_show'));
+
+-- Bookings set aside by 0004 for overlapping earlier ones come back cancelled
+INSERT INTO reservations (id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at, status)
+SELECT id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at, 'cancelled'
+FROM reservations_overlapping;
+DROP TABLE reservations_overlapping;
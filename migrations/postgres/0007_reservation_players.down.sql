@@ -0,0 +1,7 @@
+DROP TABLE reservation_guests;
+
+DROP INDEX idx_reservations_host_id;
+ALTER TABLE reservations RENAME COLUMN note TO player_info;
+UPDATE reservations SET player_info = host_id
+WHERE (player_info IS NULL OR player_info = '') AND host_id IS NOT NULL;
+ALTER TABLE reservations DROP COLUMN host_id;
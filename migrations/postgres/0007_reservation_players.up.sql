@@ -0,0 +1,13 @@
+-- Reservations belong to a registered host; earlier bookings whose player
+-- info names a player are handed to that player
+ALTER TABLE reservations ADD COLUMN host_id TEXT;
+UPDATE reservations SET host_id = player_info WHERE player_info IN (SELECT id FROM players);
+ALTER TABLE reservations RENAME COLUMN player_info TO note;
+CREATE INDEX idx_reservations_host_id ON reservations (host_id);
+
+CREATE TABLE reservation_guests (
+    reservation_id BIGINT NOT NULL REFERENCES reservations (id) ON DELETE CASCADE,
+    player_id      TEXT NOT NULL,
+    PRIMARY KEY (reservation_id, player_id)
+);
+CREATE INDEX idx_reservation_guests_player_id ON reservation_guests (player_id);
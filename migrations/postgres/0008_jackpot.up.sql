@@ -0,0 +1,17 @@
+-- A single row holds the current pot; it is created with the configured
+-- seed on first use
+CREATE TABLE jackpots (
+    id         BIGSERIAL PRIMARY KEY,
+    amount     NUMERIC(12, 2) NOT NULL,
+    entries    BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE jackpot_payouts (
+    id           BIGSERIAL PRIMARY KEY,
+    challenge_id BIGINT NOT NULL UNIQUE REFERENCES challenges (id) ON DELETE CASCADE,
+    player_id    BIGINT NOT NULL,
+    amount       NUMERIC(12, 2) NOT NULL,
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX idx_jackpot_payouts_created_at ON jackpot_payouts (created_at);
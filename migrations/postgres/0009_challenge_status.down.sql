@@ -0,0 +1,4 @@
+DROP INDEX idx_challenges_status_resolve_at;
+ALTER TABLE challenges DROP COLUMN resolved_at;
+ALTER TABLE challenges DROP COLUMN resolve_at;
+ALTER TABLE challenges DROP COLUMN status;
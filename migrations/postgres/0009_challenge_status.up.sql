@@ -0,0 +1,8 @@
+-- Challenges entered before the resolver existed were settled in memory;
+-- whatever they hold now is final
+ALTER TABLE challenges ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
+ALTER TABLE challenges ADD COLUMN resolve_at TIMESTAMPTZ;
+ALTER TABLE challenges ADD COLUMN resolved_at TIMESTAMPTZ;
+UPDATE challenges SET status = 'resolved', resolve_at = COALESCE(created_at, now()), resolved_at = COALESCE(created_at, now());
+ALTER TABLE challenges ALTER COLUMN resolve_at SET NOT NULL;
+CREATE INDEX idx_challenges_status_resolve_at ON challenges (status, resolve_at);
@@ -0,0 +1,5 @@
+DROP TABLE ledger_entries;
+
+ALTER TABLE wallets ALTER COLUMN balance DROP DEFAULT;
+ALTER TABLE wallets ALTER COLUMN balance TYPE NUMERIC(12, 2) USING balance / 100.0;
+ALTER TABLE wallets ALTER COLUMN balance SET DEFAULT 0;
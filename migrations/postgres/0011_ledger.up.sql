@@ -0,0 +1,30 @@
+-- Balances move to whole cents
+ALTER TABLE wallets ALTER COLUMN balance DROP DEFAULT;
+ALTER TABLE wallets ALTER COLUMN balance TYPE BIGINT USING ROUND(balance * 100)::BIGINT;
+ALTER TABLE wallets ALTER COLUMN balance SET DEFAULT 0;
+
+CREATE TABLE ledger_entries (
+    id         BIGSERIAL PRIMARY KEY,
+    transfer   VARCHAR(64) NOT NULL,
+    account    VARCHAR(64) NOT NULL,
+    kind       VARCHAR(32) NOT NULL,
+    amount     BIGINT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_ledger_transfer_account ON ledger_entries (transfer, account);
+CREATE INDEX idx_ledger_account ON ledger_entries (account);
+
+-- Open the ledger with the balances held so far: wallets were paid in from
+-- outside and the house funded the jackpot
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:player:' || player_id, 'player:' || player_id, 'opening', balance, NOW()
+FROM wallets WHERE balance <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:player:' || player_id, 'external', 'opening', -balance, NOW()
+FROM wallets WHERE balance <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:jackpot', 'jackpot', 'opening', ROUND(amount * 100), NOW()
+FROM jackpots WHERE amount <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:jackpot', 'house', 'opening', -ROUND(amount * 100), NOW()
+FROM jackpots WHERE amount <> 0;
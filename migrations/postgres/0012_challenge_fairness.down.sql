@@ -0,0 +1,6 @@
+ALTER TABLE challenges DROP COLUMN win_chance;
+ALTER TABLE challenges DROP COLUMN roll;
+ALTER TABLE challenges DROP COLUMN nonce;
+ALTER TABLE challenges DROP COLUMN client_seed;
+ALTER TABLE challenges DROP COLUMN server_seed_hash;
+ALTER TABLE challenges DROP COLUMN server_seed;
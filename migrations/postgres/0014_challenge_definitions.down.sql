@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_challenges_definition_id;
+ALTER TABLE challenges DROP COLUMN definition_id;
+DROP TABLE IF EXISTS challenge_definitions;
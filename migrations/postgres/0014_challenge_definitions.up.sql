@@ -0,0 +1,22 @@
+-- Challenges besides the endless one, each with its own fee, timing, odds
+-- and active window
+CREATE TABLE challenge_definitions (
+    id                  BIGSERIAL PRIMARY KEY,
+    name                VARCHAR(32) NOT NULL UNIQUE,
+    entry_fee           NUMERIC(12, 2) NOT NULL,
+    duration_seconds    BIGINT NOT NULL,
+    cooldown_seconds    BIGINT NOT NULL,
+    odds_model          VARCHAR(16) NOT NULL,
+    odds_base           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    odds_step           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    odds_cap            DOUBLE PRECISION NOT NULL DEFAULT 0,
+    odds_levels         TEXT,
+    starts_at           TIMESTAMPTZ,
+    ends_at             TIMESTAMPTZ,
+    max_entries_per_day BIGINT NOT NULL DEFAULT 0,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ
+);
+
+ALTER TABLE challenges ADD COLUMN definition_id BIGINT REFERENCES challenge_definitions (id);
+CREATE INDEX idx_challenges_definition_id ON challenges (definition_id);
@@ -0,0 +1,16 @@
+-- Requests made with an Idempotency-Key header and the responses replayed
+-- to their retries. The first request claims the key with an upsert that
+-- only replaces expired rows, so concurrent duplicates cannot both run
+CREATE TABLE idempotency_keys (
+    scope        VARCHAR(64) NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code  INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body         TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
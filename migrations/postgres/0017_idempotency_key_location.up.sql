@@ -0,0 +1,2 @@
+-- Replays of create responses carry the Location of what was created
+ALTER TABLE idempotency_keys ADD COLUMN location VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Provider callbacks find the payment by the provider's transaction ID
+CREATE INDEX idx_payments_transaction ON payments (method, transaction_id);
@@ -0,0 +1,5 @@
+ALTER TABLE challenge_definitions ALTER COLUMN entry_fee TYPE NUMERIC(12, 2) USING entry_fee / 100.0;
+ALTER TABLE challenges ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount / 100.0;
+ALTER TABLE payments ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount / 100.0;
+ALTER TABLE jackpot_payouts ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount / 100.0;
+ALTER TABLE jackpots ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount / 100.0;
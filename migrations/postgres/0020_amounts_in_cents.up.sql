@@ -0,0 +1,7 @@
+-- The remaining amounts move to whole cents, like wallet balances and the
+-- ledger
+ALTER TABLE jackpots ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+ALTER TABLE jackpot_payouts ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+ALTER TABLE payments ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+ALTER TABLE challenges ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT;
+ALTER TABLE challenge_definitions ALTER COLUMN entry_fee TYPE BIGINT USING ROUND(entry_fee * 100)::BIGINT;
@@ -0,0 +1,8 @@
+-- Pending payments are charged by a background job that claims them through
+-- process_at, so payments accepted before a restart are still charged.
+-- Payments the provider already knows wait for its callback instead; the
+-- others are due right away
+ALTER TABLE payments ADD COLUMN process_at TIMESTAMPTZ;
+UPDATE payments SET process_at = COALESCE(created_at, CURRENT_TIMESTAMP)
+WHERE status = 'Pending' AND (transaction_id IS NULL OR transaction_id = '');
+CREATE INDEX idx_payments_process_at ON payments (process_at);
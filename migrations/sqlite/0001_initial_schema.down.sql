@@ -0,0 +1,7 @@
+DROP TABLE payments;
+DROP TABLE logs;
+DROP TABLE challenges;
+DROP TABLE reservations;
+DROP TABLE rooms;
+DROP TABLE players;
+DROP TABLE levels;
@@ -0,0 +1,65 @@
+-- Databases set up before versioned migrations already have levels, players,
+-- rooms and reservations from AutoMigrate, with the same columns; those
+-- tables are adopted as they are rather than created.
+
+CREATE TABLE IF NOT EXISTS levels (
+    id   TEXT PRIMARY KEY,
+    name TEXT UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS players (
+    id       TEXT PRIMARY KEY,
+    name     TEXT,
+    level_id TEXT
+);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT,
+    description TEXT,
+    status      TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id     INTEGER NOT NULL,
+    date        DATETIME,
+    time        TEXT,
+    player_info TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_room_id ON reservations (room_id);
+
+CREATE TABLE IF NOT EXISTS challenges (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id  INTEGER NOT NULL,
+    amount     REAL NOT NULL,
+    won        NUMERIC NOT NULL DEFAULT FALSE,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_challenges_player_created ON challenges (player_id, created_at);
+
+CREATE TABLE IF NOT EXISTS logs (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id INTEGER NOT NULL,
+    action    TEXT NOT NULL,
+    timestamp DATETIME,
+    details   TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_logs_player_timestamp ON logs (player_id, timestamp);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    player_id      INTEGER NOT NULL,
+    method         TEXT NOT NULL,
+    amount         REAL NOT NULL,
+    details        TEXT,
+    status         TEXT NOT NULL,
+    transaction_id TEXT,
+    error_message  TEXT,
+    created_at     DATETIME,
+    updated_at     DATETIME
+);
@@ -0,0 +1,2 @@
+DROP TABLE match_moves;
+DROP TABLE matches;
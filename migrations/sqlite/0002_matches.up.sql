@@ -0,0 +1,27 @@
+CREATE TABLE matches (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id     INTEGER NOT NULL,
+    player_x_id TEXT NOT NULL,
+    player_o_id TEXT NOT NULL,
+    size        INTEGER NOT NULL,
+    win_length  INTEGER NOT NULL,
+    board       TEXT NOT NULL,
+    next_turn   TEXT,
+    status      TEXT NOT NULL,
+    winner      TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE INDEX idx_matches_room_id ON matches (room_id);
+
+CREATE TABLE match_moves (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    match_id   INTEGER NOT NULL REFERENCES matches (id) ON DELETE CASCADE,
+    seq        INTEGER NOT NULL,
+    player_id  TEXT NOT NULL,
+    mark       TEXT NOT NULL,
+    row        INTEGER NOT NULL,
+    col        INTEGER NOT NULL,
+    created_at DATETIME,
+    UNIQUE (match_id, seq)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE rooms ADD COLUMN capacity INTEGER NOT NULL DEFAULT 2;
+
+-- Statuses used to be free-form; rooms start over as open
+UPDATE rooms SET status = 'open'
+WHERE status IS NULL OR status NOT IN ('open', 'full', 'in_game', 'closed');
+
+CREATE TABLE room_members (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id   INTEGER NOT NULL REFERENCES rooms (id) ON DELETE CASCADE,
+    player_id TEXT NOT NULL UNIQUE,
+    seat      INTEGER NOT NULL,
+    joined_at DATETIME,
+    UNIQUE (room_id, seat)
+);
@@ -0,0 +1,24 @@
+CREATE TABLE reservations_old (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id     INTEGER NOT NULL,
+    date        DATETIME,
+    time        TEXT,
+    player_info TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+
+INSERT INTO reservations_old (id, room_id, date, time, player_info, created_at, updated_at)
+SELECT id, room_id, start_at,
+    strftime('%H:%M', start_at) || '-' || strftime('%H:%M', end_at),
+    player_info, created_at, updated_at
+FROM (
+    SELECT id, room_id, start_at, end_at, player_info, created_at, updated_at FROM reservations
+    UNION ALL
+    SELECT id, room_id, start_at, end_at, player_info, created_at, updated_at FROM reservations_overlapping
+);
+
+DROP TABLE reservations_overlapping;
+DROP TABLE reservations;
+ALTER TABLE reservations_old RENAME TO reservations;
+CREATE INDEX idx_reservations_room_id ON reservations (room_id);
@@ -0,0 +1,79 @@
+-- Times are stored as UTC text ("YYYY-MM-DD HH:MM:SS+00:00"), which
+-- compares correctly as strings in the overlap triggers below.
+CREATE TABLE reservations_new (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id     INTEGER NOT NULL,
+    start_at    DATETIME NOT NULL,
+    end_at      DATETIME NOT NULL,
+    time_zone   TEXT NOT NULL DEFAULT 'UTC',
+    player_info TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME,
+    CHECK (end_at > start_at)
+);
+
+-- Carry over "HH:MM-HH:MM" slots; anything else becomes a one hour slot
+INSERT INTO reservations_new (id, room_id, start_at, end_at, player_info, created_at, updated_at)
+SELECT id, room_id,
+    strftime('%Y-%m-%d %H:%M:%S+00:00', date(date) || ' ' || substr(time, 1, 5)),
+    strftime('%Y-%m-%d %H:%M:%S+00:00', date(date) || ' ' || substr(time, 7, 5),
+        CASE WHEN substr(time, 7, 5) <= substr(time, 1, 5) THEN '+1 day' ELSE '+0 days' END),
+    player_info, created_at, updated_at
+FROM reservations
+WHERE date IS NOT NULL
+  AND time GLOB '[0-2][0-9]:[0-5][0-9]-[0-2][0-9]:[0-5][0-9]'
+  AND substr(time, 1, 2) < '24' AND substr(time, 7, 2) < '24';
+
+INSERT INTO reservations_new (id, room_id, start_at, end_at, player_info, created_at, updated_at)
+SELECT id, room_id,
+    strftime('%Y-%m-%d %H:%M:%S+00:00', COALESCE(date, created_at, 'now')),
+    strftime('%Y-%m-%d %H:%M:%S+00:00', COALESCE(date, created_at, 'now'), '+1 hour'),
+    player_info, created_at, updated_at
+FROM reservations
+WHERE id NOT IN (SELECT id FROM reservations_new);
+
+DROP TABLE reservations;
+ALTER TABLE reservations_new RENAME TO reservations;
+CREATE INDEX idx_reservations_room_start ON reservations (room_id, start_at);
+
+-- Legacy bookings may overlap, and the one hour fallback adds to that. A
+-- booking overlapping one booked before it in the same room is set aside
+-- here so the triggers below hold; 0006 brings these back as cancelled
+CREATE TABLE reservations_overlapping (
+    id          INTEGER PRIMARY KEY,
+    room_id     INTEGER NOT NULL,
+    start_at    DATETIME NOT NULL,
+    end_at      DATETIME NOT NULL,
+    time_zone   TEXT NOT NULL DEFAULT 'UTC',
+    player_info TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+INSERT INTO reservations_overlapping (id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at)
+SELECT id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at
+FROM reservations r
+WHERE EXISTS (
+    SELECT 1 FROM reservations o
+    WHERE o.room_id = r.room_id AND o.id < r.id AND o.start_at < r.end_at AND r.start_at < o.end_at
+);
+DELETE FROM reservations WHERE id IN (SELECT id FROM reservations_overlapping);
+
+-- SQLite has no exclusion constraints; these triggers reject overlapping
+-- slots in the same room instead
+CREATE TRIGGER reservations_no_overlap_insert BEFORE INSERT ON reservations
+WHEN EXISTS (
+    SELECT 1 FROM reservations r
+    WHERE r.room_id = NEW.room_id AND r.start_at < NEW.end_at AND NEW.start_at < r.end_at
+)
+BEGIN
+    SELECT RAISE(ABORT, 'reservations_no_overlap');
+END;
+
+CREATE TRIGGER reservations_no_overlap_update BEFORE UPDATE OF room_id, start_at, end_at ON reservations
+WHEN EXISTS (
+    SELECT 1 FROM reservations r
+    WHERE r.id <> NEW.id AND r.room_id = NEW.room_id AND r.start_at < NEW.end_at AND NEW.start_at < r.end_at
+)
+BEGIN
+    SELECT RAISE(ABORT, 'reservations_no_overlap');
+END;
@@ -0,0 +1,3 @@
+ALTER TABLE rooms ADD COLUMN opens_at TEXT NOT NULL DEFAULT '00:00';
+ALTER TABLE rooms ADD COLUMN closes_at TEXT NOT NULL DEFAULT '24:00';
+ALTER TABLE rooms ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,33 @@
+ALTER TABLE reservations ADD COLUMN status TEXT NOT NULL DEFAULT 'booked';
+UPDATE reservations SET status = 'completed' WHERE end_at <= strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+CREATE INDEX idx_reservations_status_start ON reservations (status, start_at);
+
+-- Cancelled bookings and no-shows release their slot
+DROP TRIGGER reservations_no_overlap_insert;
+DROP TRIGGER reservations_no_overlap_update;
+
+CREATE TRIGGER reservations_no_overlap_insert BEFORE INSERT ON reservations
+WHEN NEW.status NOT IN ('cancelled', 'no_show') AND EXISTS (
+    SELECT 1 FROM reservations r
+    WHERE r.room_id = NEW.room_id AND r.start_at < NEW.end_at AND NEW.start_at < r.end_at
+      AND r.status NOT IN ('cancelled', 'no_show')
+)
+BEGIN
+    SELECT RAISE(ABORT, 'reservations_no_overlap');
+END;
+
+CREATE TRIGGER reservations_no_overlap_update BEFORE UPDATE OF room_id, start_at, end_at, status ON reservations
+WHEN NEW.status NOT IN ('cancelled', 'no_show') AND EXISTS (
+    SELECT 1 FROM reservations r
+    WHERE r.id <> NEW.id AND r.room_id = NEW.room_id AND r.start_at < NEW.end_at AND NEW.start_at < r.end_at
+      AND r.status NOT IN ('cancelled', 'no_show')
+)
+BEGIN
+    SELECT RAISE(ABORT, 'reservations_no_overlap');
+END;
+
+-- Bookings set aside by 0004 for overlapping earlier ones come back cancelled
+INSERT INTO reservations (id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at, status)
+SELECT id, room_id, start_at, end_at, time_zone, player_info, created_at, updated_at, 'cancelled'
+FROM reservations_overlapping;
+DROP TABLE reservations_overlapping;
@@ -0,0 +1,2 @@
+DROP TABLE jackpot_payouts;
+DROP TABLE jackpots;
@@ -0,0 +1,17 @@
+-- A single row holds the current pot; it is created with the configured
+-- seed on first use
+CREATE TABLE jackpots (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    amount     REAL NOT NULL,
+    entries    INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME
+);
+
+CREATE TABLE jackpot_payouts (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    challenge_id INTEGER NOT NULL UNIQUE REFERENCES challenges (id) ON DELETE CASCADE,
+    player_id    INTEGER NOT NULL,
+    amount       REAL NOT NULL,
+    created_at   DATETIME
+);
+CREATE INDEX idx_jackpot_payouts_created_at ON jackpot_payouts (created_at);
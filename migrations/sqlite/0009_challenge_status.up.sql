@@ -0,0 +1,7 @@
+-- Challenges entered before the resolver existed were settled in memory;
+-- whatever they hold now is final
+ALTER TABLE challenges ADD COLUMN status TEXT NOT NULL DEFAULT 'pending';
+ALTER TABLE challenges ADD COLUMN resolve_at DATETIME;
+ALTER TABLE challenges ADD COLUMN resolved_at DATETIME;
+UPDATE challenges SET status = 'resolved', resolve_at = COALESCE(created_at, strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')), resolved_at = COALESCE(created_at, strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'));
+CREATE INDEX idx_challenges_status_resolve_at ON challenges (status, resolve_at);
@@ -0,0 +1,11 @@
+CREATE TABLE wallets (
+    player_id  INTEGER PRIMARY KEY,
+    balance    REAL NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    updated_at DATETIME
+);
+
+-- Money already paid in is the opening balance
+INSERT INTO wallets (player_id, balance, updated_at)
+SELECT player_id, SUM(amount), MAX(updated_at) FROM payments
+WHERE status = 'Success'
+GROUP BY player_id;
@@ -0,0 +1,11 @@
+DROP TABLE ledger_entries;
+
+CREATE TABLE wallets_units (
+    player_id  INTEGER PRIMARY KEY,
+    balance    REAL NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    updated_at DATETIME
+);
+INSERT INTO wallets_units (player_id, balance, updated_at)
+SELECT player_id, balance / 100.0, updated_at FROM wallets;
+DROP TABLE wallets;
+ALTER TABLE wallets_units RENAME TO wallets;
@@ -0,0 +1,36 @@
+-- Balances move to whole cents
+CREATE TABLE wallets_cents (
+    player_id  INTEGER PRIMARY KEY,
+    balance    INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    updated_at DATETIME
+);
+INSERT INTO wallets_cents (player_id, balance, updated_at)
+SELECT player_id, CAST(ROUND(balance * 100) AS INTEGER), updated_at FROM wallets;
+DROP TABLE wallets;
+ALTER TABLE wallets_cents RENAME TO wallets;
+
+CREATE TABLE ledger_entries (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    transfer   VARCHAR(64) NOT NULL,
+    account    VARCHAR(64) NOT NULL,
+    kind       VARCHAR(32) NOT NULL,
+    amount     BIGINT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_ledger_transfer_account ON ledger_entries (transfer, account);
+CREATE INDEX idx_ledger_account ON ledger_entries (account);
+
+-- Open the ledger with the balances held so far: wallets were paid in from
+-- outside and the house funded the jackpot
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:player:' || player_id, 'player:' || player_id, 'opening', balance, CURRENT_TIMESTAMP
+FROM wallets WHERE balance <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:player:' || player_id, 'external', 'opening', -balance, CURRENT_TIMESTAMP
+FROM wallets WHERE balance <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:jackpot', 'jackpot', 'opening', ROUND(amount * 100), CURRENT_TIMESTAMP
+FROM jackpots WHERE amount <> 0;
+INSERT INTO ledger_entries (transfer, account, kind, amount, created_at)
+SELECT 'opening:jackpot', 'house', 'opening', -ROUND(amount * 100), CURRENT_TIMESTAMP
+FROM jackpots WHERE amount <> 0;
@@ -0,0 +1,8 @@
+-- Commit-reveal seeds. Challenges settled before have none; pending ones
+-- get their seeds when they are resolved
+ALTER TABLE challenges ADD COLUMN server_seed VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE challenges ADD COLUMN server_seed_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE challenges ADD COLUMN client_seed VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE challenges ADD COLUMN nonce BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE challenges ADD COLUMN roll REAL;
+ALTER TABLE challenges ADD COLUMN win_chance REAL;
@@ -0,0 +1,2 @@
+ALTER TABLE challenges DROP COLUMN odds_model;
+ALTER TABLE challenges DROP COLUMN type;
@@ -0,0 +1,6 @@
+-- Entries so far were endless challenges. The chances recorded on them came
+-- from the old linear odds (1% plus 1% per entry); pending ones without a
+-- chance get their odds when resolved
+ALTER TABLE challenges ADD COLUMN type VARCHAR(32) NOT NULL DEFAULT 'endless';
+ALTER TABLE challenges ADD COLUMN odds_model VARCHAR(16) NOT NULL DEFAULT '';
+UPDATE challenges SET odds_model = 'linear' WHERE win_chance IS NOT NULL;
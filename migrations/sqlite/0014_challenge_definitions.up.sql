@@ -0,0 +1,24 @@
+-- Challenges besides the endless one, each with its own fee, timing, odds
+-- and active window
+CREATE TABLE challenge_definitions (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    name                VARCHAR(32) NOT NULL UNIQUE,
+    entry_fee           REAL NOT NULL,
+    duration_seconds    INTEGER NOT NULL,
+    cooldown_seconds    INTEGER NOT NULL,
+    odds_model          VARCHAR(16) NOT NULL,
+    odds_base           REAL NOT NULL DEFAULT 0,
+    odds_step           REAL NOT NULL DEFAULT 0,
+    odds_cap            REAL NOT NULL DEFAULT 0,
+    odds_levels         TEXT,
+    starts_at           DATETIME,
+    ends_at             DATETIME,
+    max_entries_per_day INTEGER NOT NULL DEFAULT 0,
+    created_at          DATETIME,
+    updated_at          DATETIME
+);
+
+-- SQLite cannot drop a column under a foreign key, so deleting a definition
+-- with entries is refused by the application instead
+ALTER TABLE challenges ADD COLUMN definition_id INTEGER;
+CREATE INDEX idx_challenges_definition_id ON challenges (definition_id);
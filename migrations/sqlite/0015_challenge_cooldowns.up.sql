@@ -0,0 +1,17 @@
+-- The time of each player's last entry per cooldown scope. Entries claim
+-- the row with a conditional upsert, so concurrent entries cannot both pass
+-- the cooldown
+CREATE TABLE challenge_cooldowns (
+    player_id  INTEGER NOT NULL,
+    scope      VARCHAR(32) NOT NULL,
+    entered_at DATETIME NOT NULL,
+    PRIMARY KEY (player_id, scope)
+);
+
+INSERT INTO challenge_cooldowns (player_id, scope, entered_at)
+SELECT player_id,
+       CASE WHEN definition_id IS NULL THEN 'endless' ELSE 'definition:' || definition_id END,
+       MAX(created_at)
+FROM challenges
+WHERE created_at IS NOT NULL
+GROUP BY player_id, definition_id;
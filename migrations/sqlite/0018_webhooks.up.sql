@@ -0,0 +1,30 @@
+-- Webhook subscriptions and the outbox of deliveries to them. Deliveries
+-- are queued in the transaction that makes the event happen and sent by a
+-- background dispatcher
+CREATE TABLE webhooks (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    url        VARCHAR(2048) NOT NULL,
+    events     TEXT NOT NULL,
+    secret     VARCHAR(255) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        VARCHAR(64) NOT NULL,
+    event           VARCHAR(32) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_attempt_at DATETIME,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME,
+    updated_at      DATETIME
+);
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
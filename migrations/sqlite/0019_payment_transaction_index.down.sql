@@ -0,0 +1 @@
+DROP INDEX idx_payments_transaction;
@@ -0,0 +1,24 @@
+ALTER TABLE challenge_definitions ADD COLUMN entry_fee_units REAL NOT NULL DEFAULT 0;
+UPDATE challenge_definitions SET entry_fee_units = entry_fee / 100.0;
+ALTER TABLE challenge_definitions DROP COLUMN entry_fee;
+ALTER TABLE challenge_definitions RENAME COLUMN entry_fee_units TO entry_fee;
+
+ALTER TABLE challenges ADD COLUMN amount_units REAL NOT NULL DEFAULT 0;
+UPDATE challenges SET amount_units = amount / 100.0;
+ALTER TABLE challenges DROP COLUMN amount;
+ALTER TABLE challenges RENAME COLUMN amount_units TO amount;
+
+ALTER TABLE payments ADD COLUMN amount_units REAL NOT NULL DEFAULT 0;
+UPDATE payments SET amount_units = amount / 100.0;
+ALTER TABLE payments DROP COLUMN amount;
+ALTER TABLE payments RENAME COLUMN amount_units TO amount;
+
+ALTER TABLE jackpot_payouts ADD COLUMN amount_units REAL NOT NULL DEFAULT 0;
+UPDATE jackpot_payouts SET amount_units = amount / 100.0;
+ALTER TABLE jackpot_payouts DROP COLUMN amount;
+ALTER TABLE jackpot_payouts RENAME COLUMN amount_units TO amount;
+
+ALTER TABLE jackpots ADD COLUMN amount_units REAL NOT NULL DEFAULT 0;
+UPDATE jackpots SET amount_units = amount / 100.0;
+ALTER TABLE jackpots DROP COLUMN amount;
+ALTER TABLE jackpots RENAME COLUMN amount_units TO amount;
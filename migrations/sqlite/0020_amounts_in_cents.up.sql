@@ -0,0 +1,27 @@
+-- The remaining amounts move to whole cents, like wallet balances and the
+-- ledger. SQLite cannot change a column's type, so each is replaced.
+
+ALTER TABLE jackpots ADD COLUMN amount_cents BIGINT NOT NULL DEFAULT 0;
+UPDATE jackpots SET amount_cents = CAST(ROUND(amount * 100) AS INTEGER);
+ALTER TABLE jackpots DROP COLUMN amount;
+ALTER TABLE jackpots RENAME COLUMN amount_cents TO amount;
+
+ALTER TABLE jackpot_payouts ADD COLUMN amount_cents BIGINT NOT NULL DEFAULT 0;
+UPDATE jackpot_payouts SET amount_cents = CAST(ROUND(amount * 100) AS INTEGER);
+ALTER TABLE jackpot_payouts DROP COLUMN amount;
+ALTER TABLE jackpot_payouts RENAME COLUMN amount_cents TO amount;
+
+ALTER TABLE payments ADD COLUMN amount_cents BIGINT NOT NULL DEFAULT 0;
+UPDATE payments SET amount_cents = CAST(ROUND(amount * 100) AS INTEGER);
+ALTER TABLE payments DROP COLUMN amount;
+ALTER TABLE payments RENAME COLUMN amount_cents TO amount;
+
+ALTER TABLE challenges ADD COLUMN amount_cents BIGINT NOT NULL DEFAULT 0;
+UPDATE challenges SET amount_cents = CAST(ROUND(amount * 100) AS INTEGER);
+ALTER TABLE challenges DROP COLUMN amount;
+ALTER TABLE challenges RENAME COLUMN amount_cents TO amount;
+
+ALTER TABLE challenge_definitions ADD COLUMN entry_fee_cents BIGINT NOT NULL DEFAULT 0;
+UPDATE challenge_definitions SET entry_fee_cents = CAST(ROUND(entry_fee * 100) AS INTEGER);
+ALTER TABLE challenge_definitions DROP COLUMN entry_fee;
+ALTER TABLE challenge_definitions RENAME COLUMN entry_fee_cents TO entry_fee;
@@ -0,0 +1,11 @@
+-- The seed pair each player's next challenges are played with. Its server
+-- seed hash is shown before entry, and the pair is replaced once the seed
+-- is revealed
+CREATE TABLE player_seeds (
+    player_id        INTEGER PRIMARY KEY,
+    server_seed      VARCHAR(64) NOT NULL,
+    server_seed_hash VARCHAR(64) NOT NULL,
+    client_seed      VARCHAR(64) NOT NULL,
+    nonce            BIGINT NOT NULL DEFAULT 0,
+    created_at       DATETIME NOT NULL
+);
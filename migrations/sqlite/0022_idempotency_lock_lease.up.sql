@@ -0,0 +1,5 @@
+-- A request in progress holds its key until locked_until, a short lease,
+-- rather than for the replay TTL, so a request that crashed does not lock
+-- its key for a day. Claims made before have no lease and may be taken over
+ALTER TABLE idempotency_keys ADD COLUMN locked_until DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE idempotency_keys SET locked_until = created_at;
@@ -0,0 +1,4 @@
+-- Each claim on a key carries a token of its own, so a request whose claim
+-- was taken over after its lease ran out can no longer complete or release
+-- the claim of the request that took it over
+ALTER TABLE idempotency_keys ADD COLUMN claim_token TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX idx_payments_process_at;
+ALTER TABLE payments DROP COLUMN process_at;
@@ -2,11 +2,53 @@ package models
 
 import "time"
 
+// Challenge statuses. A challenge is pending until the resolver decides its
+// outcome at ResolveAt.
+const (
+    ChallengeStatusPending  = "pending"
+    ChallengeStatusResolved = "resolved"
+)
+
+// ChallengeTypeEndless is the type of entries that do not name one.
+const ChallengeTypeEndless = "endless"
+
 // Challenge represents a player's participation in a challenge
 type Challenge struct {
-    ID        uint      `json:"id" gorm:"primaryKey"`
-    PlayerID  uint      `json:"player_id" gorm:"not null"`
-    Amount    float64   `json:"amount" gorm:"not null"`
-    Won       bool      `json:"won"`
-    CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+    ID           uint       `json:"id" gorm:"primaryKey"`
+    PlayerID     uint       `json:"player_id" gorm:"not null"`
+    Type         string     `json:"type" gorm:"not null;default:endless"` // Picks the odds model in the configuration
+    DefinitionID *uint      `json:"definition_id,omitempty" gorm:"index"` // The challenge definition entered, if any
+    Amount       int64      `json:"amount" gorm:"not null"` // Entry fee in cents
+    Won          bool       `json:"won"` // Only meaningful once resolved
+    Status       string     `json:"status" gorm:"not null;default:pending"`
+    ResolveAt    time.Time  `json:"resolve_at" gorm:"not null"`
+    ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+    CreatedAt    time.Time  `json:"created_at"`
+
+    // Provably fair outcome, see package fairness. The server seed stays
+    // secret until the challenge is verified after resolution.
+    ServerSeed     string   `json:"-" gorm:"not null;default:''"`
+    ServerSeedHash string   `json:"server_seed_hash" gorm:"not null;default:''"`
+    ClientSeed     string   `json:"client_seed" gorm:"not null;default:''"`
+    Nonce          uint64   `json:"nonce" gorm:"not null;default:0"` // Numbers the entries played with the server seed
+    Roll           *float64 `json:"roll,omitempty"`                  // Set once resolved
+    WinChance      *float64 `json:"win_chance,omitempty"`            // Probability the roll has to beat, computed at entry
+    OddsModel      string   `json:"odds_model" gorm:"not null;default:''"` // Model WinChance was computed with
+}
+
+// Committed reports whether the challenge's server seed hash was published
+// when it was entered. Challenges entered before that were seeded when they
+// were resolved and have nonce 0; their outcome cannot be verified.
+func (c Challenge) Committed() bool {
+    return c.ServerSeedHash != "" && c.Nonce > 0
+}
+
+// ChallengeStats sums up a player's challenge entries.
+type ChallengeStats struct {
+    PlayerID   uint    `json:"player_id"`
+    Entries    int64   `json:"entries"`
+    Pending    int64   `json:"pending"`
+    Wins       int64   `json:"wins"`
+    TotalSpent int64   `json:"total_spent"` // Entry fees paid, in cents
+    TotalWon   int64   `json:"total_won"`   // Jackpots won, in cents
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChallengeCooldown records when a player last entered a challenge. Each
+// definition has its own cooldown; entries without one share the endless
+// scope.
+type ChallengeCooldown struct {
+	PlayerID  uint      `json:"player_id" gorm:"primaryKey;autoIncrement:false"`
+	Scope     string    `json:"scope" gorm:"primaryKey"`
+	EnteredAt time.Time `json:"entered_at" gorm:"not null"`
+}
+
+// CooldownScopeEndless is the cooldown scope of entries without a definition.
+const CooldownScopeEndless = "endless"
+
+// CooldownScope returns the cooldown scope of entries into a definition.
+func CooldownScope(definitionID uint) string {
+	return fmt.Sprintf("definition:%d", definitionID)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ChallengeDefinition is a challenge players can enter besides the endless
+// one, such as a weekend event. Its settings replace the configured
+// challenge defaults for its entries, and it only takes entries inside its
+// active window.
+type ChallengeDefinition struct {
+	ID               uint               `json:"id" gorm:"primaryKey"`
+	Name             string             `json:"name" gorm:"not null;uniqueIndex"` // Recorded as the type of its entries
+	EntryFee         int64              `json:"entry_fee" gorm:"not null"`        // In cents
+	DurationSeconds  int                `json:"duration_seconds" gorm:"not null"` // From entry until the outcome is decided
+	CooldownSeconds  int                `json:"cooldown_seconds" gorm:"not null"` // Between a player's entries
+	OddsModel        string             `json:"odds_model" gorm:"not null"`       // flat, linear, streak or level
+	OddsBase         float64            `json:"odds_base"`
+	OddsStep         float64            `json:"odds_step"`
+	OddsCap          float64            `json:"odds_cap"`
+	OddsLevels       map[string]float64 `json:"odds_levels,omitempty" gorm:"serializer:json"` // Chance by player level under the level model
+	StartsAt         *time.Time         `json:"starts_at,omitempty"`                          // Open from the start when empty
+	EndsAt           *time.Time         `json:"ends_at,omitempty"`                            // Open-ended when empty
+	MaxEntriesPerDay int                `json:"max_entries_per_day"`                          // Per player and UTC day; 0 for no limit
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// Duration is how long entries wait for their outcome.
+func (d ChallengeDefinition) Duration() time.Duration {
+	return time.Duration(d.DurationSeconds) * time.Second
+}
+
+// Cooldown is how long a player waits between entries.
+func (d ChallengeDefinition) Cooldown() time.Duration {
+	return time.Duration(d.CooldownSeconds) * time.Second
+}
+
+// ActiveAt reports whether the definition takes entries at t.
+func (d ChallengeDefinition) ActiveAt(t time.Time) bool {
+	if d.StartsAt != nil && t.Before(*d.StartsAt) {
+		return false
+	}
+	return d.EndsAt == nil || t.Before(*d.EndsAt)
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strconv"
+)
+
+// NewID returns a random numeric string ID for models keyed by strings.
+// IDs stay numeric so they can also be referenced from tables that store
+// player IDs as unsigned integers (challenges, payments, logs).
+func NewID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	// Keep 53 bits so the ID survives a round-trip through JSON numbers
+	return strconv.FormatUint(binary.BigEndian.Uint64(b[:])>>11, 10)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// IdempotencyKey records a request made with an Idempotency-Key header so
+// that retries get the first response back instead of repeating its
+// effects. StatusCode stays zero while the first request is in progress,
+// which holds the key until LockedUntil for the claim named by ClaimToken;
+// once its response is stored the key is replayed until ExpiresAt.
+type IdempotencyKey struct {
+	Scope       string    `json:"scope" gorm:"primaryKey"` // Method and route, e.g. "POST /payments"
+	Key         string    `json:"key" gorm:"primaryKey"`
+	RequestHash string    `json:"request_hash" gorm:"not null"` // SHA-256 of the request body
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type" gorm:"not null;default:''"`
+	Location    string    `json:"location" gorm:"not null;default:''"` // Location header of created resources
+	Body        string    `json:"body" gorm:"type:text;not null;default:''"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	LockedUntil time.Time `json:"locked_until" gorm:"not null"`
+	ClaimToken  string    `json:"-" gorm:"not null;default:''"` // Names the request holding the key
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// Completed reports whether the first request's response has been stored.
+func (k IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
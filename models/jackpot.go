@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Jackpot is the pot a winning challenge takes in full. Every entry adds
+// its fee minus the house rake; a payout resets it to the configured seed.
+// There is a single jackpot row.
+type Jackpot struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Amount    int64     `json:"amount" gorm:"not null"`  // In cents
+	Entries   int64     `json:"entries" gorm:"not null"` // Challenges entered since the last payout
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JackpotPayout records a jackpot paid out to a winning challenge.
+type JackpotPayout struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ChallengeID uint      `json:"challenge_id" gorm:"not null;uniqueIndex"`
+	PlayerID    uint      `json:"player_id" gorm:"not null"`
+	Amount      int64     `json:"amount" gorm:"not null"` // In cents
+	CreatedAt   time.Time `json:"created_at"`
+}
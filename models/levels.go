@@ -1,6 +1,16 @@
 package models
 
+import "gorm.io/gorm"
+
 type Level struct {
     ID   string `json:"id" gorm:"primaryKey"`
     Name string `json:"name" gorm:"unique"`
-}
\ No newline at end of file
+}
+
+// BeforeCreate assigns a generated ID when the client did not supply one.
+func (l *Level) BeforeCreate(tx *gorm.DB) error {
+    if l.ID == "" {
+        l.ID = NewID()
+    }
+    return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Match is an OXO game played between two players in a room.
+type Match struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	RoomID    uint        `json:"room_id" gorm:"not null"`
+	PlayerXID string      `json:"player_x_id" gorm:"not null"`
+	PlayerOID string      `json:"player_o_id" gorm:"not null"`
+	Size      int         `json:"size" gorm:"not null"`
+	WinLength int         `json:"win_length" gorm:"not null"`
+	Board     string      `json:"board" gorm:"not null"`  // Cells row by row, '.' for empty
+	NextTurn  string      `json:"next_turn"`              // X or O while the match is in progress
+	Status    string      `json:"status" gorm:"not null"` // in_progress, won, draw
+	Winner    string      `json:"winner"`                 // X or O once won
+	Moves     []MatchMove `json:"moves,omitempty" gorm:"foreignKey:MatchID"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// MatchMove records a single move; Seq numbers the moves of a match from 1.
+type MatchMove struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MatchID   uint      `json:"match_id" gorm:"not null"`
+	Seq       int       `json:"seq" gorm:"not null"`
+	PlayerID  string    `json:"player_id" gorm:"not null"`
+	Mark      string    `json:"mark" gorm:"not null"`
+	Row       int       `json:"row"`
+	Col       int       `json:"col"`
+	CreatedAt time.Time `json:"created_at"`
+}
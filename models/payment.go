@@ -1,6 +1,7 @@
 package models
 
 import (
+	"slices"
 	"time"
 )
 
@@ -8,12 +9,27 @@ import (
 type Payment struct {
 	ID            uint      `json:"id" gorm:"primaryKey"`
 	PlayerID      uint      `json:"player_id" gorm:"not null"`
-	Method        string    `json:"method" gorm:"not null"` // e.g., CreditCard, BankTransfer, ThirdParty, Blockchain
-	Amount        float64   `json:"amount" gorm:"not null"`
+	Method        string    `json:"method" gorm:"not null;index:idx_payments_transaction"` // e.g., CreditCard, BankTransfer, ThirdParty, Blockchain
+	Amount        int64     `json:"amount" gorm:"not null"` // In cents
 	Details       string    `json:"details" gorm:"type:text"` // JSON string containing payment method details
-	Status        string    `json:"status" gorm:"not null"`  // e.g., Pending, Success, Failed
-	TransactionID string    `json:"transaction_id"`            // Populated on success
+	Status        string    `json:"status" gorm:"not null"`  // e.g., Pending, Success, Failed, Refunded
+	TransactionID string    `json:"transaction_id" gorm:"index:idx_payments_transaction"` // The provider's, populated once authorized
 	ErrorMessage  string    `json:"error_message"`             // Populated on failure
+	ProcessAt     *time.Time `json:"-" gorm:"index"`            // When the charge is next due; nil once it is left to the provider's callback
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-}
\ No newline at end of file
+}
+
+// paymentTransitions lists the statuses a payment may move to from each
+// status.
+var paymentTransitions = map[string][]string{
+	"Pending": {"Success", "Failed"},
+	"Success": {"Refunded"},
+}
+
+// CanMoveTo reports whether the payment may move to status. Staying put is
+// always allowed, so repeating an update is harmless; moving back, such as
+// from Failed to Success, is not.
+func (p Payment) CanMoveTo(status string) bool {
+	return status == p.Status || slices.Contains(paymentTransitions[p.Status], status)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// PlayerSeed is the seed pair a player's next challenges are played with.
+// The pair is replaced as soon as a challenge played with it is resolved,
+// and only the hash of the server seed is shown until every challenge
+// played with it is, so no challenge is entered with a server seed the
+// player has seen.
+type PlayerSeed struct {
+	PlayerID       uint      `json:"player_id" gorm:"primaryKey;autoIncrement:false"`
+	ServerSeed     string    `json:"-" gorm:"not null"`
+	ServerSeedHash string    `json:"server_seed_hash" gorm:"not null"`
+	ClientSeed     string    `json:"client_seed" gorm:"not null"`     // Used by entries that bring none
+	Nonce          uint64    `json:"nonce" gorm:"not null;default:0"` // Challenges entered with the pair; the next one gets nonce + 1
+	CreatedAt      time.Time `json:"created_at" gorm:"not null"`
+}
@@ -1,8 +1,18 @@
 package models
 
+import "gorm.io/gorm"
+
 type Player struct {
     ID      string `json:"id" gorm:"primaryKey"`
     Name    string `json:"name"`
     LevelID string `json:"level_id"`
-    levle Level `json:"level" gorm:"foreignKey:LevelID"`
-}
\ No newline at end of file
+    Level   *Level `json:"level,omitempty" gorm:"foreignKey:LevelID"`
+}
+
+// BeforeCreate assigns a generated ID when the client did not supply one.
+func (p *Player) BeforeCreate(tx *gorm.DB) error {
+    if p.ID == "" {
+        p.ID = NewID()
+    }
+    return nil
+}
@@ -1,14 +1,63 @@
 package models
 
-import "time"
+import (
+    "time"
 
-// Reservation represents a reservation for a game room
+    "gorm.io/gorm"
+)
+
+// Reservation statuses. A booking starts out booked and ends up completed
+// after a check-in, or cancelled or no_show otherwise.
+const (
+    ReservationStatusBooked    = "booked"
+    ReservationStatusCheckedIn = "checked_in"
+    ReservationStatusCancelled = "cancelled"
+    ReservationStatusNoShow    = "no_show"
+    ReservationStatusCompleted = "completed"
+)
+
+// Reservation represents a reservation for a game room, made by a host
+// player who may invite guests. StartAt and EndAt are stored in UTC;
+// TimeZone is the IANA zone they are presented in.
 type Reservation struct {
     ID        uint      `json:"id" gorm:"primaryKey"`
     RoomID    uint      `json:"room_id" gorm:"not null"`
-    Date      time.Time `json:"date"`
-    Time      string    `json:"time"` 
-    PlayerInfo string    `json:"player_info"` 
+    Room      *Room     `json:"room,omitempty" gorm:"foreignKey:RoomID"`
+    HostID    string    `json:"host_id" gorm:"index"` // Empty once the host's account is deleted
+    Host      *Player   `json:"host,omitempty" gorm:"foreignKey:HostID"`
+    Guests    []Player  `json:"guests" gorm:"many2many:reservation_guests"`
+    StartAt   time.Time `json:"start_at" gorm:"not null"`
+    EndAt     time.Time `json:"end_at" gorm:"not null"` // Exclusive, so back-to-back slots do not overlap
+    TimeZone  string    `json:"time_zone" gorm:"not null;default:UTC"`
+    Note      string    `json:"note"` // Free text; holds the player info of bookings made before hosts
+    Status    string    `json:"status" gorm:"not null;default:booked"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
+
+// PlayerIDs returns the host followed by the guests.
+func (r Reservation) PlayerIDs() []string {
+    ids := make([]string, 0, len(r.Guests)+1)
+    if r.HostID != "" {
+        ids = append(ids, r.HostID)
+    }
+    for _, guest := range r.Guests {
+        ids = append(ids, guest.ID)
+    }
+    return ids
+}
+
+// AfterFind presents the slot in the reservation's own time zone.
+func (r *Reservation) AfterFind(tx *gorm.DB) error {
+    if loc, err := time.LoadLocation(r.TimeZone); err == nil {
+        r.StartAt = r.StartAt.In(loc)
+        r.EndAt = r.EndAt.In(loc)
+    }
+    return nil
+}
+
+// HoldsSlot reports whether the reservation keeps its slot from being booked
+// again; cancelled bookings and no-shows release it.
+func (r Reservation) HoldsSlot() bool {
+    return r.Status != ReservationStatusCancelled && r.Status != ReservationStatusNoShow
+}
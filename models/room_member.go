@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RoomMember is a player seated in a room. A player sits in at most one room
+// at a time; seats are numbered from 1 up to the room capacity.
+type RoomMember struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	RoomID   uint      `json:"room_id" gorm:"not null"`
+	PlayerID string    `json:"player_id" gorm:"not null"`
+	Seat     int       `json:"seat" gorm:"not null"`
+	JoinedAt time.Time `json:"joined_at" gorm:"autoCreateTime"`
+}
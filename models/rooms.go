@@ -2,12 +2,37 @@ package models
 
 import "time"
 
+// Room statuses. Open, full and in_game follow from the members and matches
+// of the room; closed and maintenance are set explicitly and take the room
+// out of service.
+const (
+    RoomStatusOpen        = "open"
+    RoomStatusFull        = "full"
+    RoomStatusInGame      = "in_game"
+    RoomStatusClosed      = "closed"
+    RoomStatusMaintenance = "maintenance"
+)
+
+// DefaultRoomCapacity is the number of seats given to rooms created without one.
+const DefaultRoomCapacity = 2
+
 // Room represents a game room
 type Room struct {
-    ID          uint      `json:"id" gorm:"primaryKey"`
-    Name        string    `json:"name"`
-    Description string    `json:"description"`
-    Status      string    `json:"status"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID           uint          `json:"id" gorm:"primaryKey"`
+    Name         string        `json:"name"`
+    Description  string        `json:"description"`
+    Capacity     int           `json:"capacity"`  // Number of seats, defaults to 2
+    Status       string        `json:"status"`    // open, full, in_game, closed or maintenance
+    OpensAt      string        `json:"opens_at"`  // Local "HH:MM", defaults to 00:00
+    ClosesAt     string        `json:"closes_at"` // Local "HH:MM", defaults to 24:00; before OpensAt means closing after midnight
+    TimeZone     string        `json:"time_zone"` // IANA zone of the opening hours, defaults to UTC
+    Members      []RoomMember  `json:"members,omitempty" gorm:"foreignKey:RoomID"`
+    Reservations []Reservation `json:"reservations,omitempty" gorm:"foreignKey:RoomID"`
+    CreatedAt    time.Time     `json:"created_at"`
+    UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// InService reports whether the room is neither closed nor under maintenance.
+func (r Room) InService() bool {
+    return r.Status != RoomStatusClosed && r.Status != RoomStatusMaintenance
 }
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Wallet holds a player's balance in minor units (cents). It is the cached
+// sum of the player's ledger entries and is only changed together with them.
+type Wallet struct {
+	PlayerID  uint      `json:"player_id" gorm:"primaryKey;autoIncrement:false"`
+	Balance   int64     `json:"balance" gorm:"not null"` // In cents
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Ledger accounts besides the player wallets. Money paid in through the
+// payment system comes from AccountExternal; the house keeps the rake and
+// funds the jackpot seed.
+const (
+	AccountExternal = "external"
+	AccountHouse    = "house"
+	AccountJackpot  = "jackpot"
+)
+
+// Ledger entry kinds.
+const (
+	LedgerKindOpening       = "opening"
+	LedgerKindDeposit       = "deposit"
+	LedgerKindRefund        = "refund"
+	LedgerKindEntryFee      = "entry_fee"
+	LedgerKindJackpotSeed   = "jackpot_seed"
+	LedgerKindJackpotPayout = "jackpot_payout"
+)
+
+// LedgerEntry is one side of a money movement. The entries sharing a
+// transfer always sum to zero, so money is only ever moved between
+// accounts, never created or lost.
+type LedgerEntry struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Transfer  string    `json:"transfer" gorm:"not null;uniqueIndex:idx_ledger_transfer_account"` // e.g. payment:12 or challenge:7:entry
+	Account   string    `json:"account" gorm:"not null;uniqueIndex:idx_ledger_transfer_account;index:idx_ledger_account"`
+	Kind      string    `json:"kind" gorm:"not null"`
+	Amount    int64     `json:"amount" gorm:"not null"` // In cents; positive credits the account
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LedgerDiscrepancy is a broken ledger invariant found by a ledger check.
+type LedgerDiscrepancy struct {
+	Account  string `json:"account,omitempty"`
+	Transfer string `json:"transfer,omitempty"`
+	Expected int64  `json:"expected"` // Balance the ledger adds up to, or 0 for a transfer
+	Actual   int64  `json:"actual"`
+}
+
+// PlayerAccount names the ledger account of a player's wallet.
+func PlayerAccount(playerID uint) string {
+	return fmt.Sprintf("player:%d", playerID)
+}
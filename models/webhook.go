@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Webhook event types.
+const (
+	EventPaymentSucceeded  = "payment.succeeded"
+	EventPaymentFailed     = "payment.failed"
+	EventChallengeResolved = "challenge.resolved"
+	EventJackpotWon        = "jackpot.won"
+)
+
+// WebhookEvents lists every event type a webhook can subscribe to.
+var WebhookEvents = []string{EventPaymentSucceeded, EventPaymentFailed, EventChallengeResolved, EventJackpotWon}
+
+// Webhook delivery states.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed" // Gave up after the last attempt
+)
+
+// Webhook is a subscription to events, delivered by POST to URL and signed
+// with Secret.
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url" gorm:"not null"`
+	Events    []string  `json:"events" gorm:"serializer:json;not null"`
+	Secret    string    `json:"secret,omitempty" gorm:"not null"` // Only shown when the webhook is created
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookEvent is the body of every delivery. IDs are stable per event, so
+// receivers can drop the duplicates retries may bring.
+type WebhookEvent struct {
+	ID        string    `json:"id" example:"payment.succeeded:12"`
+	Type      string    `json:"type" example:"payment.succeeded"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"` // The payment, challenge or jackpot payout
+}
+
+// NewWebhookEvent returns the event of the given type about the subject
+// with the given ID.
+func NewWebhookEvent(eventType string, subjectID uint, data any, now time.Time) WebhookEvent {
+	return WebhookEvent{ID: fmt.Sprintf("%s:%d", eventType, subjectID), Type: eventType, CreatedAt: now, Data: data}
+}
+
+// WebhookDelivery is one event queued for one webhook, along with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventID        string     `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_event"`
+	Event          string     `json:"event" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"` // The JSON body sent
+	Status         string     `json:"status" gorm:"not null;index:idx_webhook_deliveries_due"`
+	Attempts       int        `json:"attempts" gorm:"not null"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_webhook_deliveries_due"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"` // HTTP status of the latest attempt
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
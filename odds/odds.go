@@ -0,0 +1,90 @@
+// Package odds computes the chance that a challenge entry wins. The model
+// is picked per challenge type in the configuration.
+package odds
+
+import (
+	"fmt"
+
+	"interview_YangYang_20241010/config"
+)
+
+// History is what the models know about the player making an entry.
+type History struct {
+	Participations int    // The player's earlier entries
+	LossStreak     int    // Resolved entries lost in a row since the player's last win
+	LevelID        string // The player's level, empty when unknown
+}
+
+// Model computes the win chance of an entry, a probability from 0 to 1.
+type Model interface {
+	Name() string
+	Chance(h History) float64
+}
+
+// New returns the model selected by cfg.
+func New(cfg config.OddsConfig) (Model, error) {
+	switch cfg.Model {
+	case config.OddsFlat:
+		return Flat{P: cfg.Base}, nil
+	case config.OddsLinear:
+		return Linear{Base: cfg.Base, Step: cfg.Step, Cap: cfg.Cap}, nil
+	case config.OddsStreak:
+		return Streak{Base: cfg.Base, Step: cfg.Step, Cap: cfg.Cap}, nil
+	case config.OddsLevel:
+		return Level{Base: cfg.Base, Levels: cfg.Levels}, nil
+	}
+	return nil, fmt.Errorf("unknown odds model %q", cfg.Model)
+}
+
+// Flat gives every entry the same chance.
+type Flat struct {
+	P float64
+}
+
+func (Flat) Name() string { return config.OddsFlat }
+
+func (m Flat) Chance(History) float64 { return clamp(m.P) }
+
+// Linear starts at Base and adds Step for each earlier entry, up to Cap.
+type Linear struct {
+	Base, Step, Cap float64
+}
+
+func (Linear) Name() string { return config.OddsLinear }
+
+func (m Linear) Chance(h History) float64 {
+	return clamp(min(m.Base+m.Step*float64(h.Participations), m.Cap))
+}
+
+// Streak starts at Base and adds Step for each loss in a row, up to Cap. A
+// win starts the player over at Base.
+type Streak struct {
+	Base, Step, Cap float64
+}
+
+func (Streak) Name() string { return config.OddsStreak }
+
+func (m Streak) Chance(h History) float64 {
+	return clamp(min(m.Base+m.Step*float64(h.LossStreak), m.Cap))
+}
+
+// Level looks the chance up by the player's level, falling back to Base for
+// levels not listed.
+type Level struct {
+	Base   float64
+	Levels map[string]float64
+}
+
+func (Level) Name() string { return config.OddsLevel }
+
+func (m Level) Chance(h History) float64 {
+	if p, ok := m.Levels[h.LevelID]; ok {
+		return clamp(p)
+	}
+	return clamp(m.Base)
+}
+
+// clamp keeps a chance within [0, 1].
+func clamp(p float64) float64 {
+	return max(0, min(p, 1))
+}
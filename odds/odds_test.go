@@ -0,0 +1,49 @@
+package odds
+
+import (
+	"testing"
+
+	"interview_YangYang_20241010/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSelectsTheConfiguredModel(t *testing.T) {
+	for _, name := range []string{config.OddsFlat, config.OddsLinear, config.OddsStreak, config.OddsLevel} {
+		m, err := New(config.OddsConfig{Model: name})
+		if assert.NoError(t, err) {
+			assert.Equal(t, name, m.Name())
+		}
+	}
+	_, err := New(config.OddsConfig{Model: "dice"})
+	assert.Error(t, err)
+}
+
+func TestFlatIgnoresHistory(t *testing.T) {
+	m := Flat{P: 0.01}
+	assert.Equal(t, 0.01, m.Chance(History{}))
+	// The 100th entry is no more certain than the first
+	assert.Equal(t, 0.01, m.Chance(History{Participations: 99, LossStreak: 99}))
+}
+
+func TestLinearRampsUpToCap(t *testing.T) {
+	m := Linear{Base: 0.01, Step: 0.01, Cap: 0.05}
+	assert.InDelta(t, 0.01, m.Chance(History{}), 1e-9)
+	assert.InDelta(t, 0.03, m.Chance(History{Participations: 2}), 1e-9)
+	assert.Equal(t, 0.05, m.Chance(History{Participations: 99}))
+}
+
+func TestStreakGrowsWithLossesInARow(t *testing.T) {
+	m := Streak{Base: 0.01, Step: 0.02, Cap: 0.1}
+	assert.InDelta(t, 0.01, m.Chance(History{Participations: 40}), 1e-9)
+	assert.InDelta(t, 0.07, m.Chance(History{Participations: 40, LossStreak: 3}), 1e-9)
+	assert.Equal(t, 0.1, m.Chance(History{LossStreak: 50}))
+}
+
+func TestLevelLooksUpThePlayersLevel(t *testing.T) {
+	m := Level{Base: 0.01, Levels: map[string]float64{"1": 0.02, "2": 0.05, "cheat": 3}}
+	assert.Equal(t, 0.05, m.Chance(History{LevelID: "2"}))
+	assert.Equal(t, 0.01, m.Chance(History{LevelID: "9"}))
+	assert.Equal(t, 0.01, m.Chance(History{}))
+	assert.Equal(t, 1.0, m.Chance(History{LevelID: "cheat"}))
+}
@@ -0,0 +1,205 @@
+// realtime/hub.go
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"interview_YangYang_20241010/config"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event types broadcast to room subscribers.
+const (
+	EventRoomUpdated  = "room.updated"
+	EventRoomDeleted  = "room.deleted"
+	EventPlayerJoined = "player.joined" // Took a seat in the room
+	EventPlayerLeft   = "player.left"   // Gave up their seat
+	EventMatchCreated = "match.created"
+	EventMatchMove    = "match.move"
+
+	EventPlayerConnected    = "player.connected" // Opened a room WebSocket
+	EventPlayerDisconnected = "player.disconnected"
+)
+
+// maxMessageSize bounds the messages accepted from a client.
+const maxMessageSize = 4096
+
+// Event is a message sent to every client watching a room.
+type Event struct {
+	Type   string `json:"type"`
+	RoomID uint   `json:"room_id"`
+	Data   any    `json:"data,omitempty"`
+}
+
+// Publisher broadcasts events to the clients of a room.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Hub fans events out to the clients subscribed to each room. Every client
+// has a bounded queue; a client whose queue is full when an event arrives is
+// too slow to keep up and is disconnected rather than holding up the others.
+type Hub struct {
+	cfg   config.RealtimeConfig
+	mu    sync.Mutex
+	rooms map[uint]map[*Client]struct{}
+}
+
+// Client is a single subscriber to a room.
+type Client struct {
+	RoomID uint
+	send   chan []byte
+}
+
+// NewHub creates an empty hub.
+func NewHub(cfg config.RealtimeConfig) *Hub {
+	return &Hub{cfg: cfg, rooms: map[uint]map[*Client]struct{}{}}
+}
+
+var _ Publisher = (*Hub)(nil)
+
+// Subscribe registers a new client for the room's events.
+func (h *Hub) Subscribe(roomID uint) *Client {
+	c := &Client{RoomID: roomID, send: make(chan []byte, h.cfg.SendBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = map[*Client]struct{}{}
+	}
+	h.rooms[roomID][c] = struct{}{}
+	return c
+}
+
+// Unsubscribe removes the client and closes its queue. It is safe to call
+// more than once.
+func (h *Hub) Unsubscribe(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(c)
+}
+
+// remove must be called with h.mu held.
+func (h *Hub) remove(c *Client) {
+	clients, ok := h.rooms[c.RoomID]
+	if !ok {
+		return
+	}
+	if _, ok := clients[c]; !ok {
+		return
+	}
+	delete(clients, c)
+	close(c.send)
+	if len(clients) == 0 {
+		delete(h.rooms, c.RoomID)
+	}
+}
+
+// Publish queues the event for every client in the room without blocking.
+func (h *Hub) Publish(event Event) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("realtime: cannot encode %s event: %v", event.Type, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.rooms[event.RoomID] {
+		h.enqueue(c, msg)
+	}
+}
+
+// Reply queues a message for a single client.
+func (h *Hub) Reply(c *Client, reply any) {
+	msg, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("realtime: cannot encode reply: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.rooms[c.RoomID][c]; ok {
+		h.enqueue(c, msg)
+	}
+}
+
+// enqueue must be called with h.mu held.
+func (h *Hub) enqueue(c *Client, msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		log.Printf("realtime: dropping slow client in room %d", c.RoomID)
+		h.remove(c)
+	}
+}
+
+// Clients returns how many clients are subscribed to the room.
+func (h *Hub) Clients(roomID uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.rooms[roomID])
+}
+
+// Serve relays the client's events to conn and passes every message read
+// from conn to handle, queueing any non-nil reply back to the client. Pings
+// are sent every PingInterval and the connection is dropped when no pong
+// arrives within PongTimeout. Serve blocks until the connection closes and
+// always unsubscribes the client.
+func (h *Hub) Serve(conn *websocket.Conn, c *Client, handle func(msg []byte) any) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.writePump(conn, c)
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(h.cfg.PongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.cfg.PongTimeout))
+	})
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if reply := handle(msg); reply != nil {
+			h.Reply(c, reply)
+		}
+	}
+
+	h.Unsubscribe(c)
+	<-done
+}
+
+// writePump is the only goroutine writing to conn.
+func (h *Hub) writePump(conn *websocket.Conn, c *Client) {
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+			if !ok {
+				// Unsubscribed, either by the reader or for being too slow
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.WriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// realtime/hub_test.go
+package realtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig() config.RealtimeConfig {
+	return config.RealtimeConfig{
+		SendBuffer:   2,
+		PingInterval: 50 * time.Millisecond,
+		PongTimeout:  time.Second,
+		WriteTimeout: time.Second,
+	}
+}
+
+func TestPublishOnlyReachesTheRoom(t *testing.T) {
+	hub := NewHub(testConfig())
+	a := hub.Subscribe(1)
+	b := hub.Subscribe(2)
+
+	hub.Publish(Event{Type: EventRoomUpdated, RoomID: 1})
+
+	var event Event
+	assert.NoError(t, json.Unmarshal(<-a.send, &event))
+	assert.Equal(t, EventRoomUpdated, event.Type)
+	assert.Equal(t, uint(1), event.RoomID)
+	assert.Empty(t, b.send)
+}
+
+func TestSlowClientIsDropped(t *testing.T) {
+	hub := NewHub(testConfig())
+	slow := hub.Subscribe(1)
+	fast := hub.Subscribe(1)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(Event{Type: EventMatchMove, RoomID: 1})
+		<-fast.send
+	}
+
+	// The slow client's queue overflowed on the third event
+	assert.Equal(t, 1, hub.Clients(1))
+	assert.Len(t, slow.send, 2)
+	<-slow.send
+	<-slow.send
+	_, ok := <-slow.send
+	assert.False(t, ok)
+
+	// Unsubscribing a dropped client is harmless
+	hub.Unsubscribe(slow)
+	hub.Unsubscribe(fast)
+	assert.Zero(t, hub.Clients(1))
+}
+
+func TestServe(t *testing.T) {
+	hub := NewHub(testConfig())
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, hub.Subscribe(7), func(msg []byte) any {
+			return map[string]string{"echo": string(msg)}
+		})
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	// Replies go to the sender only
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	var reply map[string]string
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, "hello", reply["echo"])
+
+	hub.Publish(Event{Type: EventPlayerJoined, RoomID: 7})
+	var event Event
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, EventPlayerJoined, event.Type)
+
+	// Ping handlers run while reading, so keep a read pending
+	go conn.ReadMessage()
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("no heartbeat received")
+	}
+}
@@ -0,0 +1,151 @@
+// repository/challenge_definitions.go
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrChallengeDefinitionNotFound = errors.New("challenge definition not found")
+	ErrChallengeDefinitionExists   = errors.New("a challenge definition with this name already exists")
+	ErrChallengeDefinitionInUse    = errors.New("challenge definition has entries")
+	ErrChallengeNotRunning         = errors.New("challenge is not running")
+	ErrDailyEntryLimit             = errors.New("player reached the daily entry limit of the challenge")
+)
+
+// GetChallengeDefinitions retrieves every challenge definition, oldest first.
+func (s *GormStore) GetChallengeDefinitions() ([]models.ChallengeDefinition, error) {
+	var definitions []models.ChallengeDefinition
+	if err := s.db.Order("id").Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// GetChallengeDefinitionByID retrieves a challenge definition by its ID.
+func (s *GormStore) GetChallengeDefinitionByID(id uint) (*models.ChallengeDefinition, error) {
+	var definition models.ChallengeDefinition
+	err := s.db.First(&definition, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChallengeDefinitionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// CreateChallengeDefinition adds a challenge definition. Names are unique.
+func (s *GormStore) CreateChallengeDefinition(definition models.ChallengeDefinition) (uint, error) {
+	definition.ID = 0
+	err := s.db.Create(&definition).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return 0, ErrChallengeDefinitionExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return definition.ID, nil
+}
+
+// UpdateChallengeDefinition replaces the settings of a challenge definition.
+// Entries already made keep the fee, resolve time and win chance they were
+// given at entry.
+func (s *GormStore) UpdateChallengeDefinition(id uint, updated models.ChallengeDefinition) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var definition models.ChallengeDefinition
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&definition, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChallengeDefinitionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		updated.ID, updated.CreatedAt = definition.ID, definition.CreatedAt
+		err = tx.Save(&updated).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrChallengeDefinitionExists
+		}
+		return err
+	})
+}
+
+// DeleteChallengeDefinition removes a challenge definition nobody entered.
+// Definitions with entries are ended by setting EndsAt instead, so the
+// entries keep pointing at the settings they were made under.
+func (s *GormStore) DeleteChallengeDefinition(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var entries int64
+		if err := tx.Model(&models.Challenge{}).Where("definition_id = ?", id).Count(&entries).Error; err != nil {
+			return err
+		}
+		if entries > 0 {
+			return ErrChallengeDefinitionInUse
+		}
+		result := tx.Delete(&models.ChallengeDefinition{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrChallengeDefinitionNotFound
+		}
+		return nil
+	})
+}
+
+// enterDefinition applies the definition a challenge names inside tx. It
+// checks that the definition is running at now, then returns it along with
+// cfg carrying the definition's fee, timing and odds in place of the
+// configured defaults. The challenge takes the definition's name as its type
+// and its entry fee as its amount, and is due once the definition's
+// duration is over.
+func enterDefinition(tx *gorm.DB, challenge *models.Challenge, now time.Time, cfg config.ChallengeConfig) (*models.ChallengeDefinition, config.ChallengeConfig, error) {
+	var definition models.ChallengeDefinition
+	err := tx.First(&definition, *challenge.DefinitionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, cfg, ErrChallengeDefinitionNotFound
+	}
+	if err != nil {
+		return nil, cfg, err
+	}
+	if !definition.ActiveAt(now) {
+		return nil, cfg, ErrChallengeNotRunning
+	}
+
+	cfg.EntryFee, cfg.Duration, cfg.Cooldown = definition.EntryFee, definition.Duration(), definition.Cooldown()
+	cfg.Odds = map[string]config.OddsConfig{definition.Name: {
+		Model: definition.OddsModel, Base: definition.OddsBase, Step: definition.OddsStep,
+		Cap: definition.OddsCap, Levels: definition.OddsLevels,
+	}}
+	challenge.Type, challenge.Amount = definition.Name, definition.EntryFee
+	challenge.ResolveAt = now.Add(definition.Duration())
+	return &definition, cfg, nil
+}
+
+// checkDailyEntries fails with ErrDailyEntryLimit when the player already
+// made the definition's maximum number of entries on now's UTC day.
+func checkDailyEntries(tx *gorm.DB, playerID uint, definition models.ChallengeDefinition, now time.Time) error {
+	if definition.MaxEntriesPerDay <= 0 {
+		return nil
+	}
+	utc := now.UTC()
+	today := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+	var entries int64
+	err := tx.Model(&models.Challenge{}).
+		Where("player_id = ? AND definition_id = ? AND created_at >= ?", playerID, definition.ID, today).
+		Count(&entries).Error
+	if err != nil {
+		return err
+	}
+	if entries >= int64(definition.MaxEntriesPerDay) {
+		return ErrDailyEntryLimit
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// repository/challenge_definitions_test.go
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeDefinitionCRUD(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	weekend := models.ChallengeDefinition{
+		Name: "weekend", EntryFee: 500, DurationSeconds: 60, CooldownSeconds: 300,
+		OddsModel: config.OddsLevel, OddsBase: 0.01, OddsLevels: map[string]float64{"gold": 0.1},
+	}
+	id, err := store.CreateChallengeDefinition(weekend)
+	assert.NoError(t, err)
+	_, err = store.CreateChallengeDefinition(weekend)
+	assert.ErrorIs(t, err, ErrChallengeDefinitionExists)
+
+	stored, err := store.GetChallengeDefinitionByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "weekend", stored.Name)
+		assert.Equal(t, map[string]float64{"gold": 0.1}, stored.OddsLevels)
+		assert.Equal(t, time.Minute, stored.Duration())
+	}
+
+	end := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	weekend.EntryFee, weekend.EndsAt = 700, &end
+	assert.NoError(t, store.UpdateChallengeDefinition(id, weekend))
+	stored, err = store.GetChallengeDefinitionByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(700), stored.EntryFee)
+		if assert.NotNil(t, stored.EndsAt) {
+			assert.True(t, end.Equal(*stored.EndsAt))
+		}
+	}
+	assert.ErrorIs(t, store.UpdateChallengeDefinition(id+1, weekend), ErrChallengeDefinitionNotFound)
+
+	definitions, err := store.GetChallengeDefinitions()
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 1)
+
+	assert.NoError(t, store.DeleteChallengeDefinition(id))
+	_, err = store.GetChallengeDefinitionByID(id)
+	assert.ErrorIs(t, err, ErrChallengeDefinitionNotFound)
+	assert.ErrorIs(t, store.DeleteChallengeDefinition(id), ErrChallengeDefinitionNotFound)
+}
+
+func TestEntriesPlayByTheirDefinition(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2000, Cooldown: time.Hour, Odds: flatOdds(0)}
+	fundWallet(t, store, 1, 10000)
+
+	id, err := store.CreateChallengeDefinition(models.ChallengeDefinition{
+		Name: "sprint", EntryFee: 250, DurationSeconds: 120, OddsModel: config.OddsFlat, OddsBase: 0.25, MaxEntriesPerDay: 2,
+	})
+	assert.NoError(t, err)
+
+	// The endless cooldown does not hold back entries into the definition
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+	assert.NoError(t, err)
+	entryID, err := store.CreateChallenge(models.Challenge{PlayerID: 1, DefinitionID: &id}, cfg)
+	assert.NoError(t, err)
+
+	entry, err := store.GetChallengeByID(entryID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sprint", entry.Type)
+		assert.Equal(t, int64(250), entry.Amount)
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), entry.ResolveAt, 2*time.Second)
+		if assert.NotNil(t, entry.WinChance) {
+			assert.Equal(t, 0.25, *entry.WinChance)
+		}
+	}
+	assertBalance(t, store, 1, 10000-2000-250)
+	assertLedgerBalanced(t, store)
+
+	// Two entries a day
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, DefinitionID: &id}, cfg)
+	assert.NoError(t, err)
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, DefinitionID: &id}, cfg)
+	assert.ErrorIs(t, err, ErrDailyEntryLimit)
+
+	// Entered definitions can only be ended, not deleted
+	assert.ErrorIs(t, store.DeleteChallengeDefinition(id), ErrChallengeDefinitionInUse)
+	missing := id + 1
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, DefinitionID: &missing}, cfg)
+	assert.ErrorIs(t, err, ErrChallengeDefinitionNotFound)
+}
+
+func TestDefinitionsOnlyTakeEntriesWhileRunning(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	fundWallet(t, store, 1, 10000)
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	windows := []struct {
+		name       string
+		start, end *time.Time
+		wantErr    error
+	}{
+		{name: "upcoming", start: &future, wantErr: ErrChallengeNotRunning},
+		{name: "ended", end: &past, wantErr: ErrChallengeNotRunning},
+		{name: "running", start: &past, end: &future},
+	}
+	for _, w := range windows {
+		id, err := store.CreateChallengeDefinition(models.ChallengeDefinition{
+			Name: w.name, EntryFee: 100, OddsModel: config.OddsFlat, StartsAt: w.start, EndsAt: w.end,
+		})
+		assert.NoError(t, err)
+		_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, DefinitionID: &id}, config.ChallengeConfig{})
+		if w.wantErr != nil {
+			assert.ErrorIs(t, err, w.wantErr, w.name)
+		} else {
+			assert.NoError(t, err, w.name)
+		}
+	}
+	assertBalance(t, store, 1, 9900)
+}
@@ -2,53 +2,120 @@ package repository
 
 import (
     "errors"
+    "fmt"
+    "math"
+    "strconv"
     "time"
 
+    "interview_YangYang_20241010/config"
+    "interview_YangYang_20241010/fairness"
     "interview_YangYang_20241010/models"
+    "interview_YangYang_20241010/odds"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 // Define custom errors
 var (
-    ErrPlayerNotAllowed   = errors.New("player can only participate once per minute")
-    ErrChallengeNotFound  = errors.New("challenge not found")
+    ErrPlayerNotAllowed     = errors.New("player is still in challenge cooldown")
+    ErrChallengeNotFound    = errors.New("challenge not found")
+    ErrChallengeResolved    = errors.New("challenge is already resolved or being resolved")
+    ErrUnknownChallengeType = errors.New("no odds are configured for the challenge type")
 )
 
 // CreateChallenge adds a new challenge to the database after validating participation rules.
-func CreateChallenge(challenge models.Challenge) (uint, error) {
-    // Check if the player has participated in the last minute
-    oneMinuteAgo := time.Now().Add(-1 * time.Minute)
-    var count int64
-    if err := DB.Model(&models.Challenge{}).
-        Where("player_id = ? AND created_at > ?", challenge.PlayerID, oneMinuteAgo).
-        Count(&count).Error; err != nil {
-        return 0, err
-    }
-    if count > 0 {
-        return 0, ErrPlayerNotAllowed
-    }
+// A player may only participate once per cooldown period. The entry fee is debited from
+// the player's wallet and, minus the house rake, added to the jackpot in the same
+// transaction, so a challenge never exists without its paid entry. The challenge starts out pending
+// and is due for resolution after the challenge duration unless ResolveAt is set. It is
+// played with the player's seed pair, whose server seed hash was published before entry, and its win chance
+// is computed with the odds model configured for its type. A challenge naming a definition
+// is played by the definition's settings instead of cfg's, and its cooldown only counts
+// entries into the same definition. The cooldown is claimed atomically, so concurrent
+// requests from one player cannot both enter.
+func (s *GormStore) CreateChallenge(challenge models.Challenge, cfg config.ChallengeConfig) (uint, error) {
+    challenge.Status = models.ChallengeStatusPending
+
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        now := time.Now().UTC()
+        scope := models.CooldownScopeEndless
+        var definition *models.ChallengeDefinition
+        if challenge.DefinitionID != nil {
+            var err error
+            if definition, cfg, err = enterDefinition(tx, &challenge, now, cfg); err != nil {
+                return err
+            }
+            scope = models.CooldownScope(definition.ID)
+        }
+        if challenge.ResolveAt.IsZero() {
+            challenge.ResolveAt = now.Add(cfg.Duration)
+        }
+        challenge.ResolveAt = challenge.ResolveAt.UTC().Truncate(time.Second)
+        if challenge.Type == "" {
+            challenge.Type = models.ChallengeTypeEndless
+        }
+
+        // Claim the cooldown first: it holds the player's other entries into
+        // the scope until this one commits, so the checks below cannot race
+        if err := claimCooldown(tx, challenge.PlayerID, scope, now, cfg.Cooldown); err != nil {
+            return err
+        }
+        if definition != nil {
+            if err := checkDailyEntries(tx, challenge.PlayerID, *definition, now); err != nil {
+                return err
+            }
+        }
+
+        // The win chance is fixed at entry, from the player's record so far
+        history, err := playerHistory(tx, challenge)
+        if err != nil {
+            return err
+        }
+        if err := assessOdds(&challenge, history, cfg); err != nil {
+            return err
+        }
+        // Played with the seed pair whose hash the player could see before entering
+        if err := commitSeed(tx, &challenge); err != nil {
+            return err
+        }
+
+        // Create the challenge
+        if err := tx.Create(&challenge).Error; err != nil {
+            return err
+        }
 
-    // Create the challenge
-    if err := DB.Create(&challenge).Error; err != nil {
+        // Charge the entry fee: the share left after the rake goes to the
+        // jackpot, rounded to the nearest cent; the house keeps the rest
+        fee := challenge.Amount
+        share := int64(math.Round(float64(fee) * (1 - cfg.Rake)))
+        err = post(tx, fmt.Sprintf("challenge:%d:entry", challenge.ID), models.LedgerKindEntryFee,
+            walletLeg(challenge.PlayerID, -fee),
+            accountLeg(models.AccountJackpot, share),
+            accountLeg(models.AccountHouse, fee-share))
+        if err != nil {
+            return err
+        }
+        return addToJackpot(tx, share, cfg.JackpotSeed)
+    })
+    if err != nil {
         return 0, err
     }
-
     return challenge.ID, nil
 }
 
 // GetRecentChallengeResults retrieves the most recent challenges up to the specified limit.
-func GetRecentChallengeResults(limit int) ([]models.Challenge, error) {
+func (s *GormStore) GetRecentChallengeResults(limit int) ([]models.Challenge, error) {
     var challenges []models.Challenge
-    if err := DB.Order("created_at desc").Limit(limit).Find(&challenges).Error; err != nil {
+    if err := s.db.Order("created_at desc").Limit(limit).Find(&challenges).Error; err != nil {
         return nil, err
     }
     return challenges, nil
 }
 
 // GetChallengeByID retrieves a challenge by its ID.
-func GetChallengeByID(id uint) (*models.Challenge, error) {
+func (s *GormStore) GetChallengeByID(id uint) (*models.Challenge, error) {
     var challenge models.Challenge
-    if err := DB.First(&challenge, id).Error; err != nil {
+    if err := s.db.First(&challenge, id).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
             return nil, ErrChallengeNotFound
         }
@@ -58,17 +125,254 @@ func GetChallengeByID(id uint) (*models.Challenge, error) {
 }
 
 // UpdateChallenge updates the outcome of a challenge.
-func UpdateChallenge(challenge models.Challenge) error {
-    return DB.Save(&challenge).Error
+func (s *GormStore) UpdateChallenge(challenge models.Challenge) error {
+    return s.db.Save(&challenge).Error
+}
+
+// GetPlayerChallenges retrieves a page of a player's challenges, newest
+// first, together with the number of challenges the player has entered.
+func (s *GormStore) GetPlayerChallenges(playerID uint, limit, offset int) ([]models.Challenge, int64, error) {
+    var total int64
+    if err := s.db.Model(&models.Challenge{}).Where("player_id = ?", playerID).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var challenges []models.Challenge
+    err := s.db.Where("player_id = ?", playerID).Order("id desc").Limit(limit).Offset(offset).Find(&challenges).Error
+    if err != nil {
+        return nil, 0, err
+    }
+    return challenges, total, nil
+}
+
+// GetPlayerChallengeStats sums up a player's entries, their outcomes, the
+// entry fees paid and the jackpots won.
+func (s *GormStore) GetPlayerChallengeStats(playerID uint) (*models.ChallengeStats, error) {
+    var row struct {
+        Entries, Pending, Wins int64
+        Spent                  int64
+    }
+    err := s.db.Model(&models.Challenge{}).
+        Select("COUNT(*) AS entries, "+
+            "COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending, "+
+            "COALESCE(SUM(CASE WHEN status = ? AND won = ? THEN 1 ELSE 0 END), 0) AS wins, "+
+            "COALESCE(SUM(amount), 0) AS spent",
+            models.ChallengeStatusPending, models.ChallengeStatusResolved, true).
+        Where("player_id = ?", playerID).Scan(&row).Error
+    if err != nil {
+        return nil, err
+    }
+    var won int64
+    err = s.db.Model(&models.JackpotPayout{}).Select("COALESCE(SUM(amount), 0)").
+        Where("player_id = ?", playerID).Scan(&won).Error
+    if err != nil {
+        return nil, err
+    }
+    return &models.ChallengeStats{
+        PlayerID:   playerID,
+        Entries:    row.Entries,
+        Pending:    row.Pending,
+        Wins:       row.Wins,
+        TotalSpent: row.Spent,
+        TotalWon:   won,
+    }, nil
 }
 
 // GetPlayerParticipationCount retrieves the total number of participations by a player.
-func GetPlayerParticipationCount(playerID uint) (int, error) {
+func (s *GormStore) GetPlayerParticipationCount(playerID uint) (int, error) {
     var count int64
-    if err := DB.Model(&models.Challenge{}).
+    if err := s.db.Model(&models.Challenge{}).
         Where("player_id = ?", playerID).
         Count(&count).Error; err != nil {
         return 0, err
     }
     return int(count), nil
-}
\ No newline at end of file
+}
+
+// CountPendingChallengesBySeed counts the challenges played with the server
+// seed hashing to serverSeedHash that are not resolved yet. The seed must
+// stay secret while any are.
+func (s *GormStore) CountPendingChallengesBySeed(serverSeedHash string) (int64, error) {
+    var count int64
+    err := s.db.Model(&models.Challenge{}).
+        Where("status = ? AND server_seed_hash = ?", models.ChallengeStatusPending, serverSeedHash).
+        Count(&count).Error
+    return count, err
+}
+
+// GetDueChallenges retrieves pending challenges whose resolve time has come,
+// oldest first, up to limit.
+func (s *GormStore) GetDueChallenges(now time.Time, limit int) ([]models.Challenge, error) {
+    var challenges []models.Challenge
+    err := s.db.Where("status = ? AND resolve_at <= ?", models.ChallengeStatusPending, now.UTC()).
+        Order("resolve_at, id").Limit(limit).Find(&challenges).Error
+    return challenges, err
+}
+
+// ResolveChallenge settles a due pending challenge. The challenge row is
+// locked for the transaction and rows locked by another resolver are
+// skipped, so concurrent resolvers never settle the same challenge twice;
+// ErrChallengeResolved is returned to the one that loses. The challenge is
+// won when its provably fair roll falls below the win chance fixed at entry;
+// a winner is paid the jackpot, which then starts over from the configured
+// seed. The roll is stored so the outcome can be verified, and the
+// challenge.resolved webhook event is queued with the outcome.
+func (s *GormStore) ResolveChallenge(id uint, now time.Time, cfg config.ChallengeConfig) (*models.Challenge, error) {
+    var challenge models.Challenge
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+            Where("status = ? AND resolve_at <= ?", models.ChallengeStatusPending, now.UTC()).
+            First(&challenge, id).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return ErrChallengeResolved
+        }
+        if err != nil {
+            return err
+        }
+
+        // Challenges entered before seeds or odds existed get theirs now
+        if challenge.ServerSeed == "" {
+            if err := seedUncommitted(&challenge); err != nil {
+                return err
+            }
+        }
+        if challenge.WinChance == nil {
+            history, err := playerHistory(tx, challenge)
+            if err != nil {
+                return err
+            }
+            if err := assessOdds(&challenge, history, cfg); err != nil {
+                return err
+            }
+        }
+        chance := *challenge.WinChance
+        roll := fairness.Roll(challenge.ServerSeed, challenge.ClientSeed, challenge.Nonce)
+        won := roll < chance
+        if won {
+            if _, err := payJackpot(tx, challenge, cfg.JackpotSeed); err != nil {
+                return err
+            }
+        }
+
+        // The status guard keeps drivers without row locks from settling twice
+        resolvedAt := now.UTC()
+        result := tx.Model(&models.Challenge{}).
+            Where("id = ? AND status = ?", id, models.ChallengeStatusPending).
+            Updates(map[string]any{
+                "status": models.ChallengeStatusResolved, "won": won, "resolved_at": resolvedAt, "roll": roll,
+                "win_chance": chance, "odds_model": challenge.OddsModel,
+                "server_seed": challenge.ServerSeed, "server_seed_hash": challenge.ServerSeedHash, "client_seed": challenge.ClientSeed,
+            })
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrChallengeResolved
+        }
+        challenge.Status, challenge.Won, challenge.ResolvedAt = models.ChallengeStatusResolved, won, &resolvedAt
+        challenge.Roll = &roll
+        // Once one challenge on a seed is settled its seed may be revealed,
+        // so the player's next entries are played with a fresh one
+        if challenge.Committed() {
+            if err := rotateSeed(tx, challenge.PlayerID, challenge.ServerSeedHash); err != nil {
+                return err
+            }
+        }
+        return enqueueEvent(tx, models.EventChallengeResolved, challenge.ID, challenge, resolvedAt)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &challenge, nil
+}
+
+// claimCooldown records an entry by the player into scope at now, failing
+// with ErrPlayerNotAllowed when the last one was less than cooldown ago.
+// The check and the claim are one conditional upsert, so of two concurrent
+// entries only one can claim the cooldown; the other waits for the first
+// to commit on drivers with row locks and then sees its claim.
+func claimCooldown(tx *gorm.DB, playerID uint, scope string, now time.Time, cooldown time.Duration) error {
+    claim := models.ChallengeCooldown{PlayerID: playerID, Scope: scope, EnteredAt: now}
+    result := tx.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "player_id"}, {Name: "scope"}},
+        DoUpdates: clause.AssignmentColumns([]string{"entered_at"}),
+        Where: clause.Where{Exprs: []clause.Expression{
+            clause.Expr{SQL: "challenge_cooldowns.entered_at <= ?", Vars: []any{now.Add(-cooldown)}},
+        }},
+    }).Create(&claim)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrPlayerNotAllowed
+    }
+    return nil
+}
+
+// seedUncommitted draws the seeds of a challenge entered before seeds were
+// committed at entry. No hash of its server seed was published before the
+// outcome was decided, so none is recorded and it cannot be verified.
+func seedUncommitted(challenge *models.Challenge) error {
+    seed, err := fairness.NewSeed()
+    if err != nil {
+        return err
+    }
+    challenge.ServerSeed, challenge.ServerSeedHash = seed, ""
+    if challenge.ClientSeed == "" {
+        if challenge.ClientSeed, err = fairness.NewSeed(); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// playerHistory gathers what the odds models know about the player of a
+// challenge from the entries before it.
+func playerHistory(tx *gorm.DB, challenge models.Challenge) (odds.History, error) {
+    var history odds.History
+    earlier := tx.Model(&models.Challenge{}).Where("player_id = ?", challenge.PlayerID)
+    if challenge.ID != 0 {
+        earlier = earlier.Where("id < ?", challenge.ID)
+    }
+
+    var participations, streak int64
+    if err := earlier.Session(&gorm.Session{}).Count(&participations).Error; err != nil {
+        return history, err
+    }
+    lastWin := tx.Model(&models.Challenge{}).Select("COALESCE(MAX(id), 0)").
+        Where("player_id = ? AND won = ?", challenge.PlayerID, true)
+    err := earlier.Session(&gorm.Session{}).
+        Where("status = ? AND won = ? AND id > (?)", models.ChallengeStatusResolved, false, lastWin).
+        Count(&streak).Error
+    if err != nil {
+        return history, err
+    }
+
+    // Challenges name players by their numeric ID; unknown players have no level
+    var levels []string
+    err = tx.Model(&models.Player{}).Where("id = ?", strconv.FormatUint(uint64(challenge.PlayerID), 10)).
+        Pluck("level_id", &levels).Error
+    if err != nil {
+        return history, err
+    }
+    if len(levels) > 0 {
+        history.LevelID = levels[0]
+    }
+    history.Participations, history.LossStreak = int(participations), int(streak)
+    return history, nil
+}
+
+// assessOdds fixes the win chance of a challenge with the odds model
+// configured for its type.
+func assessOdds(challenge *models.Challenge, history odds.History, cfg config.ChallengeConfig) error {
+    oddsCfg, ok := cfg.Odds[challenge.Type]
+    if !ok {
+        return fmt.Errorf("%w: %s", ErrUnknownChallengeType, challenge.Type)
+    }
+    model, err := odds.New(oddsCfg)
+    if err != nil {
+        return err
+    }
+    chance := model.Chance(history)
+    challenge.OddsModel, challenge.WinChance = model.Name(), &chance
+    return nil
+}
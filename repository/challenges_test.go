@@ -2,73 +2,447 @@
 package repository
 
 import (
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/fairness"
 	"interview_YangYang_20241010/models"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateChallenge(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	fundWallet(t, store, 1, 2001)
 
 	challenge := models.Challenge{
 		PlayerID:  1, // Ensure a Player with ID 1 exists
-		Amount:    20.01,
+		Amount:    2001,
 		Won:       false,
 	}
 
-	challengeID, err := CreateChallenge(challenge)
+	challengeID, err := store.CreateChallenge(challenge, config.Default().Challenge)
 	assert.NoError(t, err)
 	assert.NotZero(t, challengeID)
 }
 
 func TestGetChallengeByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a challenge first
+	fundWallet(t, store, 2, 2001)
 	challenge := models.Challenge{
 		PlayerID:  2,
-		Amount:    20.01,
+		Amount:    2001,
 		Won:       false,
 	}
-	challengeID, err := CreateChallenge(challenge)
+	challengeID, err := store.CreateChallenge(challenge, config.Default().Challenge)
 	assert.NoError(t, err)
 
 	// Retrieve the challenge
-	retrievedChallenge, err := GetChallengeByID(challengeID)
+	retrievedChallenge, err := store.GetChallengeByID(challengeID)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(2), retrievedChallenge.PlayerID)
-	assert.Equal(t, 20.01, retrievedChallenge.Amount)
+	assert.Equal(t, int64(2001), retrievedChallenge.Amount)
 	assert.False(t, retrievedChallenge.Won)
 }
 
 func TestUpdateChallenge(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a challenge first
+	fundWallet(t, store, 3, 2001)
 	challenge := models.Challenge{
 		PlayerID:  3,
-		Amount:    20.01,
+		Amount:    2001,
 		Won:       false,
 	}
-	challengeID, err := CreateChallenge(challenge)
+	challengeID, err := store.CreateChallenge(challenge, config.Default().Challenge)
 	assert.NoError(t, err)
 
 	// Update the challenge's status to won
 	updatedChallenge := models.Challenge{
+		ID:        challengeID,
 		PlayerID:  3,
-		Amount:    20.01,
+		Amount:    2001,
 		Won:       true,
 	}
-	err = UpdateChallenge(updatedChallenge)
+	err = store.UpdateChallenge(updatedChallenge)
 	assert.NoError(t, err)
 
 	// Retrieve and verify the update
-	retrievedChallenge, err := GetChallengeByID(challengeID)
+	retrievedChallenge, err := store.GetChallengeByID(challengeID)
 	assert.NoError(t, err)
 	assert.True(t, retrievedChallenge.Won)
 }
+
+func TestResolveDueChallenges(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	// Endless entries always win, hopeless ones never do
+	cfg := config.ChallengeConfig{Rake: 0, JackpotSeed: 500, Odds: flatOdds(1)}
+	cfg.Odds["hopeless"] = config.OddsConfig{Model: config.OddsFlat}
+	start := time.Date(2024, 10, 10, 14, 0, 0, 0, time.UTC)
+	fundWallet(t, store, 1, 4000)
+	fundWallet(t, store, 2, 2000)
+
+	var ids []uint
+	for i, playerID := range []uint{1, 2, 1} {
+		challengeType := models.ChallengeTypeEndless
+		if i == 0 {
+			challengeType = "hopeless"
+		}
+		id, err := store.CreateChallenge(models.Challenge{PlayerID: playerID, Amount: 2000, Type: challengeType, ResolveAt: start.Add(time.Duration(i) * time.Minute)}, cfg)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	due, err := store.GetDueChallenges(start.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 2) {
+		assert.Equal(t, ids[0], due[0].ID)
+		assert.Equal(t, models.ChallengeStatusPending, due[0].Status)
+	}
+
+	// Not due yet
+	_, err = store.ResolveChallenge(ids[2], start, cfg)
+	assert.ErrorIs(t, err, ErrChallengeResolved)
+
+	lost, err := store.ResolveChallenge(ids[0], start, cfg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ChallengeStatusResolved, lost.Status)
+		assert.False(t, lost.Won)
+		assert.NotNil(t, lost.ResolvedAt)
+	}
+	_, err = store.ResolveChallenge(ids[0], start, cfg)
+	assert.ErrorIs(t, err, ErrChallengeResolved)
+
+	// A winner takes the jackpot
+	won, err := store.ResolveChallenge(ids[1], start.Add(time.Minute), cfg)
+	if assert.NoError(t, err) {
+		assert.True(t, won.Won)
+	}
+	payouts, err := store.GetJackpotPayouts(10)
+	assert.NoError(t, err)
+	if assert.Len(t, payouts, 1) {
+		assert.Equal(t, int64(6500), payouts[0].Amount)
+	}
+	stored, err := store.GetChallengeByID(ids[1])
+	assert.NoError(t, err)
+	assert.True(t, stored.Won)
+	assert.Equal(t, models.ChallengeStatusResolved, stored.Status)
+
+	due, err = store.GetDueChallenges(start.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+}
+
+func TestConcurrentResolversSettleOnce(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now()
+	fundWallet(t, store, 1, 2000)
+
+	cfg := config.ChallengeConfig{Odds: flatOdds(1)}
+	id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, ResolveAt: now.Add(-time.Second)}, cfg)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = store.ResolveChallenge(id, now, cfg)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	payouts, err := store.GetJackpotPayouts(10)
+	assert.NoError(t, err)
+	assert.Len(t, payouts, 1)
+}
+
+func TestResolvedChallengesAreVerifiable(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now()
+	fundWallet(t, store, 1, 4000)
+	cfg := config.ChallengeConfig{Odds: flatOdds(0.5)}
+
+	// The server seed is committed to before the client seed is known
+	pair, err := store.GetPlayerSeed(1)
+	assert.NoError(t, err)
+	assert.Equal(t, fairness.Hash(pair.ServerSeed), pair.ServerSeedHash)
+
+	var ids []uint
+	for _, clientSeed := range []string{"lucky", ""} {
+		id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, ResolveAt: now.Add(-time.Second), ClientSeed: clientSeed}, cfg)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	committed, err := store.GetChallengeByID(ids[0])
+	assert.NoError(t, err)
+	assert.True(t, committed.Committed())
+	assert.Equal(t, "lucky", committed.ClientSeed)
+	assert.Equal(t, uint64(1), committed.Nonce)
+	assert.Equal(t, pair.ServerSeedHash, committed.ServerSeedHash)
+	assert.Nil(t, committed.Roll)
+	second, err := store.GetChallengeByID(ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), second.Nonce)
+	assert.Equal(t, pair.ClientSeed, second.ClientSeed)
+	assert.Equal(t, pair.ServerSeed, second.ServerSeed)
+
+	resolved, err := store.ResolveChallenge(ids[0], now, cfg)
+	assert.NoError(t, err)
+	stored, err := store.GetChallengeByID(ids[0])
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored.Roll) && assert.NotNil(t, stored.WinChance) {
+		assert.Equal(t, fairness.Roll(committed.ServerSeed, "lucky", 1), *stored.Roll)
+		assert.Equal(t, 0.5, *stored.WinChance)
+		assert.Equal(t, *stored.Roll < 0.5, stored.Won)
+		assert.Equal(t, resolved.Won, stored.Won)
+		assert.True(t, fairness.Verify(stored.ServerSeed, committed.ServerSeedHash, stored.ClientSeed, stored.Nonce, *stored.Roll))
+	}
+}
+
+func TestResolvingRotatesPlayerSeed(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	fundWallet(t, store, 1, 6000)
+	cfg := config.ChallengeConfig{Odds: flatOdds(0)}
+
+	// Two entries share the pair until one of them is settled
+	first, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000}, cfg)
+	assert.NoError(t, err)
+	second, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000}, cfg)
+	assert.NoError(t, err)
+	old, err := store.GetChallengeByID(first)
+	assert.NoError(t, err)
+	pending, err := store.CountPendingChallengesBySeed(old.ServerSeedHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), pending)
+
+	assert.NoError(t, db.Model(&models.Challenge{}).Where("id IN ?", []uint{first, second}).
+		Update("resolve_at", time.Now().Add(-time.Minute).UTC()).Error)
+	_, err = store.ResolveChallenge(first, time.Now(), cfg)
+	assert.NoError(t, err)
+	pair, err := store.GetPlayerSeed(1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, old.ServerSeedHash, pair.ServerSeedHash)
+	assert.Equal(t, uint64(0), pair.Nonce)
+	pending, err = store.CountPendingChallengesBySeed(old.ServerSeedHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	// Later entries are played with the new pair, numbered from the start
+	third, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000}, cfg)
+	assert.NoError(t, err)
+	entered, err := store.GetChallengeByID(third)
+	assert.NoError(t, err)
+	assert.Equal(t, pair.ServerSeedHash, entered.ServerSeedHash)
+	assert.Equal(t, pair.ServerSeed, entered.ServerSeed)
+	assert.Equal(t, uint64(1), entered.Nonce)
+
+	// Settling the other entry on the old seed leaves the new pair alone
+	_, err = store.ResolveChallenge(second, time.Now(), cfg)
+	assert.NoError(t, err)
+	current, err := store.GetPlayerSeed(1)
+	assert.NoError(t, err)
+	assert.Equal(t, pair.ServerSeedHash, current.ServerSeedHash)
+	assert.Equal(t, uint64(1), current.Nonce)
+	pending, err = store.CountPendingChallengesBySeed(old.ServerSeedHash)
+	assert.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestLegacyPendingChallengesGetSeedsOnResolve(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now()
+
+	legacy := models.Challenge{PlayerID: 1, Amount: 2000, Status: models.ChallengeStatusPending, ResolveAt: now.Add(-time.Minute).UTC()}
+	assert.NoError(t, db.Create(&legacy).Error)
+
+	resolved, err := store.ResolveChallenge(legacy.ID, now, config.ChallengeConfig{Odds: flatOdds(0)})
+	assert.NoError(t, err)
+	assert.False(t, resolved.Won)
+	stored, err := store.GetChallengeByID(legacy.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stored.ServerSeed)
+	assert.NotEmpty(t, stored.ClientSeed)
+	// No hash was published before the outcome, so none is made up now
+	assert.Empty(t, stored.ServerSeedHash)
+	assert.False(t, stored.Committed())
+	// Their odds are assessed on resolve instead of at entry
+	assert.Equal(t, config.OddsFlat, stored.OddsModel)
+	if assert.NotNil(t, stored.WinChance) {
+		assert.Equal(t, 0.0, *stored.WinChance)
+	}
+}
+
+func TestOddsAreFixedAtEntry(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now()
+	fundWallet(t, store, 1, 10000)
+	_, err := store.CreatePlayer(models.Player{ID: "1", Name: "Alice", LevelID: "gold"})
+	assert.NoError(t, err)
+
+	cfg := config.ChallengeConfig{Odds: map[string]config.OddsConfig{
+		"endless": {Model: config.OddsStreak, Base: 0.1, Step: 0.1, Cap: 0.5},
+		"vip":     {Model: config.OddsLevel, Base: 0.01, Levels: map[string]float64{"gold": 0.2}},
+	}}
+
+	// Two lost entries grow the streak odds of the third
+	var ids []uint
+	for range 3 {
+		id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, ResolveAt: now.Add(-time.Second)}, cfg)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.NoError(t, db.Model(&models.Challenge{}).Where("id IN ?", ids[:2]).
+		Updates(map[string]any{"status": models.ChallengeStatusResolved, "won": false}).Error)
+	id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, ResolveAt: now.Add(-time.Second)}, cfg)
+	assert.NoError(t, err)
+	streak, err := store.GetChallengeByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, config.OddsStreak, streak.OddsModel)
+	if assert.NotNil(t, streak.WinChance) {
+		assert.InDelta(t, 0.3, *streak.WinChance, 1e-9)
+	}
+
+	id, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, Type: "vip", ResolveAt: now.Add(-time.Second)}, cfg)
+	assert.NoError(t, err)
+	vip, err := store.GetChallengeByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "vip", vip.Type)
+	assert.Equal(t, config.OddsLevel, vip.OddsModel)
+	if assert.NotNil(t, vip.WinChance) {
+		assert.Equal(t, 0.2, *vip.WinChance)
+	}
+
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, Type: "daily"}, cfg)
+	assert.ErrorIs(t, err, ErrUnknownChallengeType)
+}
+
+// flatOdds configures the endless challenge type to win with a fixed chance.
+func flatOdds(chance float64) map[string]config.OddsConfig {
+	return map[string]config.OddsConfig{models.ChallengeTypeEndless: {Model: config.OddsFlat, Base: chance}}
+}
+
+func TestPlayerChallengeHistoryAndStats(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2001, JackpotSeed: 500, Odds: flatOdds(1)}
+	fundWallet(t, store, 1, 6003)
+	fundWallet(t, store, 2, 2001)
+	now := time.Now()
+
+	var ids []uint
+	for i := range 3 {
+		id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee, ResolveAt: now.Add(time.Duration(i-1) * time.Hour)}, cfg)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	_, err := store.CreateChallenge(models.Challenge{PlayerID: 2, Amount: cfg.EntryFee}, cfg)
+	assert.NoError(t, err)
+	won, err := store.ResolveChallenge(ids[0], now, cfg)
+	assert.NoError(t, err)
+	assert.True(t, won.Won)
+
+	page, total, err := store.GetPlayerChallenges(1, 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, ids[2], page[0].ID)
+		assert.Equal(t, ids[1], page[1].ID)
+	}
+	page, _, err = store.GetPlayerChallenges(1, 2, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, ids[0], page[0].ID)
+	}
+
+	stats, err := store.GetPlayerChallengeStats(1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(3), stats.Entries)
+		assert.Equal(t, int64(2), stats.Pending)
+		assert.Equal(t, int64(1), stats.Wins)
+		assert.Equal(t, int64(6003), stats.TotalSpent)
+		// The seed plus every entry fee made so far, nothing raked
+		assert.Equal(t, int64(8504), stats.TotalWon)
+	}
+	stats, err = store.GetPlayerChallengeStats(3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ChallengeStats{PlayerID: 3}, *stats)
+	}
+}
+
+func TestConcurrentEntriesRespectTheCooldown(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 100, Cooldown: time.Minute, Odds: flatOdds(0)}
+	fundWallet(t, store, 1, 10000)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrPlayerNotAllowed) {
+			// SQLite turns some entries away as busy instead
+			t.Logf("entry failed: %v", err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	var entries int64
+	assert.NoError(t, db.Model(&models.Challenge{}).Where("player_id = ?", 1).Count(&entries).Error)
+	assert.Equal(t, int64(1), entries)
+	assertBalance(t, store, 1, 9900)
+	assertLedgerBalanced(t, store)
+
+	// Once the cooldown is over the player can enter again
+	cfg.Cooldown = 0
+	_, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+	assert.NoError(t, err)
+}
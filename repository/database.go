@@ -1,27 +1,66 @@
 package repository
 
 import (
+    "fmt"
     "log"
-    "interview_YangYang_20241010/models"
+    "interview_YangYang_20241010/config"
+    "interview_YangYang_20241010/migrations"
 
+    "github.com/glebarez/sqlite"
     "gorm.io/driver/postgres"
     "gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-// InitDB initializes the database connection and performs migrations
-func InitDB() {
-    dsn := "host=db user=archie password=postgres dbname=spinnerdb port=5432 sslmode=disable"
-    var err error
-    DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// InitDB initializes the database connection and verifies the schema is up to date
+func InitDB(cfg config.DatabaseConfig) *gorm.DB {
+    db, err := Open(cfg)
     if err != nil {
         log.Fatalf("Failed to connect to database: %v", err)
     }
 
-    // Perform migrations
-    err = DB.AutoMigrate(&models.Player{}, &models.Level{}, &models.Room{}, &models.Reservation{})
+    // Refuse to serve against a schema that has not been fully migrated
+    migrator, err := migrations.New(db, cfg.Driver)
+    if err != nil {
+        log.Fatalf("Failed to load migrations: %v", err)
+    }
+    if err := migrator.Check(); err != nil {
+        log.Fatalf("Database is not ready: %v (run the migrate subcommand first)", err)
+    }
+    return db
+}
+
+// Open connects to the database selected by cfg.Driver and applies the pool settings.
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+    var dialector gorm.Dialector
+    switch cfg.Driver {
+    case config.DriverPostgres:
+        dialector = postgres.Open(cfg.DSN())
+    case config.DriverSQLite:
+        dialector = sqlite.Open(sqliteDSN(cfg.Path))
+    default:
+        return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+    }
+
+    // Translate constraint violations into gorm.ErrDuplicatedKey and friends
+    db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+    if err != nil {
+        return nil, err
+    }
+
+    // Apply connection pool settings
+    sqlDB, err := db.DB()
     if err != nil {
-        log.Fatalf("Failed to migrate database: %v", err)
+        return nil, err
     }
-}
\ No newline at end of file
+    sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+    sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+    sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+    return db, nil
+}
+
+// sqliteDSN waits on locks instead of failing immediately when several
+// connections write to the same file.
+func sqliteDSN(path string) string {
+    return path + "?_pragma=busy_timeout(5000)"
+}
@@ -0,0 +1,69 @@
+// repository/database_test.go
+package repository
+
+import (
+	"testing"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestSetupTestDBIsIsolated(t *testing.T) {
+	first := SetupTestDB(t)
+	_, err := NewGormStore(first).CreateRoom(models.Room{Name: "Leftover"})
+	assert.NoError(t, err)
+	TearDownTestDB(first, t)
+
+	second := SetupTestDB(t)
+	defer TearDownTestDB(second, t)
+
+	rooms, err := NewGormStore(second).GetAllRooms()
+	assert.NoError(t, err)
+	assert.Empty(t, rooms)
+}
+
+// allModels lists every model that must have a table created by migrations.
+var allModels = []interface{}{
+	&models.Player{},
+	&models.Level{},
+	&models.Room{},
+	&models.Reservation{},
+	&models.Challenge{},
+	&models.ChallengeDefinition{},
+	&models.ChallengeCooldown{},
+	&models.PlayerSeed{},
+	&models.IdempotencyKey{},
+	&models.Log{},
+	&models.Payment{},
+	&models.Match{},
+	&models.MatchMove{},
+	&models.RoomMember{},
+	&models.Jackpot{},
+	&models.JackpotPayout{},
+	&models.Wallet{},
+	&models.Webhook{},
+	&models.WebhookDelivery{},
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+
+	for _, model := range allModels {
+		stmt := &gorm.Statement{DB: db}
+		assert.NoError(t, stmt.Parse(model))
+		table := stmt.Schema.Table
+
+		if !assert.True(t, db.Migrator().HasTable(table), "missing table %s", table) {
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "missing column %s.%s", table, field.DBName)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// repository/idempotency.go
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// ClaimIdempotencyKey claims key within scope for a request whose body
+// hashes to requestHash, naming the claim by token, which the caller draws
+// and must pass to complete or release it. It returns nil when the caller
+// claimed the key and should handle the request, and the stored key when an earlier request
+// with the same body already completed. It fails with
+// ErrIdempotencyKeyMismatch when the key was used for a different body and
+// with ErrIdempotencyKeyInProgress while the earlier request is running.
+// A claim holds the key for lease; a claim left behind longer, by a request
+// that crashed, is taken over, as are expired keys. Until the response is
+// stored the key also expires with its lease, so abandoned claims are
+// purged. The check and the claim are one conditional upsert, so of two
+// concurrent requests only one claims the key.
+func (s *GormStore) ClaimIdempotencyKey(scope, key, requestHash, token string, now time.Time, lease time.Duration) (*models.IdempotencyKey, error) {
+	claim := models.IdempotencyKey{Scope: scope, Key: key, RequestHash: requestHash, ClaimToken: token, CreatedAt: now, LockedUntil: now.Add(lease), ExpiresAt: now.Add(lease)}
+	result := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"request_hash": requestHash,
+			"status_code":  0,
+			"content_type": "",
+			"location":     "",
+			"body":         "",
+			"created_at":   claim.CreatedAt,
+			"locked_until": claim.LockedUntil,
+			"claim_token":  token,
+			"expires_at":   claim.ExpiresAt,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{
+				SQL:  "idempotency_keys.expires_at <= ? OR (idempotency_keys.status_code = 0 AND idempotency_keys.locked_until <= ?)",
+				Vars: []any{now, now},
+			},
+		}},
+	}).Create(&claim)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var stored models.IdempotencyKey
+	if err := s.db.Where("scope = ? AND key = ?", scope, key).First(&stored).Error; err != nil {
+		return nil, err
+	}
+	switch {
+	case stored.RequestHash != requestHash:
+		return nil, ErrIdempotencyKeyMismatch
+	case !stored.Completed():
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &stored, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request that claimed
+// the key so that retries can replay it until completed.ExpiresAt. The key
+// is named by the Scope and Key of completed and the claim by its
+// ClaimToken, the response by its remaining fields. A claim that was taken
+// over is left to the request that holds it now.
+func (s *GormStore) CompleteIdempotencyKey(completed models.IdempotencyKey) error {
+	return s.db.Model(&models.IdempotencyKey{}).
+		Where("scope = ? AND key = ? AND status_code = 0 AND claim_token = ?", completed.Scope, completed.Key, completed.ClaimToken).
+		Updates(map[string]any{
+			"status_code":  completed.StatusCode,
+			"content_type": completed.ContentType,
+			"location":     completed.Location,
+			"body":         completed.Body,
+			"expires_at":   completed.ExpiresAt,
+		}).Error
+}
+
+// ReleaseIdempotencyKey gives up a claim whose request did not complete, so
+// a retry handles the request again. Only the claim named by token is
+// given up; one taken over since is kept.
+func (s *GormStore) ReleaseIdempotencyKey(scope, key, token string) error {
+	return s.db.Where("scope = ? AND key = ? AND status_code = 0 AND claim_token = ?", scope, key, token).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeIdempotencyKeys deletes the keys that expired by now and returns how
+// many there were.
+func (s *GormStore) PurgeIdempotencyKeys(now time.Time) (int64, error) {
+	result := s.db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
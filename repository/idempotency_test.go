@@ -0,0 +1,187 @@
+// repository/idempotency_test.go
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeyLifecycle(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+
+	stored, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "first", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Until the first request completes its retries have to wait
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now, time.Hour)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-b", "retry", now, time.Hour)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+
+	// The same key is independent on another route
+	stored, err = store.ClaimIdempotencyKey("POST /challenges", "k1", "hash-b", "other", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	assert.NoError(t, store.CompleteIdempotencyKey(models.IdempotencyKey{
+		Scope: "POST /payments", Key: "k1", ClaimToken: "first", StatusCode: 202, ContentType: "application/json", Location: "/payments/1", Body: `{"ok":true}`,
+		ExpiresAt: now.Add(time.Hour),
+	}))
+	stored, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now.Add(time.Minute), time.Hour)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, 202, stored.StatusCode)
+		assert.Equal(t, "application/json", stored.ContentType)
+		assert.Equal(t, "/payments/1", stored.Location)
+		assert.Equal(t, `{"ok":true}`, stored.Body)
+	}
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-b", "retry", now.Add(time.Minute), time.Hour)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+
+	// Once expired the key is free for any request
+	stored, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-b", "later", now.Add(time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestReleasedIdempotencyKeysCanBeClaimedAgain(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+
+	_, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "first", now, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, store.ReleaseIdempotencyKey("POST /payments", "k1", "first"))
+	stored, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Completed keys are not released
+	assert.NoError(t, store.CompleteIdempotencyKey(models.IdempotencyKey{Scope: "POST /payments", Key: "k1", ClaimToken: "retry", StatusCode: 201, Body: "{}", ExpiresAt: now.Add(time.Hour)}))
+	assert.NoError(t, store.ReleaseIdempotencyKey("POST /payments", "k1", "retry"))
+	stored, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "later", now, time.Hour)
+	assert.NoError(t, err)
+	assert.NotNil(t, stored)
+}
+
+func TestStaleIdempotencyClaimsAreTakenOver(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+	lease := 30 * time.Second
+
+	_, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "first", now, lease)
+	assert.NoError(t, err)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now.Add(10*time.Second), lease)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+
+	// The first request never finished; once its lease is over a retry
+	// takes the key over instead of waiting out the replay TTL
+	stored, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "takeover", now.Add(lease), lease)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now.Add(lease+time.Second), lease)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+
+	// A stored response is replayed for the TTL, well past the lease
+	assert.NoError(t, store.CompleteIdempotencyKey(models.IdempotencyKey{
+		Scope: "POST /payments", Key: "k1", ClaimToken: "takeover", StatusCode: 201, Body: "{}", ExpiresAt: now.Add(24 * time.Hour),
+	}))
+	stored, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "later", now.Add(time.Hour), lease)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, 201, stored.StatusCode)
+	}
+}
+
+func TestTakenOverClaimsStayWithTheNewRequest(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+	lease := 30 * time.Second
+
+	_, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "slow", now, lease)
+	assert.NoError(t, err)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "takeover", now.Add(lease), lease)
+	assert.NoError(t, err)
+
+	// The slow request finishing late neither stores its response nor
+	// drops the claim of the request that took the key over
+	assert.NoError(t, store.CompleteIdempotencyKey(models.IdempotencyKey{
+		Scope: "POST /payments", Key: "k1", ClaimToken: "slow", StatusCode: 201, Body: `{"id":1}`, ExpiresAt: now.Add(24 * time.Hour),
+	}))
+	assert.NoError(t, store.ReleaseIdempotencyKey("POST /payments", "k1", "slow"))
+	_, err = store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "retry", now.Add(lease+time.Second), lease)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+
+	assert.NoError(t, store.CompleteIdempotencyKey(models.IdempotencyKey{
+		Scope: "POST /payments", Key: "k1", ClaimToken: "takeover", StatusCode: 201, Body: `{"id":2}`, ExpiresAt: now.Add(24 * time.Hour),
+	}))
+	stored, err := store.ClaimIdempotencyKey("POST /payments", "k1", "hash-a", "later", now.Add(time.Hour), lease)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, `{"id":2}`, stored.Body)
+	}
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+
+	_, err := store.ClaimIdempotencyKey("POST /payments", "old", "hash", "first", now.Add(-2*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "new", "hash", "first", now, time.Hour)
+	assert.NoError(t, err)
+
+	purged, err := store.PurgeIdempotencyKeys(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	_, err = store.ClaimIdempotencyKey("POST /payments", "new", "hash", "retry", now, time.Hour)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+}
+
+func TestConcurrentClaimsOfOneKey(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = store.ClaimIdempotencyKey("POST /payments", "k1", "hash", strconv.Itoa(i), now, time.Hour)
+		}(i)
+	}
+	wg.Wait()
+
+	// SQLite may turn claims away as busy, but only one may succeed
+	claimed := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			claimed++
+		case !errors.Is(err, ErrIdempotencyKeyInProgress):
+			t.Logf("claim failed: %v", err)
+		}
+	}
+	assert.Equal(t, 1, claimed)
+}
@@ -0,0 +1,121 @@
+// repository/jackpot.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrJackpotAlreadyPaid = errors.New("jackpot already paid out for this challenge")
+
+// jackpotID is the primary key of the single jackpot row.
+const jackpotID = 1
+
+// GetJackpot returns the current pot. Before the first entry it shows the
+// seed the pot will open with.
+func (s *GormStore) GetJackpot(seed int64) (*models.Jackpot, error) {
+	var pot models.Jackpot
+	err := s.db.First(&pot, jackpotID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.Jackpot{ID: jackpotID, Amount: seed}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pot, nil
+}
+
+// payJackpot pays the pot to a winning challenge inside tx, credits the
+// player's wallet, marks the challenge won, resets the pot to seed and
+// queues the jackpot.won webhook event. Only ResolveChallenge calls it, for
+// a challenge whose roll it has just found to win; a challenge is paid at
+// most once.
+func payJackpot(tx *gorm.DB, challenge models.Challenge, seed int64) (models.JackpotPayout, error) {
+	var paid int64
+	if err := tx.Model(&models.JackpotPayout{}).Where("challenge_id = ?", challenge.ID).Count(&paid).Error; err != nil {
+		return models.JackpotPayout{}, err
+	}
+	if paid > 0 {
+		return models.JackpotPayout{}, ErrJackpotAlreadyPaid
+	}
+
+	pot, err := lockJackpot(tx, seed)
+	if err != nil {
+		return models.JackpotPayout{}, err
+	}
+	payout := models.JackpotPayout{ChallengeID: challenge.ID, PlayerID: challenge.PlayerID, Amount: pot.Amount}
+	if err := tx.Create(&payout).Error; err != nil {
+		return payout, err
+	}
+	if err := tx.Model(&challenge).Update("won", true).Error; err != nil {
+		return payout, err
+	}
+	// The house tops the emptied pot back up to the seed
+	err = post(tx, fmt.Sprintf("challenge:%d:payout", challenge.ID), models.LedgerKindJackpotPayout,
+		walletLeg(challenge.PlayerID, pot.Amount),
+		accountLeg(models.AccountJackpot, seed-pot.Amount),
+		accountLeg(models.AccountHouse, -seed))
+	if err != nil {
+		return payout, err
+	}
+	if err := tx.Model(&pot).Updates(map[string]any{"amount": seed, "entries": 0}).Error; err != nil {
+		return payout, err
+	}
+	return payout, enqueueEvent(tx, models.EventJackpotWon, payout.ID, payout, time.Now().UTC())
+}
+
+// GetJackpotPayouts retrieves the most recent payouts up to limit.
+func (s *GormStore) GetJackpotPayouts(limit int) ([]models.JackpotPayout, error) {
+	var payouts []models.JackpotPayout
+	if err := s.db.Order("created_at desc, id desc").Limit(limit).Find(&payouts).Error; err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// addToJackpot adds an entry's share of its fee to the pot.
+func addToJackpot(tx *gorm.DB, share, seed int64) error {
+	pot, err := lockJackpot(tx, seed)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&pot).Updates(map[string]any{
+		"amount":  pot.Amount + share,
+		"entries": pot.Entries + 1,
+	}).Error
+}
+
+// lockJackpot loads the pot and holds its row lock until the transaction
+// ends, opening the pot with seed if it does not exist yet.
+func lockJackpot(tx *gorm.DB, seed int64) (models.Jackpot, error) {
+	var pot models.Jackpot
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pot, jackpotID).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return pot, err
+	}
+
+	// Concurrent first entries race to open the pot; the loser waits for
+	// and then locks the winner's row
+	pot = models.Jackpot{ID: jackpotID, Amount: seed}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&pot)
+	if result.Error != nil {
+		return pot, result.Error
+	}
+	if result.RowsAffected > 0 {
+		// The house funds the opening pot
+		err := post(tx, "jackpot:open", models.LedgerKindJackpotSeed,
+			accountLeg(models.AccountHouse, -seed),
+			accountLeg(models.AccountJackpot, seed))
+		if err != nil {
+			return pot, err
+		}
+	}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pot, jackpotID).Error
+	return pot, err
+}
@@ -0,0 +1,89 @@
+// repository/jackpot_test.go
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestChallengesFeedTheJackpot(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2001, Rake: 0.1, JackpotSeed: 10000, Odds: flatOdds(0)}
+
+	pot, err := store.GetJackpot(cfg.JackpotSeed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000), pot.Amount)
+
+	for playerID := uint(1); playerID <= 3; playerID++ {
+		fundWallet(t, store, playerID, cfg.EntryFee)
+		_, err := store.CreateChallenge(models.Challenge{PlayerID: playerID, Amount: cfg.EntryFee}, cfg)
+		assert.NoError(t, err)
+	}
+	// A rejected entry adds nothing
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, config.ChallengeConfig{Cooldown: time.Hour, Rake: 0.1, Odds: flatOdds(0)})
+	assert.ErrorIs(t, err, ErrPlayerNotAllowed)
+
+	pot, err = store.GetJackpot(cfg.JackpotSeed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15403), pot.Amount) // 10000 + 3 * 1801
+	assert.Equal(t, int64(3), pot.Entries)
+}
+
+func TestWinnersTakeTheJackpot(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{Rake: 0.5, JackpotSeed: 1000, Odds: flatOdds(1)}
+	now := time.Now()
+
+	var ids []uint
+	for playerID := uint(1); playerID <= 2; playerID++ {
+		fundWallet(t, store, playerID, 2000)
+		id, err := store.CreateChallenge(models.Challenge{PlayerID: playerID, Amount: 2000, ResolveAt: now.Add(-time.Second)}, cfg)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	challenge, err := store.ResolveChallenge(ids[1], now, cfg)
+	assert.NoError(t, err)
+	assert.True(t, challenge.Won)
+	payouts, err := store.GetJackpotPayouts(1)
+	assert.NoError(t, err)
+	if assert.Len(t, payouts, 1) {
+		assert.Equal(t, int64(3000), payouts[0].Amount)
+		assert.Equal(t, uint(2), payouts[0].PlayerID)
+	}
+	assertBalance(t, store, 2, 3000)
+
+	pot, err := store.GetJackpot(cfg.JackpotSeed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), pot.Amount)
+	assert.Zero(t, pot.Entries)
+
+	// A challenge is paid once, however it is reached
+	_, err = store.ResolveChallenge(ids[1], now, cfg)
+	assert.ErrorIs(t, err, ErrChallengeResolved)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := payJackpot(tx, *challenge, cfg.JackpotSeed)
+		return err
+	})
+	assert.ErrorIs(t, err, ErrJackpotAlreadyPaid)
+
+	_, err = store.ResolveChallenge(ids[0], now, cfg)
+	assert.NoError(t, err)
+	payouts, err = store.GetJackpotPayouts(10)
+	assert.NoError(t, err)
+	if assert.Len(t, payouts, 2) {
+		assert.Equal(t, ids[0], payouts[0].ChallengeID)
+		assert.Equal(t, int64(1000), payouts[0].Amount)
+	}
+	assertLedgerBalanced(t, store)
+}
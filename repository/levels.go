@@ -12,16 +12,16 @@ var (
 )
 
 // GetAllLevels retrieves all levels
-func GetAllLevels() ([]models.Level, error) {
+func (s *GormStore) GetAllLevels() ([]models.Level, error) {
     var levels []models.Level
-    result := DB.Find(&levels)
+    result := s.db.Find(&levels)
     return levels, result.Error
 }
 
 // GetLevelByID retrieves a level by its ID
-func GetLevelByID(id string) (*models.Level, error) {
+func (s *GormStore) GetLevelByID(id string) (*models.Level, error) {
     var level models.Level
-    result := DB.First(&level, "id = ?", id)
+    result := s.db.First(&level, "id = ?", id)
     if errors.Is(result.Error, gorm.ErrRecordNotFound) {
         return nil, ErrLevelNotFound
     }
@@ -29,7 +29,7 @@ func GetLevelByID(id string) (*models.Level, error) {
 }
 
 // CreateLevel adds a new level to the database
-func CreateLevel(level models.Level) (string, error) {
-    result := DB.Create(&level)
+func (s *GormStore) CreateLevel(level models.Level) (string, error) {
+    result := s.db.Create(&level)
     return level.ID, result.Error
 }
\ No newline at end of file
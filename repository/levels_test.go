@@ -10,31 +10,33 @@ import (
 )
 
 func TestCreateLevel(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	level := models.Level{
 		Name: "Beginner",
 	}
 
-	levelID, err := CreateLevel(level)
+	levelID, err := store.CreateLevel(level)
 	assert.NoError(t, err)
 	assert.NotZero(t, levelID)
 }
 
 func TestGetLevelByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a level first
 	level := models.Level{
 		Name: "Intermediate",
 	}
-	levelID, err := CreateLevel(level)
+	levelID, err := store.CreateLevel(level)
 	assert.NoError(t, err)
 
 	// Retrieve the level
-	retrievedLevel, err := GetLevelByID(levelID)
+	retrievedLevel, err := store.GetLevelByID(levelID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Intermediate", retrievedLevel.Name)
 }
@@ -7,8 +7,8 @@ import (
 )
 
 // CreateLog adds a new log entry to the database.
-func CreateLog(logEntry models.Log) (uint, error) {
-	if err := DB.Create(&logEntry).Error; err != nil {
+func (s *GormStore) CreateLog(logEntry models.Log) (uint, error) {
+	if err := s.db.Create(&logEntry).Error; err != nil {
 		return 0, err
 	}
 	return logEntry.ID, nil
@@ -16,9 +16,9 @@ func CreateLog(logEntry models.Log) (uint, error) {
 
 // QueryLogs retrieves logs based on the provided filters.
 // If a filter is not provided (zero value), it is ignored.
-func QueryLogs(playerID *uint, action *string, startTime *time.Time, endTime *time.Time, limit *int) ([]models.Log, error) {
+func (s *GormStore) QueryLogs(playerID *uint, action *string, startTime *time.Time, endTime *time.Time, limit *int) ([]models.Log, error) {
 	var logs []models.Log
-	query := DB.Model(&models.Log{})
+	query := s.db.Model(&models.Log{})
 
 	if playerID != nil {
 		query = query.Where("player_id = ?", *playerID)
@@ -11,8 +11,9 @@ import (
 )
 
 func TestCreateLog(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	logEntry := models.Log{
 		PlayerID:  1, // Ensure a Player with ID 1 exists
@@ -21,13 +22,14 @@ func TestCreateLog(t *testing.T) {
 		Timestamp: time.Now(),
 	}
 
-	logID, err := CreateLog(logEntry)
+	logID, err := store.CreateLog(logEntry)
 	assert.NoError(t, err)
 	assert.NotZero(t, logID)
 }
 func TestGetLogByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a log first
 	logEntry := models.Log{
@@ -36,11 +38,12 @@ func TestGetLogByID(t *testing.T) {
 		Details:   "Player registered successfully.",
 		Timestamp: time.Now(),
 	}
-	logID, err := CreateLog(logEntry)
+	_, err := store.CreateLog(logEntry)
 	assert.NoError(t, err)
 
-	// Retrieve the log
-	retrievedLogs, err := QueryLogs(&logID, nil, nil, nil, nil)
+	// Retrieve the log by its player
+	playerID := logEntry.PlayerID
+	retrievedLogs, err := store.QueryLogs(&playerID, nil, nil, nil, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(retrievedLogs)) // Assuming there's only one log with the given ID
 	retrievedLog := retrievedLogs[0]
@@ -0,0 +1,150 @@
+// repository/matches.go
+package repository
+
+import (
+	"errors"
+
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMatchNotFound  = errors.New("match not found")
+	ErrNotMatchPlayer = errors.New("player is not part of this match")
+	ErrRoomBusy       = errors.New("room already has a match in progress")
+)
+
+// CreateMatch adds a new match to the database. Both players must be seated
+// in the room, which may hold only one match in progress at a time and moves
+// to in_game once the match is created.
+func (s *GormStore) CreateMatch(match models.Match) (uint, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		room, err := lockRoom(tx, match.RoomID)
+		if err != nil {
+			return err
+		}
+		if !room.InService() {
+			return ErrRoomClosed
+		}
+		if room.Status == models.RoomStatusInGame {
+			return ErrRoomBusy
+		}
+
+		var seated int64
+		err = tx.Model(&models.RoomMember{}).
+			Where("room_id = ? AND player_id IN ?", match.RoomID, []string{match.PlayerXID, match.PlayerOID}).
+			Count(&seated).Error
+		if err != nil {
+			return err
+		}
+		if seated != 2 {
+			return ErrNotInRoom
+		}
+
+		if err := tx.Create(&match).Error; err != nil {
+			return err
+		}
+		return settleRoomStatus(tx, room)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return match.ID, nil
+}
+
+// GetMatchByID retrieves a match together with its moves in order.
+func (s *GormStore) GetMatchByID(id uint) (*models.Match, error) {
+	var match models.Match
+	err := s.db.Preload("Moves", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seq")
+	}).First(&match, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// ApplyMove validates and records a move by playerID in a single transaction.
+// The match row is locked while the move is applied, and the unique
+// (match_id, seq) constraint rejects a concurrent move on drivers without
+// row locks.
+func (s *GormStore) ApplyMove(matchID uint, playerID string, row, col int) (*models.Match, error) {
+	var match models.Match
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&match, matchID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMatchNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var mark game.Mark
+		switch playerID {
+		case match.PlayerXID:
+			mark = game.X
+		case match.PlayerOID:
+			mark = game.O
+		default:
+			return ErrNotMatchPlayer
+		}
+
+		g, err := game.Restore(game.Rules{Size: match.Size, WinLength: match.WinLength}, match.Board)
+		if err != nil {
+			return err
+		}
+		if err := g.Play(mark, row, col); err != nil {
+			return err
+		}
+
+		move := models.MatchMove{
+			MatchID:  match.ID,
+			Seq:      g.Moves(),
+			PlayerID: playerID,
+			Mark:     mark.String(),
+			Row:      row,
+			Col:      col,
+		}
+		if err := tx.Create(&move).Error; err != nil {
+			return err
+		}
+
+		ApplyGameState(&match, g)
+		if err := tx.Save(&match).Error; err != nil {
+			return err
+		}
+
+		// A finished match frees the room for the next one
+		if match.Status == game.StatusInProgress {
+			return nil
+		}
+		room, err := lockRoom(tx, match.RoomID)
+		if err != nil {
+			return err
+		}
+		return settleRoomStatus(tx, room)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMatchByID(matchID)
+}
+
+// ApplyGameState copies the engine state onto the persisted match.
+func ApplyGameState(match *models.Match, g *game.Game) {
+	match.Size = g.Rules().Size
+	match.WinLength = g.Rules().WinLength
+	match.Board = g.Board()
+	match.Status = g.Status()
+	match.Winner = g.Winner().String()
+	match.NextTurn = ""
+	if g.Status() == game.StatusInProgress {
+		match.NextTurn = g.Turn().String()
+	}
+}
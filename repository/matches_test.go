@@ -0,0 +1,125 @@
+// repository/matches_test.go
+package repository
+
+import (
+	"testing"
+
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// seatTestPlayers creates a room with players "1" and "2" seated in it.
+func seatTestPlayers(t *testing.T, store *GormStore) uint {
+	t.Helper()
+	roomID, err := store.CreateRoom(models.Room{Name: "Match room"})
+	assert.NoError(t, err)
+	for _, id := range []string{"1", "2"} {
+		_, err := store.CreatePlayer(models.Player{ID: id, Name: "Player " + id})
+		assert.NoError(t, err)
+		_, err = store.JoinRoom(roomID, id)
+		assert.NoError(t, err)
+	}
+	return roomID
+}
+
+func newTestMatch(roomID uint) models.Match {
+	g, _ := game.New(game.Rules{Size: 3, WinLength: 3})
+	match := models.Match{RoomID: roomID, PlayerXID: "1", PlayerOID: "2"}
+	ApplyGameState(&match, g)
+	return match
+}
+
+func createTestMatch(t *testing.T, store *GormStore) uint {
+	t.Helper()
+	id, err := store.CreateMatch(newTestMatch(seatTestPlayers(t, store)))
+	assert.NoError(t, err)
+	return id
+}
+
+func TestCreateAndGetMatch(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	id := createTestMatch(t, store)
+	match, err := store.GetMatchByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, ".........", match.Board)
+	assert.Equal(t, game.StatusInProgress, match.Status)
+	assert.Equal(t, "X", match.NextTurn)
+
+	_, err = store.GetMatchByID(id + 1)
+	assert.ErrorIs(t, err, ErrMatchNotFound)
+}
+
+func TestApplyMove(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	id := createTestMatch(t, store)
+
+	_, err := store.ApplyMove(id, "3", 0, 0)
+	assert.ErrorIs(t, err, ErrNotMatchPlayer)
+	_, err = store.ApplyMove(id, "2", 0, 0)
+	assert.ErrorIs(t, err, game.ErrNotYourTurn)
+
+	match, err := store.ApplyMove(id, "1", 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "....X....", match.Board)
+	assert.Equal(t, "O", match.NextTurn)
+
+	_, err = store.ApplyMove(id, "2", 1, 1)
+	assert.ErrorIs(t, err, game.ErrCellOccupied)
+
+	// O answers, X wins on the diagonal
+	for _, m := range []struct {
+		player   string
+		row, col int
+	}{{"2", 0, 1}, {"1", 0, 0}, {"2", 0, 2}, {"1", 2, 2}} {
+		match, err = store.ApplyMove(id, m.player, m.row, m.col)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, game.StatusWon, match.Status)
+	assert.Equal(t, "X", match.Winner)
+	assert.Empty(t, match.NextTurn)
+	if assert.Len(t, match.Moves, 5) {
+		for i, m := range match.Moves {
+			assert.Equal(t, i+1, m.Seq)
+		}
+	}
+
+	_, err = store.ApplyMove(id, "2", 2, 0)
+	assert.ErrorIs(t, err, game.ErrGameOver)
+
+	// The finished match hands the room back to its seated players
+	room, err := store.GetRoomByID(match.RoomID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoomStatusFull, room.Status)
+}
+
+func TestCreateMatchRequiresSeatedPlayers(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := seatTestPlayers(t, store)
+
+	stranger := newTestMatch(roomID)
+	stranger.PlayerOID = "3"
+	_, err := store.CreateMatch(stranger)
+	assert.ErrorIs(t, err, ErrNotInRoom)
+
+	_, err = store.CreateMatch(newTestMatch(roomID + 1))
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+
+	_, err = store.CreateMatch(newTestMatch(roomID))
+	assert.NoError(t, err)
+	room, err := store.GetRoomByID(roomID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoomStatusInGame, room.Status)
+
+	// One match at a time per room
+	_, err = store.CreateMatch(newTestMatch(roomID))
+	assert.ErrorIs(t, err, ErrRoomBusy)
+}
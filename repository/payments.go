@@ -2,30 +2,53 @@ package repository
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"interview_YangYang_20241010/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Define custom errors
 var (
-	ErrPaymentNotFound = errors.New("payment not found")
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrPaymentTransition = errors.New("payment cannot move to that status")
 )
 
 // CreatePayment adds a new payment record to the database.
-func CreatePayment(payment models.Payment) (uint, error) {
-	payment.Status = "Pending"
-	if err := DB.Create(&payment).Error; err != nil {
+// Payments without a status start out as Pending, and Pending ones the
+// provider does not know yet are due to be charged right away. A payment
+// created as Success is deposited into the player's wallet in the same
+// transaction.
+func (s *GormStore) CreatePayment(payment models.Payment) (uint, error) {
+	if payment.Status == "" {
+		payment.Status = "Pending"
+	}
+	if payment.Status == "Pending" && payment.TransactionID == "" && payment.ProcessAt == nil {
+		now := time.Now().UTC()
+		payment.ProcessAt = &now
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+		if err := settlePayment(tx, payment, "Pending"); err != nil {
+			return err
+		}
+		return announcePayment(tx, payment, "Pending")
+	})
+	if err != nil {
 		return 0, err
 	}
 	return payment.ID, nil
 }
 
 // GetPaymentByID retrieves a payment by its ID.
-func GetPaymentByID(id uint) (*models.Payment, error) {
+func (s *GormStore) GetPaymentByID(id uint) (*models.Payment, error) {
 	var payment models.Payment
-	if err := DB.First(&payment, id).Error; err != nil {
+	if err := s.db.First(&payment, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrPaymentNotFound
 		}
@@ -34,7 +57,138 @@ func GetPaymentByID(id uint) (*models.Payment, error) {
 	return &payment, nil
 }
 
-// UpdatePayment updates the payment record in the database.
-func UpdatePayment(payment models.Payment) error {
-	return DB.Save(&payment).Error
-}
\ No newline at end of file
+// GetPaymentByTransactionID retrieves the payment of the given method that
+// the provider knows by transactionID.
+func (s *GormStore) GetPaymentByTransactionID(method, transactionID string) (*models.Payment, error) {
+	if transactionID == "" {
+		return nil, ErrPaymentNotFound
+	}
+	var payment models.Payment
+	err := s.db.Where("method = ? AND transaction_id = ?", method, transactionID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ClaimDuePayments claims up to limit Pending payments due to be charged by
+// now and returns them. A claimed payment is not due again until lease has
+// passed, so processors running side by side never charge it at the same
+// time, and one left midway by a processor that stopped is taken up again
+// once the lease is over.
+func (s *GormStore) ClaimDuePayments(now time.Time, lease time.Duration, limit int) ([]models.Payment, error) {
+	var due []models.Payment
+	err := s.db.Where("status = ? AND process_at <= ?", "Pending", now).
+		Order("process_at, id").Limit(limit).Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := due[:0]
+	for _, payment := range due {
+		// Only the processor whose update still sees the payment due claims it
+		next := now.Add(lease)
+		result := s.db.Model(&models.Payment{}).
+			Where("id = ? AND status = ? AND process_at <= ?", payment.ID, "Pending", now).
+			Update("process_at", next)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected > 0 {
+			payment.ProcessAt = &next
+			claimed = append(claimed, payment)
+		}
+	}
+	return claimed, nil
+}
+
+// UpdatePayment records a payment's status, transaction ID, error message
+// and when its charge is next due; a payment leaving Pending is no longer
+// due. Moving a payment to Success deposits its amount into the player's
+// wallet, and moving a successful payment to Refunded takes it back out, in
+// the same transaction as the status change. Moving it to Success or Failed
+// queues the matching webhook event. Pending payments may only move to
+// Success or Failed and successful ones to Refunded; any other move, such as
+// Failed to Success, fails with ErrPaymentTransition and changes nothing.
+// The update only applies while the payment still has the status it was
+// checked against, so of two racing updates, such as a charge and a
+// provider callback, the second fails with ErrPaymentTransition instead of
+// overwriting the first.
+func (s *GormStore) UpdatePayment(payment models.Payment) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var stored models.Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, payment.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !stored.CanMoveTo(payment.Status) {
+			return fmt.Errorf("%w: %s to %s", ErrPaymentTransition, stored.Status, payment.Status)
+		}
+		processAt := payment.ProcessAt
+		if payment.Status != "Pending" {
+			processAt = nil
+		}
+		result := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, stored.Status).
+			Updates(map[string]any{
+				"status": payment.Status, "transaction_id": payment.TransactionID,
+				"error_message": payment.ErrorMessage, "process_at": processAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s was settled meanwhile", ErrPaymentTransition, stored.Status)
+		}
+		// The ledger and the event go by the stored amount and player, not
+		// by whatever else the caller's copy holds
+		updated := stored
+		updated.Status, updated.TransactionID, updated.ErrorMessage, updated.ProcessAt = payment.Status, payment.TransactionID, payment.ErrorMessage, processAt
+		if err := settlePayment(tx, updated, stored.Status); err != nil {
+			return err
+		}
+		return announcePayment(tx, updated, stored.Status)
+	})
+}
+
+// settlePayment posts the ledger transfer for a payment moving from the
+// previous status to its current one. The transfer names are unique per
+// payment, so a payment is deposited and refunded at most once.
+func settlePayment(tx *gorm.DB, payment models.Payment, previous string) error {
+	amount := payment.Amount
+	switch {
+	case payment.Status == "Success" && previous != "Success":
+		return post(tx, fmt.Sprintf("payment:%d", payment.ID), models.LedgerKindDeposit,
+			accountLeg(models.AccountExternal, -amount),
+			walletLeg(payment.PlayerID, amount))
+	case payment.Status == "Refunded" && previous == "Success":
+		return post(tx, fmt.Sprintf("payment:%d:refund", payment.ID), models.LedgerKindRefund,
+			walletLeg(payment.PlayerID, -amount),
+			accountLeg(models.AccountExternal, amount))
+	}
+	return nil
+}
+
+// announcePayment queues the webhook event for a payment moving from the
+// previous status to Success or Failed. The method details stay out of the
+// event.
+func announcePayment(tx *gorm.DB, payment models.Payment, previous string) error {
+	if payment.Status == previous {
+		return nil
+	}
+	payment.Details = ""
+	now := time.Now().UTC()
+	switch payment.Status {
+	case "Success":
+		return enqueueEvent(tx, models.EventPaymentSucceeded, payment.ID, payment, now)
+	case "Failed":
+		return enqueueEvent(tx, models.EventPaymentFailed, payment.ID, payment, now)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"testing"
+	"time"
 
 	"interview_YangYang_20241010/models"
 
@@ -10,78 +11,204 @@ import (
 )
 
 func TestCreatePayment(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	payment := models.Payment{
 		PlayerID:  1, // Ensure a Player with ID 1 exists
 		Method:    "CreditCard",
-		Amount:    100.00,
+		Amount:    10000,
 		Details:   `{"card_number":"4111111111111111","expiry":"12/25","cvv":"123"}`,
 		Status:    "Pending",
 	}
 
-	paymentID, err := CreatePayment(payment)
+	paymentID, err := store.CreatePayment(payment)
 	assert.NoError(t, err)
 	assert.NotZero(t, paymentID)
 }
 
 func TestGetPaymentByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a payment first
 	payment := models.Payment{
 		PlayerID:      2,
 		Method:        "ThirdParty",
-		Amount:        50.00,
+		Amount:        5000,
 		Details:       `{"provider":"PayPal","account":"player@example.com"}`,
 		Status:        "Success",
 		TransactionID: "TP1234567890",
 	}
-	paymentID, err := CreatePayment(payment)
+	paymentID, err := store.CreatePayment(payment)
 	assert.NoError(t, err)
 
 	// Retrieve the payment
-	retrievedPayment, err := GetPaymentByID(paymentID)
+	retrievedPayment, err := store.GetPaymentByID(paymentID)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(2), retrievedPayment.PlayerID)
 	assert.Equal(t, "ThirdParty", retrievedPayment.Method)
-	assert.Equal(t, 50.00, retrievedPayment.Amount)
+	assert.Equal(t, int64(5000), retrievedPayment.Amount)
 	assert.Equal(t, "Success", retrievedPayment.Status)
 	assert.Equal(t, "TP1234567890", retrievedPayment.TransactionID)
 }
 
 func TestUpdatePayment(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a payment first
 	payment := models.Payment{
 		PlayerID:      3,
 		Method:        "BankTransfer",
-		Amount:        200.00,
+		Amount:        20000,
 		Details:       `{"bank_account":"123456789","bank_code":"001"}`,
 		Status:        "Pending",
 	}
-	paymentID, err := CreatePayment(payment)
+	paymentID, err := store.CreatePayment(payment)
 	assert.NoError(t, err)
 
 	// Update the payment's status to Success
 	updatedPayment := models.Payment{
+		ID:            paymentID,
 		PlayerID:      3,
 		Method:        "BankTransfer",
-		Amount:        200.00,
+		Amount:        20000,
 		Details:       `{"bank_account":"123456789","bank_code":"001"}`,
 		Status:        "Success",
 		TransactionID: "BT9876543210",
 	}
-	err = UpdatePayment(updatedPayment)
+	err = store.UpdatePayment(updatedPayment)
 	assert.NoError(t, err)
 
 	// Retrieve and verify the update
-	retrievedPayment, err := GetPaymentByID(paymentID)
+	retrievedPayment, err := store.GetPaymentByID(paymentID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Success", retrievedPayment.Status)
 	assert.Equal(t, "BT9876543210", retrievedPayment.TransactionID)
-}
\ No newline at end of file
+}
+func TestPaymentStatusTransitions(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 2500})
+	assert.NoError(t, err)
+	payment, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+
+	payment.Status = "Failed"
+	assert.NoError(t, store.UpdatePayment(*payment))
+	// Repeating the outcome is harmless, reversing it is not
+	assert.NoError(t, store.UpdatePayment(*payment))
+	for _, status := range []string{"Success", "Pending", "Refunded"} {
+		payment.Status = status
+		assert.ErrorIs(t, store.UpdatePayment(*payment), ErrPaymentTransition, status)
+	}
+	stored, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Failed", stored.Status)
+	assertBalance(t, store, 1, 0)
+
+	id, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 2500, Status: "Success"})
+	assert.NoError(t, err)
+	payment, err = store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	payment.Status = "Failed"
+	assert.ErrorIs(t, store.UpdatePayment(*payment), ErrPaymentTransition)
+	payment.Status = "Refunded"
+	assert.NoError(t, store.UpdatePayment(*payment))
+	payment.Status = "Success"
+	assert.ErrorIs(t, store.UpdatePayment(*payment), ErrPaymentTransition)
+	assertBalance(t, store, 1, 0)
+}
+
+func TestGetPaymentByTransactionID(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "Blockchain", Amount: 500, TransactionID: "tx_1"})
+	assert.NoError(t, err)
+	_, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 500})
+	assert.NoError(t, err)
+
+	payment, err := store.GetPaymentByTransactionID("Blockchain", "tx_1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, id, payment.ID)
+	}
+	// Transaction IDs are only unique per provider
+	_, err = store.GetPaymentByTransactionID("BankTransfer", "tx_1")
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+	_, err = store.GetPaymentByTransactionID("BankTransfer", "")
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestClaimDuePayments(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	now := time.Now().UTC()
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000})
+	assert.NoError(t, err)
+	// Payments the provider already knows wait for its callback
+	_, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 1000, TransactionID: "tx_1"})
+	assert.NoError(t, err)
+	_, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Success"})
+	assert.NoError(t, err)
+
+	claimed, err := store.ClaimDuePayments(now.Add(time.Second), time.Minute, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, id, claimed[0].ID)
+	}
+	// A claimed payment is left alone until its lease is over
+	claimed, err = store.ClaimDuePayments(now.Add(30*time.Second), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+	claimed, err = store.ClaimDuePayments(now.Add(2*time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	// Settling the payment takes it off the schedule
+	payment, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	payment.Status = "Success"
+	assert.NoError(t, store.UpdatePayment(*payment))
+	claimed, err = store.ClaimDuePayments(now.Add(time.Hour), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestStaleUpdatesDoNotOverwritePayments(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 2500, TransactionID: "tx_1"})
+	assert.NoError(t, err)
+	stale, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+
+	// A callback settles the payment while a charge still holds its copy
+	callback := *stale
+	callback.Status = "Success"
+	assert.NoError(t, store.UpdatePayment(callback))
+	stale.Status = "Failed"
+	stale.ErrorMessage = "Payment provider unavailable"
+	assert.ErrorIs(t, store.UpdatePayment(*stale), ErrPaymentTransition)
+
+	// Fields outside the update are not written back from the copy
+	stale.Status, stale.Amount, stale.ErrorMessage = "Success", 1, ""
+	assert.NoError(t, store.UpdatePayment(*stale))
+	stored, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Success", stored.Status)
+	assert.Equal(t, int64(2500), stored.Amount)
+	assert.Empty(t, stored.ErrorMessage)
+	assertBalance(t, store, 1, 2500)
+}
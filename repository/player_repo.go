@@ -12,16 +12,16 @@ var (
 )
 
 // GetAllPlayers retrieves all players with their associated levels
-func GetAllPlayers() ([]models.Player, error) {
+func (s *GormStore) GetAllPlayers() ([]models.Player, error) {
     var players []models.Player
-    result := DB.Preload("Level").Find(&players)
+    result := s.db.Preload("Level").Find(&players)
     return players, result.Error
 }
 
 // GetPlayerByID retrieves a player by their ID
-func GetPlayerByID(id string) (*models.Player, error) {
+func (s *GormStore) GetPlayerByID(id string) (*models.Player, error) {
     var player models.Player
-    result := DB.Preload("Level").First(&player, "id = ?", id)
+    result := s.db.Preload("Level").First(&player, "id = ?", id)
     if errors.Is(result.Error, gorm.ErrRecordNotFound) {
         return nil, ErrPlayerNotFound
     }
@@ -29,15 +29,15 @@ func GetPlayerByID(id string) (*models.Player, error) {
 }
 
 // CreatePlayer adds a new player to the database
-func CreatePlayer(player models.Player) (string, error) {
-    result := DB.Create(&player)
+func (s *GormStore) CreatePlayer(player models.Player) (string, error) {
+    result := s.db.Create(&player)
     return player.ID, result.Error
 }
 
 // UpdatePlayer updates an existing player's information
-func UpdatePlayer(id string, updatedPlayer models.Player) error {
+func (s *GormStore) UpdatePlayer(id string, updatedPlayer models.Player) error {
     var player models.Player
-    result := DB.First(&player, "id = ?", id)
+    result := s.db.First(&player, "id = ?", id)
     if errors.Is(result.Error, gorm.ErrRecordNotFound) {
         return ErrPlayerNotFound
     }
@@ -46,14 +46,23 @@ func UpdatePlayer(id string, updatedPlayer models.Player) error {
     player.Name = updatedPlayer.Name
     player.LevelID = updatedPlayer.LevelID
 
-    return DB.Save(&player).Error
+    return s.db.Save(&player).Error
 }
 
-// DeletePlayer removes a player from the database
-func DeletePlayer(id string) error {
-    result := DB.Delete(&models.Player{}, "id = ?", id)
-    if result.RowsAffected == 0 {
-        return ErrPlayerNotFound
-    }
-    return result.Error
+// DeletePlayer removes a player from the database, freeing their room seat
+// and cancelling the reservations they host
+func (s *GormStore) DeletePlayer(id string) error {
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Delete(&models.Player{}, "id = ?", id)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrPlayerNotFound
+        }
+        if err := vacateSeat(tx, id); err != nil {
+            return err
+        }
+        return releasePlayerReservations(tx, id)
+    })
 }
\ No newline at end of file
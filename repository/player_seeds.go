@@ -0,0 +1,104 @@
+// repository/player_seeds.go
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"interview_YangYang_20241010/fairness"
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetPlayerSeed returns the seed pair the player's next challenge will be
+// played with, drawing one if the player has none yet. Its server seed hash
+// is the commitment the player sees before entering.
+func (s *GormStore) GetPlayerSeed(playerID uint) (*models.PlayerSeed, error) {
+	seed, err := activeSeed(s.db, playerID)
+	if err != nil {
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// rotateSeed replaces the player's seed pair with a fresh one if its server
+// seed hashes to playedHash, and starts its nonce over. Resolution calls it,
+// so that no challenge is entered with a seed that may be revealed. A pair
+// rotated already is left alone.
+func rotateSeed(tx *gorm.DB, playerID uint, playedHash string) error {
+	fresh, err := newPlayerSeed(playerID)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.PlayerSeed{}).
+		Where("player_id = ? AND server_seed_hash = ?", playerID, playedHash).
+		Updates(map[string]any{
+			"server_seed": fresh.ServerSeed, "server_seed_hash": fresh.ServerSeedHash,
+			"client_seed": fresh.ClientSeed, "nonce": 0, "created_at": fresh.CreatedAt,
+		}).Error
+}
+
+// commitSeed plays a new challenge with its player's seed pair: the
+// challenge takes the pair's server seed, and its client seed unless the
+// player brought one, and the next nonce. Taking the nonce locks the pair
+// until the entry commits on drivers with row locks, so concurrent entries
+// cannot share a nonce or race a rotation.
+func commitSeed(tx *gorm.DB, challenge *models.Challenge) error {
+	if _, err := activeSeed(tx, challenge.PlayerID); err != nil {
+		return err
+	}
+	err := tx.Model(&models.PlayerSeed{}).Where("player_id = ?", challenge.PlayerID).
+		UpdateColumn("nonce", gorm.Expr("nonce + 1")).Error
+	if err != nil {
+		return err
+	}
+	var seed models.PlayerSeed
+	if err := tx.First(&seed, "player_id = ?", challenge.PlayerID).Error; err != nil {
+		return err
+	}
+	challenge.ServerSeed, challenge.ServerSeedHash, challenge.Nonce = seed.ServerSeed, seed.ServerSeedHash, seed.Nonce
+	if challenge.ClientSeed == "" {
+		challenge.ClientSeed = seed.ClientSeed
+	}
+	return nil
+}
+
+// activeSeed returns the player's seed pair, drawing it on first use. Of
+// two concurrent first uses one insert is ignored and both read the other.
+func activeSeed(tx *gorm.DB, playerID uint) (models.PlayerSeed, error) {
+	var seed models.PlayerSeed
+	err := tx.First(&seed, "player_id = ?", playerID).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return seed, err
+	}
+	fresh, err := newPlayerSeed(playerID)
+	if err != nil {
+		return seed, err
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh).Error; err != nil {
+		return seed, err
+	}
+	err = tx.First(&seed, "player_id = ?", playerID).Error
+	return seed, err
+}
+
+// newPlayerSeed draws a seed pair for the player.
+func newPlayerSeed(playerID uint) (models.PlayerSeed, error) {
+	serverSeed, err := fairness.NewSeed()
+	if err != nil {
+		return models.PlayerSeed{}, err
+	}
+	clientSeed, err := fairness.NewSeed()
+	if err != nil {
+		return models.PlayerSeed{}, err
+	}
+	return models.PlayerSeed{
+		PlayerID:       playerID,
+		ServerSeed:     serverSeed,
+		ServerSeedHash: fairness.Hash(serverSeed),
+		ClientSeed:     clientSeed,
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
@@ -11,6 +11,7 @@ func TestPlayerCRUD(t *testing.T) {
     // Setup the test database
     db := SetupTestDB(t)
     defer TearDownTestDB(db, t)
+    store := NewGormStore(db)
 
     // Create a new player
     player := models.Player{
@@ -19,12 +20,12 @@ func TestPlayerCRUD(t *testing.T) {
     }
 
     // Test CreatePlayer
-    createdPlayerID, err := CreatePlayer(player)
+    createdPlayerID, err := store.CreatePlayer(player)
     assert.NoError(t, err)
     assert.NotZero(t, createdPlayerID)
 
     // Test GetPlayerByID
-    fetchedPlayer, err := GetPlayerByID(createdPlayerID)
+    fetchedPlayer, err := store.GetPlayerByID(createdPlayerID)
     assert.NoError(t, err)
     assert.Equal(t, player.Name, fetchedPlayer.Name)
     assert.Equal(t, player.LevelID, fetchedPlayer.LevelID)
@@ -35,20 +36,20 @@ func TestPlayerCRUD(t *testing.T) {
         Name:    "Updated Player",
         LevelID: "2",
     }
-    err = UpdatePlayer(createdPlayerID, updatedPlayer)
+    err = store.UpdatePlayer(createdPlayerID, updatedPlayer)
     assert.NoError(t, err)
 
     // Verify the update
-    fetchedUpdatedPlayer, err := GetPlayerByID(createdPlayerID)
+    fetchedUpdatedPlayer, err := store.GetPlayerByID(createdPlayerID)
     assert.NoError(t, err)
     assert.Equal(t, updatedPlayer.Name, fetchedUpdatedPlayer.Name)
     assert.Equal(t, updatedPlayer.LevelID, fetchedUpdatedPlayer.LevelID)
 
     // Test DeletePlayer
-    err = DeletePlayer(createdPlayerID)
+    err = store.DeletePlayer(createdPlayerID)
     assert.NoError(t, err)
 
     // Verify deletion
-    _, err = GetPlayerByID(createdPlayerID)
+    _, err = store.GetPlayerByID(createdPlayerID)
     assert.Error(t, err) // Expect an error when fetching a deleted player
 }
\ No newline at end of file
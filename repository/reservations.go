@@ -3,40 +3,344 @@ package repository
 
 import (
     "errors"
+    "fmt"
+    "strings"
     "time"
 
     "interview_YangYang_20241010/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 var (
     ErrReservationNotFound = errors.New("reservation not found")
+    ErrInvalidSlot         = errors.New("reservation must end after it starts")
+    ErrSlotTooSoon         = errors.New("reservation starts too soon")
+    ErrReservationConflict = errors.New("reservation overlaps an existing reservation")
+    ErrReservationClosed   = errors.New("reservation can no longer be changed")
+    ErrCheckInClosed       = errors.New("check-in is only open around the reservation start")
 )
 
-// GetReservations retrieves reservations based on optional filters
-func GetReservations(roomID uint, date time.Time, limit int) ([]models.Reservation, error) {
+// ReservationConflictError names the reservation a new slot clashes with.
+type ReservationConflictError struct {
+    Existing models.Reservation
+}
+
+func (e *ReservationConflictError) Error() string {
+    return fmt.Sprintf("reservation overlaps reservation %d from %s to %s",
+        e.Existing.ID, e.Existing.StartAt.Format(time.RFC3339), e.Existing.EndAt.Format(time.RFC3339))
+}
+
+func (e *ReservationConflictError) Unwrap() error {
+    return ErrReservationConflict
+}
+
+// preloadReservation loads the room, host and guests of reservations.
+func preloadReservation(db *gorm.DB) *gorm.DB {
+    return db.Preload("Room").Preload("Host").Preload("Guests")
+}
+
+// GetReservations retrieves reservations based on optional filters, ordered by start time
+func (s *GormStore) GetReservations(roomID uint, date time.Time, limit int) ([]models.Reservation, error) {
     var reservations []models.Reservation
-    query := DB.Preload("Room").Preload("Player").Model(&models.Reservation{})
+    query := preloadReservation(s.db).Model(&models.Reservation{})
 
     if roomID != 0 {
         query = query.Where("room_id = ?", roomID)
     }
     if !date.IsZero() {
-        formattedDate := date.Format("2006-01-02")
-        query = query.Where("date = ?", formattedDate)
+        // Match every slot that overlaps the given calendar day
+        dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+        query = query.Where("start_at < ? AND end_at > ?", dayStart.AddDate(0, 0, 1).UTC(), dayStart.UTC())
     }
     if limit > 0 {
         query = query.Limit(limit)
     }
 
-    result := query.Find(&reservations)
+    result := query.Order("start_at").Find(&reservations)
     return reservations, result.Error
 }
 
-// CreateReservation adds a new reservation to the database
-func CreateReservation(reservation models.Reservation) (uint, error) {
-    result := DB.Create(&reservation)
-    if result.Error != nil {
-        return 0, result.Error
+// GetPlayerReservations retrieves the reservations a player hosts or is
+// invited to, ordered by start time
+func (s *GormStore) GetPlayerReservations(playerID string) ([]models.Reservation, error) {
+    if err := s.db.First(&models.Player{}, "id = ?", playerID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, ErrPlayerNotFound
+        }
+        return nil, err
+    }
+
+    var reservations []models.Reservation
+    result := preloadReservation(s.db).
+        Where("host_id = ? OR id IN (?)", playerID,
+            s.db.Table("reservation_guests").Select("reservation_id").Where("player_id = ?", playerID)).
+        Order("start_at").Find(&reservations)
+    return reservations, result.Error
+}
+
+// CreateReservation adds a new reservation to the database. The slot must
+// start after now, and the host and guests must be registered players. Overlapping
+// slots in the same room are rejected with a *ReservationConflictError: the
+// room row is locked while checking, and the database refuses overlaps that
+// slip past the check (an exclusion constraint on PostgreSQL, triggers on
+// SQLite).
+func (s *GormStore) CreateReservation(reservation models.Reservation, now time.Time) (uint, error) {
+    reservation.StartAt = reservation.StartAt.UTC().Truncate(time.Second)
+    reservation.EndAt = reservation.EndAt.UTC().Truncate(time.Second)
+    if !reservation.EndAt.After(reservation.StartAt) {
+        return 0, ErrInvalidSlot
+    }
+    if !reservation.StartAt.After(now) {
+        return 0, fmt.Errorf("%w: it must start in the future", ErrSlotTooSoon)
+    }
+    if reservation.TimeZone == "" {
+        reservation.TimeZone = "UTC"
+    }
+    reservation.Status = models.ReservationStatusBooked
+
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Room{}, "id = ?", reservation.RoomID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return ErrRoomNotFound
+            }
+            return err
+        }
+        if err := checkPlayers(tx, reservation.PlayerIDs()); err != nil {
+            return err
+        }
+        if err := findOverlap(tx, reservation); err != nil {
+            return err
+        }
+        return tx.Omit("Room", "Host", "Guests.*").Create(&reservation).Error
+    })
+    if err != nil && isOverlapViolation(err) {
+        // Lost a race with a concurrent booking; report the winner
+        if conflict := findOverlap(s.db, reservation); conflict != nil {
+            return 0, conflict
+        }
+        return 0, ErrReservationConflict
+    }
+    if err != nil {
+        return 0, err
     }
     return reservation.ID, nil
-}
\ No newline at end of file
+}
+
+// releasedStatuses are the statuses that no longer hold a slot.
+var releasedStatuses = []string{models.ReservationStatusCancelled, models.ReservationStatusNoShow}
+
+// GetReservationByID retrieves a reservation by its ID
+func (s *GormStore) GetReservationByID(id uint) (*models.Reservation, error) {
+    var reservation models.Reservation
+    result := preloadReservation(s.db).First(&reservation, "id = ?", id)
+    if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+        return nil, ErrReservationNotFound
+    }
+    return &reservation, result.Error
+}
+
+// UpdateReservation moves a booked reservation to a new slot or changes its
+// details. Only bookings starting after cutoff can be changed, and only to a
+// slot that also starts after cutoff; the new slot is checked for overlaps
+// like a new booking. A zero changes.StartAt or changes.EndAt keeps the
+// current one. Guests are replaced when changes.Guests is not nil; the host
+// stays the same.
+func (s *GormStore) UpdateReservation(id uint, changes models.Reservation, cutoff time.Time) (*models.Reservation, error) {
+    var reservation models.Reservation
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        var err error
+        reservation, err = lockChangeableReservation(tx, id, cutoff)
+        if err != nil {
+            return err
+        }
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Room{}, "id = ?", reservation.RoomID).Error; err != nil {
+            return err
+        }
+
+        if !changes.StartAt.IsZero() {
+            reservation.StartAt = changes.StartAt.UTC().Truncate(time.Second)
+        }
+        if !changes.EndAt.IsZero() {
+            reservation.EndAt = changes.EndAt.UTC().Truncate(time.Second)
+        }
+        if !reservation.EndAt.After(reservation.StartAt) {
+            return ErrInvalidSlot
+        }
+        if !reservation.StartAt.After(cutoff) {
+            return fmt.Errorf("%w: it must start after %s", ErrSlotTooSoon, cutoff.UTC().Format(time.RFC3339))
+        }
+        if changes.TimeZone != "" {
+            reservation.TimeZone = changes.TimeZone
+        }
+        if changes.Note != "" {
+            reservation.Note = changes.Note
+        }
+        if err := findOverlap(tx, reservation); err != nil {
+            return err
+        }
+        if err := tx.Omit(clause.Associations).Save(&reservation).Error; err != nil {
+            return err
+        }
+
+        if changes.Guests == nil {
+            return nil
+        }
+        changes.HostID = reservation.HostID
+        if err := checkPlayers(tx, changes.PlayerIDs()); err != nil {
+            return err
+        }
+        return tx.Model(&reservation).Omit("Guests.*").Association("Guests").Replace(changes.Guests)
+    })
+    if err != nil && isOverlapViolation(err) {
+        if conflict := findOverlap(s.db, reservation); conflict != nil {
+            return nil, conflict
+        }
+        return nil, ErrReservationConflict
+    }
+    if err != nil {
+        return nil, err
+    }
+    return s.GetReservationByID(id)
+}
+
+// CancelReservation cancels a booked reservation that starts after cutoff,
+// releasing its slot.
+func (s *GormStore) CancelReservation(id uint, cutoff time.Time) (*models.Reservation, error) {
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        reservation, err := lockChangeableReservation(tx, id, cutoff)
+        if err != nil {
+            return err
+        }
+        return tx.Model(&reservation).Update("status", models.ReservationStatusCancelled).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return s.GetReservationByID(id)
+}
+
+// CheckInReservation marks a booked reservation as used. Check-in is open
+// from grace before the start until grace after it.
+func (s *GormStore) CheckInReservation(id uint, now time.Time, grace time.Duration) (*models.Reservation, error) {
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        reservation, err := lockReservation(tx, id)
+        if err != nil {
+            return err
+        }
+        if reservation.Status != models.ReservationStatusBooked {
+            return fmt.Errorf("%w: reservation is %s", ErrReservationClosed, reservation.Status)
+        }
+        if now.Before(reservation.StartAt.Add(-grace)) || !now.Before(reservation.StartAt.Add(grace)) {
+            return ErrCheckInClosed
+        }
+        return tx.Model(&reservation).Update("status", models.ReservationStatusCheckedIn).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return s.GetReservationByID(id)
+}
+
+// MarkNoShows flips booked reservations that started before cutoff to
+// no_show and returns how many were changed.
+func (s *GormStore) MarkNoShows(cutoff time.Time) (int64, error) {
+    result := s.db.Model(&models.Reservation{}).
+        Where("status = ? AND start_at < ?", models.ReservationStatusBooked, cutoff.UTC()).
+        Update("status", models.ReservationStatusNoShow)
+    return result.RowsAffected, result.Error
+}
+
+// CompleteReservations flips checked-in reservations that ended by now to
+// completed and returns how many were changed.
+func (s *GormStore) CompleteReservations(now time.Time) (int64, error) {
+    result := s.db.Model(&models.Reservation{}).
+        Where("status = ? AND end_at <= ?", models.ReservationStatusCheckedIn, now.UTC()).
+        Update("status", models.ReservationStatusCompleted)
+    return result.RowsAffected, result.Error
+}
+
+// checkPlayers returns ErrPlayerNotFound naming the first of ids that is not
+// a registered player.
+func checkPlayers(tx *gorm.DB, ids []string) error {
+    if len(ids) == 0 {
+        return nil
+    }
+    var found []string
+    if err := tx.Model(&models.Player{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+        return err
+    }
+    known := make(map[string]bool, len(found))
+    for _, id := range found {
+        known[id] = true
+    }
+    for _, id := range ids {
+        if !known[id] {
+            return fmt.Errorf("%w: %s", ErrPlayerNotFound, id)
+        }
+    }
+    return nil
+}
+
+// releasePlayerReservations drops a deleted player from guest lists and
+// cancels the bookings they host that have not been used yet.
+func releasePlayerReservations(tx *gorm.DB, playerID string) error {
+    if err := tx.Exec("DELETE FROM reservation_guests WHERE player_id = ?", playerID).Error; err != nil {
+        return err
+    }
+    return tx.Model(&models.Reservation{}).Where("host_id = ?", playerID).
+        Updates(map[string]any{
+            "host_id": nil,
+            "status": gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END",
+                models.ReservationStatusBooked, models.ReservationStatusCancelled),
+        }).Error
+}
+
+// lockReservation loads the reservation and holds its row lock until the
+// transaction ends.
+func lockReservation(tx *gorm.DB, id uint) (models.Reservation, error) {
+    var reservation models.Reservation
+    err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, "id = ?", id).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return reservation, ErrReservationNotFound
+    }
+    return reservation, err
+}
+
+// lockChangeableReservation locks a reservation that is still booked and
+// starts after cutoff.
+func lockChangeableReservation(tx *gorm.DB, id uint, cutoff time.Time) (models.Reservation, error) {
+    reservation, err := lockReservation(tx, id)
+    if err != nil {
+        return reservation, err
+    }
+    if reservation.Status != models.ReservationStatusBooked {
+        return reservation, fmt.Errorf("%w: reservation is %s", ErrReservationClosed, reservation.Status)
+    }
+    if !reservation.StartAt.After(cutoff) {
+        return reservation, fmt.Errorf("%w: it starts at %s", ErrReservationClosed, reservation.StartAt.Format(time.RFC3339))
+    }
+    return reservation, nil
+}
+
+// findOverlap returns a *ReservationConflictError for the first reservation
+// in the same room whose slot overlaps the given one.
+func findOverlap(tx *gorm.DB, reservation models.Reservation) error {
+    var existing models.Reservation
+    err := tx.Where("room_id = ? AND start_at < ? AND end_at > ? AND id <> ? AND status NOT IN ?",
+        reservation.RoomID, reservation.EndAt, reservation.StartAt, reservation.ID, releasedStatuses).
+        Order("start_at").First(&existing).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return &ReservationConflictError{Existing: existing}
+}
+
+// isOverlapViolation reports whether err comes from the database-level
+// overlap guard.
+func isOverlapViolation(err error) bool {
+    return strings.Contains(err.Error(), "reservations_no_overlap")
+}
@@ -2,6 +2,7 @@
 package repository
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -10,39 +11,314 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestCreateReservation(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
-
-	reservation := models.Reservation{
-		RoomID:     1, // Ensure a Room with ID 1 exists
-		Date:       time.Now(), // Set the reservation date
-		Time:       "14:00", // Set the reservation time
-		PlayerInfo: "Player 1", // Set player information
+// slot returns a reservation in roomID from start to end, both given as
+// "15:04" on 2024-10-10 UTC.
+func slot(roomID uint, start, end string) models.Reservation {
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", "2024-10-10 "+clock)
+		return t
 	}
+	return models.Reservation{RoomID: roomID, StartAt: at(start), EndAt: at(end), Note: "Player 1"}
+}
+
+// bookedAt is when the test reservations on 2024-10-10 are made.
+var bookedAt = time.Date(2024, 10, 9, 12, 0, 0, 0, time.UTC)
 
-	reservationID, err := CreateReservation(reservation)
+func createTestRoom(t *testing.T, store *GormStore) uint {
+	t.Helper()
+	roomID, err := store.CreateRoom(models.Room{Name: "Room A"})
+	assert.NoError(t, err)
+	return roomID
+}
+
+func TestCreateReservation(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+
+	reservationID, err := store.CreateReservation(slot(roomID, "14:00", "16:00"), bookedAt)
 	assert.NoError(t, err)
 	assert.NotZero(t, reservationID)
+
+	_, err = store.CreateReservation(slot(roomID, "16:00", "14:00"), bookedAt)
+	assert.ErrorIs(t, err, ErrInvalidSlot)
+	_, err = store.CreateReservation(slot(roomID+1, "14:00", "16:00"), bookedAt)
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+
+	// Slots that already started cannot be booked
+	late := slot(roomID, "18:00", "19:00")
+	_, err = store.CreateReservation(late, late.StartAt)
+	assert.ErrorIs(t, err, ErrSlotTooSoon)
 }
 
 func TestGetReservationByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
 
-	// Create a reservation first
+	// Create a reservation first, booked from Taipei
+	taipei, _ := time.LoadLocation("Asia/Taipei")
 	reservation := models.Reservation{
-		RoomID:     1,
-		Date:       time.Now(),
-		Time:       "15:00",
-		PlayerInfo: "Player 2",
+		RoomID:   roomID,
+		StartAt:  time.Date(2024, 10, 10, 15, 0, 0, 0, taipei),
+		EndAt:    time.Date(2024, 10, 10, 16, 0, 0, 0, taipei),
+		TimeZone: "Asia/Taipei",
+		Note:     "Player 2",
 	}
-	reservationID, err := CreateReservation(reservation)
+	_, err := store.CreateReservation(reservation, bookedAt)
 	assert.NoError(t, err)
 
 	// Retrieve the reservation
-	retrievedReservations, err := GetReservations(reservationID, time.Now(), 1)
+	retrievedReservations, err := store.GetReservations(roomID, time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC), 1)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, retrievedReservations) {
+		got := retrievedReservations[0]
+		assert.Equal(t, "Player 2", got.Note)
+		assert.True(t, reservation.StartAt.Equal(got.StartAt))
+		assert.Equal(t, "Asia/Taipei", got.StartAt.Location().String())
+	}
+
+	retrievedReservations, err = store.GetReservations(roomID, time.Date(2024, 10, 11, 0, 0, 0, 0, time.UTC), 0)
+	assert.NoError(t, err)
+	assert.Empty(t, retrievedReservations)
+}
+
+func TestOverlappingReservationsAreRejected(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+	otherRoomID := createTestRoom(t, store)
+
+	firstID, err := store.CreateReservation(slot(roomID, "14:00", "16:00"), bookedAt)
+	assert.NoError(t, err)
+
+	for _, clash := range [][2]string{{"13:00", "14:30"}, {"15:00", "15:30"}, {"15:59", "18:00"}, {"13:00", "17:00"}} {
+		_, err := store.CreateReservation(slot(roomID, clash[0], clash[1]), bookedAt)
+		var conflict *ReservationConflictError
+		if assert.True(t, errors.As(err, &conflict), "%v should clash", clash) {
+			assert.Equal(t, firstID, conflict.Existing.ID)
+		}
+		assert.ErrorIs(t, err, ErrReservationConflict)
+	}
+
+	// Back-to-back slots and other rooms are fine
+	_, err = store.CreateReservation(slot(roomID, "16:00", "17:00"), bookedAt)
+	assert.NoError(t, err)
+	_, err = store.CreateReservation(slot(roomID, "13:00", "14:00"), bookedAt)
+	assert.NoError(t, err)
+	_, err = store.CreateReservation(slot(otherRoomID, "14:00", "16:00"), bookedAt)
+	assert.NoError(t, err)
+}
+
+func TestDatabaseRejectsOverlaps(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+
+	_, err := store.CreateReservation(slot(roomID, "14:00", "16:00"), bookedAt)
+	assert.NoError(t, err)
+
+	// Bypass the repository check, as a concurrent booking would
+	clash := slot(roomID, "15:00", "17:00")
+	clash.StartAt, clash.EndAt = clash.StartAt.UTC(), clash.EndAt.UTC()
+	err = db.Create(&clash).Error
+	if assert.Error(t, err) {
+		assert.True(t, isOverlapViolation(err), err.Error())
+	}
+}
+
+func TestUpdateAndCancelReservation(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+	cutoff := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	firstID, err := store.CreateReservation(slot(roomID, "14:00", "16:00"), bookedAt)
+	assert.NoError(t, err)
+	secondID, err := store.CreateReservation(slot(roomID, "17:00", "18:00"), bookedAt)
+	assert.NoError(t, err)
+
+	// Moving onto another booking is a clash, moving within its own slot is not
+	_, err = store.UpdateReservation(firstID, slot(roomID, "17:30", "18:30"), cutoff)
+	assert.ErrorIs(t, err, ErrReservationConflict)
+	changes := slot(roomID, "15:00", "17:00")
+	changes.Note = "Player 2"
+	updated, err := store.UpdateReservation(firstID, changes, cutoff)
+	if assert.NoError(t, err) {
+		assert.True(t, changes.StartAt.Equal(updated.StartAt))
+		assert.Equal(t, "Player 2", updated.Note)
+	}
+	_, err = store.UpdateReservation(firstID, slot(roomID, "16:00", "15:00"), cutoff)
+	assert.ErrorIs(t, err, ErrInvalidSlot)
+
+	// Nor can a booking be moved to before the cutoff
+	_, err = store.UpdateReservation(firstID, slot(roomID, "11:00", "13:00"), cutoff)
+	assert.ErrorIs(t, err, ErrSlotTooSoon)
+
+	// Leaving the slot out keeps it
+	updated, err = store.UpdateReservation(firstID, models.Reservation{Note: "Player 3"}, cutoff)
+	if assert.NoError(t, err) {
+		assert.True(t, changes.StartAt.Equal(updated.StartAt))
+		assert.True(t, changes.EndAt.Equal(updated.EndAt))
+		assert.Equal(t, "Player 3", updated.Note)
+	}
+
+	// Within the cancellation window nothing changes
+	late := time.Date(2024, 10, 10, 15, 30, 0, 0, time.UTC)
+	_, err = store.CancelReservation(firstID, late)
+	assert.ErrorIs(t, err, ErrReservationClosed)
+
+	cancelled, err := store.CancelReservation(secondID, cutoff)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ReservationStatusCancelled, cancelled.Status)
+	}
+	_, err = store.CancelReservation(secondID, cutoff)
+	assert.ErrorIs(t, err, ErrReservationClosed)
+	_, err = store.CancelReservation(secondID+1, cutoff)
+	assert.ErrorIs(t, err, ErrReservationNotFound)
+
+	// The cancelled slot can be booked again, also past the repository check
+	_, err = store.CreateReservation(slot(roomID, "17:00", "18:00"), bookedAt)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, retrievedReservations)
-	assert.Equal(t, "Player 2", retrievedReservations[0].PlayerInfo)
+	rebooked := slot(roomID, "17:30", "18:30")
+	rebooked.StartAt, rebooked.EndAt = rebooked.StartAt.UTC(), rebooked.EndAt.UTC()
+	assert.Error(t, db.Create(&rebooked).Error)
+}
+
+func TestCheckInAndSweepReservations(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+	grace := 15 * time.Minute
+	at := func(clock string) time.Time { return slot(roomID, clock, clock).StartAt }
+
+	usedID, err := store.CreateReservation(slot(roomID, "14:00", "15:00"), bookedAt)
+	assert.NoError(t, err)
+	missedID, err := store.CreateReservation(slot(roomID, "15:00", "16:00"), bookedAt)
+	assert.NoError(t, err)
+
+	_, err = store.CheckInReservation(usedID, at("13:40"), grace)
+	assert.ErrorIs(t, err, ErrCheckInClosed)
+	checkedIn, err := store.CheckInReservation(usedID, at("14:10"), grace)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ReservationStatusCheckedIn, checkedIn.Status)
+	}
+	_, err = store.CheckInReservation(usedID, at("14:11"), grace)
+	assert.ErrorIs(t, err, ErrReservationClosed)
+	_, err = store.CheckInReservation(missedID, at("15:15"), grace)
+	assert.ErrorIs(t, err, ErrCheckInClosed)
+
+	// Only bookings past the grace period become no-shows
+	n, err := store.MarkNoShows(at("15:00"))
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = store.MarkNoShows(at("15:01"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = store.CompleteReservations(at("14:59"))
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = store.CompleteReservations(at("15:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	for id, status := range map[uint]string{usedID: models.ReservationStatusCompleted, missedID: models.ReservationStatusNoShow} {
+		reservation, err := store.GetReservationByID(id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, status, reservation.Status)
+		}
+	}
+
+	// A no-show frees its slot
+	_, err = store.CreateReservation(slot(roomID, "15:30", "16:30"), bookedAt)
+	assert.NoError(t, err)
+}
+
+func TestReservationHostAndGuests(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	roomID := createTestRoom(t, store)
+	for _, id := range []string{"host", "guest1", "guest2"} {
+		_, err := store.CreatePlayer(models.Player{ID: id, Name: id})
+		assert.NoError(t, err)
+	}
+
+	booking := slot(roomID, "14:00", "15:00")
+	booking.HostID = "host"
+	booking.Guests = []models.Player{{ID: "guest1"}}
+	firstID, err := store.CreateReservation(booking, bookedAt)
+	assert.NoError(t, err)
+
+	// Registering the guests must not touch the players themselves
+	player, err := store.GetPlayerByID("guest1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "guest1", player.Name)
+	}
+
+	unknown := slot(roomID, "16:00", "17:00")
+	unknown.HostID = "host"
+	unknown.Guests = []models.Player{{ID: "ghost"}}
+	_, err = store.CreateReservation(unknown, bookedAt)
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+	assert.ErrorContains(t, err, "ghost")
+
+	reservation, err := store.GetReservationByID(firstID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, roomID, reservation.Room.ID)
+		assert.Equal(t, "host", reservation.Host.Name)
+		if assert.Len(t, reservation.Guests, 1) {
+			assert.Equal(t, "guest1", reservation.Guests[0].Name)
+		}
+	}
+
+	// Guests are swapped on update and the listing embeds every association
+	changes := slot(roomID, "14:00", "15:00")
+	changes.Guests = []models.Player{{ID: "guest2"}}
+	_, err = store.UpdateReservation(firstID, changes, time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	reservations, err := store.GetReservations(roomID, time.Time{}, 0)
+	if assert.NoError(t, err) && assert.Len(t, reservations, 1) {
+		assert.Equal(t, "Room A", reservations[0].Room.Name)
+		assert.Equal(t, "host", reservations[0].Host.ID)
+		assert.Equal(t, []string{"host", "guest2"}, reservations[0].PlayerIDs())
+	}
+
+	secondBooking := slot(roomID, "15:00", "16:00")
+	secondBooking.HostID = "guest2"
+	secondID, err := store.CreateReservation(secondBooking, bookedAt)
+	assert.NoError(t, err)
+	for playerID, want := range map[string][]uint{"host": {firstID}, "guest1": nil, "guest2": {firstID, secondID}} {
+		reservations, err := store.GetPlayerReservations(playerID)
+		assert.NoError(t, err)
+		var ids []uint
+		for _, r := range reservations {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, want, ids, playerID)
+	}
+	_, err = store.GetPlayerReservations("ghost")
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+
+	// Deleting the host cancels their booking; deleting a guest only drops them
+	assert.NoError(t, store.DeletePlayer("host"))
+	assert.NoError(t, store.DeletePlayer("guest2"))
+	reservation, err = store.GetReservationByID(firstID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ReservationStatusCancelled, reservation.Status)
+		assert.Empty(t, reservation.HostID)
+		assert.Nil(t, reservation.Host)
+		assert.Empty(t, reservation.Guests)
+	}
+	reservation, err = store.GetReservationByID(secondID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ReservationStatusCancelled, reservation.Status)
+	}
 }
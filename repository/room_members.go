@@ -0,0 +1,196 @@
+// repository/room_members.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"interview_YangYang_20241010/game"
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRoomFull      = errors.New("room is full")
+	ErrRoomClosed    = errors.New("room is out of service")
+	ErrAlreadyInRoom = errors.New("player is already in a room")
+	ErrNotInRoom     = errors.New("player is not in this room")
+)
+
+// Log actions written when players enter and leave rooms.
+const (
+	ActionEnterRoom = "Enter Room"
+	ActionExitRoom  = "Exit Room"
+)
+
+// JoinRoom seats the player in the lowest free seat of the room and returns
+// the updated room. The room row is locked for the duration, and the unique
+// player_id column keeps a player from sitting in two rooms even when joins
+// race across rooms.
+func (s *GormStore) JoinRoom(roomID uint, playerID string) (*models.Room, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		room, err := lockRoom(tx, roomID)
+		if err != nil {
+			return err
+		}
+		if !room.InService() {
+			return ErrRoomClosed
+		}
+
+		if err := tx.First(&models.Player{}, "id = ?", playerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPlayerNotFound
+			}
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&models.RoomMember{}).Where("player_id = ?", playerID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyInRoom
+		}
+
+		var members []models.RoomMember
+		if err := tx.Where("room_id = ?", roomID).Order("seat").Find(&members).Error; err != nil {
+			return err
+		}
+		if len(members) >= room.Capacity {
+			return ErrRoomFull
+		}
+		seat := 1
+		for _, m := range members {
+			if m.Seat != seat {
+				break
+			}
+			seat++
+		}
+
+		member := models.RoomMember{RoomID: roomID, PlayerID: playerID, Seat: seat}
+		if err := tx.Create(&member).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyInRoom
+			}
+			return err
+		}
+		if err := logRoomAction(tx, playerID, ActionEnterRoom, room, seat); err != nil {
+			return err
+		}
+		return settleRoomStatus(tx, room)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRoomByID(roomID)
+}
+
+// LeaveRoom frees the player's seat in the room and returns the updated room.
+func (s *GormStore) LeaveRoom(roomID uint, playerID string) (*models.Room, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		room, err := lockRoom(tx, roomID)
+		if err != nil {
+			return err
+		}
+
+		var member models.RoomMember
+		err = tx.Where("room_id = ? AND player_id = ?", roomID, playerID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotInRoom
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		if err := logRoomAction(tx, playerID, ActionExitRoom, room, member.Seat); err != nil {
+			return err
+		}
+		return settleRoomStatus(tx, room)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRoomByID(roomID)
+}
+
+// vacateSeat removes the player from whichever room they sit in, if any.
+func vacateSeat(tx *gorm.DB, playerID string) error {
+	var member models.RoomMember
+	err := tx.Where("player_id = ?", playerID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	room, err := lockRoom(tx, member.RoomID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Delete(&member).Error; err != nil {
+		return err
+	}
+	if err := logRoomAction(tx, playerID, ActionExitRoom, room, member.Seat); err != nil {
+		return err
+	}
+	return settleRoomStatus(tx, room)
+}
+
+// lockRoom loads the room and holds its row lock until the transaction ends.
+func lockRoom(tx *gorm.DB, roomID uint) (*models.Room, error) {
+	var room models.Room
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, "id = ?", roomID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// settleRoomStatus moves a room that is in service to the status implied by
+// its matches and members: in_game while a match is in progress, otherwise
+// full or open depending on the free seats.
+func settleRoomStatus(tx *gorm.DB, room *models.Room) error {
+	if !room.InService() {
+		return nil
+	}
+
+	var active, seated int64
+	if err := tx.Model(&models.Match{}).Where("room_id = ? AND status = ?", room.ID, game.StatusInProgress).Count(&active).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.RoomMember{}).Where("room_id = ?", room.ID).Count(&seated).Error; err != nil {
+		return err
+	}
+
+	status := models.RoomStatusOpen
+	switch {
+	case active > 0:
+		status = models.RoomStatusInGame
+	case seated >= int64(room.Capacity):
+		status = models.RoomStatusFull
+	}
+	if status == room.Status {
+		return nil
+	}
+	room.Status = status
+	return tx.Model(&models.Room{}).Where("id = ?", room.ID).Update("status", status).Error
+}
+
+// logRoomAction records a player entering or leaving a room. Logs key players
+// by number, so players registered with a non-numeric ID are logged under 0.
+func logRoomAction(tx *gorm.DB, playerID, action string, room *models.Room, seat int) error {
+	logPlayerID, _ := strconv.ParseUint(playerID, 10, 64)
+	entry := models.Log{
+		PlayerID: uint(logPlayerID),
+		Action:   action,
+		Details:  fmt.Sprintf("Room %d (%s), seat %d", room.ID, room.Name, seat),
+	}
+	return tx.Create(&entry).Error
+}
@@ -0,0 +1,113 @@
+// repository/room_members_test.go
+package repository
+
+import (
+	"testing"
+
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinRoomFillsSeatsAndLogs(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	roomID, err := store.CreateRoom(models.Room{Name: "Room A", Capacity: 2})
+	assert.NoError(t, err)
+	for _, id := range []string{"1", "2", "3"} {
+		_, err := store.CreatePlayer(models.Player{ID: id, Name: "Player " + id})
+		assert.NoError(t, err)
+	}
+
+	room, err := store.JoinRoom(roomID, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoomStatusOpen, room.Status)
+
+	room, err = store.JoinRoom(roomID, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoomStatusFull, room.Status)
+	if assert.Len(t, room.Members, 2) {
+		assert.Equal(t, 1, room.Members[0].Seat)
+		assert.Equal(t, 2, room.Members[1].Seat)
+	}
+
+	_, err = store.JoinRoom(roomID, "3")
+	assert.ErrorIs(t, err, ErrRoomFull)
+	_, err = store.JoinRoom(roomID, "1")
+	assert.ErrorIs(t, err, ErrAlreadyInRoom)
+	_, err = store.JoinRoom(roomID, "404")
+	assert.ErrorIs(t, err, ErrPlayerNotFound)
+
+	// Leaving frees the seat, which the next player takes
+	room, err = store.LeaveRoom(roomID, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoomStatusOpen, room.Status)
+	room, err = store.JoinRoom(roomID, "3")
+	assert.NoError(t, err)
+	if assert.Len(t, room.Members, 2) {
+		assert.Equal(t, "3", room.Members[0].PlayerID)
+		assert.Equal(t, 1, room.Members[0].Seat)
+	}
+
+	_, err = store.LeaveRoom(roomID, "1")
+	assert.ErrorIs(t, err, ErrNotInRoom)
+
+	enter, exit := ActionEnterRoom, ActionExitRoom
+	entered, err := store.QueryLogs(nil, &enter, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, entered, 3)
+	exited, err := store.QueryLogs(nil, &exit, nil, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, exited, 1) {
+		assert.Equal(t, uint(1), exited[0].PlayerID)
+	}
+}
+
+func TestPlayerSitsInOneRoom(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	first, _ := store.CreateRoom(models.Room{Name: "Room A"})
+	second, _ := store.CreateRoom(models.Room{Name: "Room B"})
+	store.CreatePlayer(models.Player{ID: "1", Name: "Alice"})
+
+	_, err := store.JoinRoom(first, "1")
+	assert.NoError(t, err)
+	_, err = store.JoinRoom(second, "1")
+	assert.ErrorIs(t, err, ErrAlreadyInRoom)
+
+	// The unique index backs the check up when joins race
+	err = db.Create(&models.RoomMember{RoomID: second, PlayerID: "1", Seat: 1}).Error
+	assert.Error(t, err)
+
+	// Deleting the player frees their seat
+	assert.NoError(t, store.DeletePlayer("1"))
+	room, err := store.GetRoomByID(first)
+	assert.NoError(t, err)
+	assert.Empty(t, room.Members)
+}
+
+func TestClosedRoomRejectsPlayers(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	roomID, _ := store.CreateRoom(models.Room{Name: "Room A", Status: models.RoomStatusClosed})
+	store.CreatePlayer(models.Player{ID: "1", Name: "Alice"})
+
+	_, err := store.JoinRoom(roomID, "1")
+	assert.ErrorIs(t, err, ErrRoomClosed)
+
+	assert.NoError(t, store.UpdateRoom(roomID, models.Room{Name: "Room A", Status: models.RoomStatusOpen}))
+	_, err = store.JoinRoom(roomID, "1")
+	assert.NoError(t, err)
+
+	// Capacity cannot drop below the seated players
+	store.CreatePlayer(models.Player{ID: "2", Name: "Bob"})
+	_, err = store.JoinRoom(roomID, "2")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, store.UpdateRoom(roomID, models.Room{Name: "Room A", Capacity: 1}), ErrCapacityTooSmall)
+}
@@ -6,59 +6,132 @@ import (
 
     "interview_YangYang_20241010/models"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 var (
-    ErrRoomNotFound = errors.New("room not found")
+    ErrRoomNotFound     = errors.New("room not found")
+    ErrCapacityTooSmall  = errors.New("capacity is below the number of seated players")
 )
 
-// GetAllRooms retrieves all game rooms with their reservations
-func GetAllRooms() ([]models.Room, error) {
+// preloadRoom loads the members in seat order and the reservations of a room
+func preloadRoom(db *gorm.DB) *gorm.DB {
+    return db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+        return db.Order("seat")
+    }).Preload("Reservations")
+}
+
+// GetAllRooms retrieves all game rooms with their members and reservations
+func (s *GormStore) GetAllRooms() ([]models.Room, error) {
     var rooms []models.Room
-    result := DB.Preload("Reservations").Find(&rooms)
+    result := preloadRoom(s.db).Find(&rooms)
     return rooms, result.Error
 }
 
 // GetRoomByID retrieves a room by its ID
-func GetRoomByID(id uint) (*models.Room, error) {
+func (s *GormStore) GetRoomByID(id uint) (*models.Room, error) {
     var room models.Room
-    result := DB.Preload("Reservations").First(&room, "id = ?", id)
+    result := preloadRoom(s.db).First(&room, "id = ?", id)
     if errors.Is(result.Error, gorm.ErrRecordNotFound) {
         return nil, ErrRoomNotFound
     }
     return &room, result.Error
 }
 
-// CreateRoom adds a new room to the database
-func CreateRoom(room models.Room) (uint, error) {
-    result := DB.Create(&room)
+// CreateRoom adds a new room to the database. New rooms are open unless
+// created out of service, are open around the clock in UTC unless told
+// otherwise, and get the default capacity when none is given.
+func (s *GormStore) CreateRoom(room models.Room) (uint, error) {
+    if room.Capacity <= 0 {
+        room.Capacity = models.DefaultRoomCapacity
+    }
+    if room.InService() {
+        room.Status = models.RoomStatusOpen
+    }
+    if room.OpensAt == "" {
+        room.OpensAt = "00:00"
+    }
+    if room.ClosesAt == "" {
+        room.ClosesAt = "24:00"
+    }
+    if room.TimeZone == "" {
+        room.TimeZone = "UTC"
+    }
+    room.Members = nil
+
+    result := s.db.Create(&room)
     if result.Error != nil {
         return 0, result.Error
     }
     return room.ID, nil
 }
 
-// UpdateRoom updates an existing room's information
-func UpdateRoom(id uint, updatedRoom models.Room) error {
-    var room models.Room
-    result := DB.First(&room, "id = ?", id)
-    if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-        return ErrRoomNotFound
-    }
+// UpdateRoom updates an existing room's information. Only taking the room
+// out of service (closed or maintenance) and reopening it are taken from the
+// requested status; every other status follows from the room's members and
+// matches. Empty opening hours and time zone keep their current values.
+func (s *GormStore) UpdateRoom(id uint, updatedRoom models.Room) error {
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        var room models.Room
+        result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, "id = ?", id)
+        if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+            return ErrRoomNotFound
+        }
+        if result.Error != nil {
+            return result.Error
+        }
 
-    // Update fields
-    room.Name = updatedRoom.Name
-    room.Description = updatedRoom.Description
-    room.Status = updatedRoom.Status
+        // Update fields
+        room.Name = updatedRoom.Name
+        room.Description = updatedRoom.Description
+        if updatedRoom.Capacity > 0 {
+            var seated int64
+            if err := tx.Model(&models.RoomMember{}).Where("room_id = ?", id).Count(&seated).Error; err != nil {
+                return err
+            }
+            if int64(updatedRoom.Capacity) < seated {
+                return ErrCapacityTooSmall
+            }
+            room.Capacity = updatedRoom.Capacity
+        }
+        if updatedRoom.OpensAt != "" {
+            room.OpensAt = updatedRoom.OpensAt
+        }
+        if updatedRoom.ClosesAt != "" {
+            room.ClosesAt = updatedRoom.ClosesAt
+        }
+        if updatedRoom.TimeZone != "" {
+            room.TimeZone = updatedRoom.TimeZone
+        }
+        switch updatedRoom.Status {
+        case models.RoomStatusClosed, models.RoomStatusMaintenance:
+            room.Status = updatedRoom.Status
+        case models.RoomStatusOpen:
+            if !room.InService() {
+                room.Status = models.RoomStatusOpen
+            }
+        }
 
-    return DB.Save(&room).Error
+        if err := tx.Omit(clause.Associations).Save(&room).Error; err != nil {
+            return err
+        }
+        return settleRoomStatus(tx, &room)
+    })
 }
 
-// DeleteRoom removes a room from the database
-func DeleteRoom(id uint) error {
-    result := DB.Delete(&models.Room{}, "id = ?", id)
-    if result.RowsAffected == 0 {
-        return ErrRoomNotFound
-    }
-    return result.Error
-}
\ No newline at end of file
+// DeleteRoom removes a room and its members from the database
+func (s *GormStore) DeleteRoom(id uint) error {
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("room_id = ?", id).Delete(&models.RoomMember{}).Error; err != nil {
+            return err
+        }
+        result := tx.Delete(&models.Room{}, "id = ?", id)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrRoomNotFound
+        }
+        return nil
+    })
+}
@@ -10,89 +10,117 @@ import (
 )
 
 func TestCreateRoom(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	room := models.Room{
 		Name:        "Room A",
 		Description: "First game room",
-		Status:      "available",
+		Capacity:    4,
 	}
 
-	roomID, err := CreateRoom(room)
+	roomID, err := store.CreateRoom(room)
 	assert.NoError(t, err)
 	assert.NotZero(t, roomID)
 }
 
 func TestGetRoomByID(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a room first
 	room := models.Room{
 		Name:        "Room B",
 		Description: "Second game room",
-		Status:      "occupied",
 	}
-	roomID, err := CreateRoom(room)
+	roomID, err := store.CreateRoom(room)
 	assert.NoError(t, err)
 
 	// Retrieve the room
-	retrievedRoom, err := GetRoomByID(roomID)
+	retrievedRoom, err := store.GetRoomByID(roomID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Room B", retrievedRoom.Name)
 	assert.Equal(t, "Second game room", retrievedRoom.Description)
-	assert.Equal(t, "occupied", retrievedRoom.Status)
+	assert.Equal(t, models.DefaultRoomCapacity, retrievedRoom.Capacity)
+	assert.Equal(t, models.RoomStatusOpen, retrievedRoom.Status)
+	assert.Equal(t, "00:00", retrievedRoom.OpensAt)
+	assert.Equal(t, "24:00", retrievedRoom.ClosesAt)
+	assert.Equal(t, "UTC", retrievedRoom.TimeZone)
 }
 
 func TestUpdateRoom(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a room first
 	room := models.Room{
 		Name:        "Room C",
 		Description: "Third game room",
-		Status:      "available",
+		Capacity:    4,
 	}
-	roomID, err := CreateRoom(room)
+	roomID, err := store.CreateRoom(room)
 	assert.NoError(t, err)
 
-	// Update the room's status
+	// Close the room
 	updatedRoom := models.Room{
 		Name:        "Room C",
 		Description: "Third game room updated",
-		Status:      "occupied",
+		Status:      models.RoomStatusClosed,
 	}
-	err = UpdateRoom(roomID, updatedRoom)
+	err = store.UpdateRoom(roomID, updatedRoom)
 	assert.NoError(t, err)
 
 	// Retrieve and verify the update
-	retrievedRoom, err := GetRoomByID(roomID)
+	retrievedRoom, err := store.GetRoomByID(roomID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Room C", retrievedRoom.Name)
 	assert.Equal(t, "Third game room updated", retrievedRoom.Description)
-	assert.Equal(t, "occupied", retrievedRoom.Status)
+	assert.Equal(t, models.RoomStatusClosed, retrievedRoom.Status)
+	assert.Equal(t, 4, retrievedRoom.Capacity)
+
+	// Statuses other than open and closed are derived, not set
+	updatedRoom.Status = models.RoomStatusFull
+	assert.NoError(t, store.UpdateRoom(roomID, updatedRoom))
+	retrievedRoom, _ = store.GetRoomByID(roomID)
+	assert.Equal(t, models.RoomStatusClosed, retrievedRoom.Status)
+
+	updatedRoom.Status = models.RoomStatusMaintenance
+	updatedRoom.OpensAt, updatedRoom.TimeZone = "09:00", "Europe/London"
+	assert.NoError(t, store.UpdateRoom(roomID, updatedRoom))
+	retrievedRoom, _ = store.GetRoomByID(roomID)
+	assert.Equal(t, models.RoomStatusMaintenance, retrievedRoom.Status)
+	assert.Equal(t, "09:00", retrievedRoom.OpensAt)
+	assert.Equal(t, "24:00", retrievedRoom.ClosesAt)
+	assert.Equal(t, "Europe/London", retrievedRoom.TimeZone)
+
+	updatedRoom.Status = models.RoomStatusOpen
+	assert.NoError(t, store.UpdateRoom(roomID, updatedRoom))
+	retrievedRoom, _ = store.GetRoomByID(roomID)
+	assert.Equal(t, models.RoomStatusOpen, retrievedRoom.Status)
 }
 
 func TestDeleteRoom(t *testing.T) {
-	SetupTestDB(t)
-	defer TearDownTestDB(TestDB, t)
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
 
 	// Create a room first
 	room := models.Room{
 		Name:        "Room D",
 		Description: "Fourth game room",
-		Status:      "available",
+		Capacity:    4,
 	}
-	roomID, err := CreateRoom(room)
+	roomID, err := store.CreateRoom(room)
 	assert.NoError(t, err)
 
 	// Delete the room
-	err = DeleteRoom(roomID)
+	err = store.DeleteRoom(roomID)
 	assert.NoError(t, err)
 
 	// Attempt to retrieve the deleted room
-	_, err = GetRoomByID(roomID)
+	_, err = store.GetRoomByID(roomID)
 	assert.Error(t, err)
 }
\ No newline at end of file
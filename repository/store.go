@@ -0,0 +1,153 @@
+// repository/store.go
+package repository
+
+import (
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+)
+
+// PlayerStore persists players.
+type PlayerStore interface {
+	GetAllPlayers() ([]models.Player, error)
+	GetPlayerByID(id string) (*models.Player, error)
+	CreatePlayer(player models.Player) (string, error)
+	UpdatePlayer(id string, updatedPlayer models.Player) error
+	DeletePlayer(id string) error
+}
+
+// LevelStore persists player levels.
+type LevelStore interface {
+	GetAllLevels() ([]models.Level, error)
+	GetLevelByID(id string) (*models.Level, error)
+	CreateLevel(level models.Level) (string, error)
+}
+
+// RoomStore persists game rooms.
+type RoomStore interface {
+	GetAllRooms() ([]models.Room, error)
+	GetRoomByID(id uint) (*models.Room, error)
+	CreateRoom(room models.Room) (uint, error)
+	UpdateRoom(id uint, updatedRoom models.Room) error
+	DeleteRoom(id uint) error
+	JoinRoom(roomID uint, playerID string) (*models.Room, error)
+	LeaveRoom(roomID uint, playerID string) (*models.Room, error)
+}
+
+// ReservationStore persists room reservations.
+type ReservationStore interface {
+	GetReservations(roomID uint, date time.Time, limit int) ([]models.Reservation, error)
+	CreateReservation(reservation models.Reservation, now time.Time) (uint, error)
+	GetReservationByID(id uint) (*models.Reservation, error)
+	GetPlayerReservations(playerID string) ([]models.Reservation, error)
+	UpdateReservation(id uint, changes models.Reservation, cutoff time.Time) (*models.Reservation, error)
+	CancelReservation(id uint, cutoff time.Time) (*models.Reservation, error)
+	CheckInReservation(id uint, now time.Time, grace time.Duration) (*models.Reservation, error)
+	MarkNoShows(cutoff time.Time) (int64, error)
+	CompleteReservations(now time.Time) (int64, error)
+}
+
+// ChallengeStore persists challenge entries and the jackpot they feed.
+type ChallengeStore interface {
+	CreateChallenge(challenge models.Challenge, cfg config.ChallengeConfig) (uint, error)
+	GetRecentChallengeResults(limit int) ([]models.Challenge, error)
+	GetChallengeByID(id uint) (*models.Challenge, error)
+	UpdateChallenge(challenge models.Challenge) error
+	GetPlayerChallenges(playerID uint, limit, offset int) ([]models.Challenge, int64, error)
+	GetPlayerChallengeStats(playerID uint) (*models.ChallengeStats, error)
+	GetPlayerParticipationCount(playerID uint) (int, error)
+	GetDueChallenges(now time.Time, limit int) ([]models.Challenge, error)
+	ResolveChallenge(id uint, now time.Time, cfg config.ChallengeConfig) (*models.Challenge, error)
+	GetJackpot(seed int64) (*models.Jackpot, error)
+	GetJackpotPayouts(limit int) ([]models.JackpotPayout, error)
+	GetPlayerSeed(playerID uint) (*models.PlayerSeed, error)
+	CountPendingChallengesBySeed(serverSeedHash string) (int64, error)
+}
+
+// ChallengeDefinitionStore persists the challenges players can enter
+// besides the endless one.
+type ChallengeDefinitionStore interface {
+	GetChallengeDefinitions() ([]models.ChallengeDefinition, error)
+	GetChallengeDefinitionByID(id uint) (*models.ChallengeDefinition, error)
+	CreateChallengeDefinition(definition models.ChallengeDefinition) (uint, error)
+	UpdateChallengeDefinition(id uint, definition models.ChallengeDefinition) error
+	DeleteChallengeDefinition(id uint) error
+}
+
+// LogStore persists game operation logs.
+type LogStore interface {
+	CreateLog(logEntry models.Log) (uint, error)
+	QueryLogs(playerID *uint, action *string, startTime *time.Time, endTime *time.Time, limit *int) ([]models.Log, error)
+}
+
+// PaymentStore persists payments.
+type PaymentStore interface {
+	CreatePayment(payment models.Payment) (uint, error)
+	GetPaymentByID(id uint) (*models.Payment, error)
+	GetPaymentByTransactionID(method, transactionID string) (*models.Payment, error)
+	UpdatePayment(payment models.Payment) error
+	ClaimDuePayments(now time.Time, lease time.Duration, limit int) ([]models.Payment, error)
+}
+
+// WalletStore reads player wallets and the ledger behind them.
+type WalletStore interface {
+	GetWallet(playerID uint) (*models.Wallet, error)
+	GetLedger(playerID uint, limit int) ([]models.LedgerEntry, error)
+	CheckLedger() ([]models.LedgerDiscrepancy, error)
+}
+
+// IdempotencyStore persists the requests made with an Idempotency-Key and
+// the responses replayed to their retries.
+type IdempotencyStore interface {
+	ClaimIdempotencyKey(scope, key, requestHash, token string, now time.Time, lease time.Duration) (*models.IdempotencyKey, error)
+	CompleteIdempotencyKey(completed models.IdempotencyKey) error
+	ReleaseIdempotencyKey(scope, key, token string) error
+	PurgeIdempotencyKeys(now time.Time) (int64, error)
+}
+
+// WebhookStore persists webhook subscriptions and the queue of deliveries
+// to them.
+type WebhookStore interface {
+	GetWebhooks() ([]models.Webhook, error)
+	GetWebhookByID(id uint) (*models.Webhook, error)
+	CreateWebhook(webhook models.Webhook) (uint, error)
+	DeleteWebhook(id uint) error
+	GetWebhookDeliveries(webhookID uint, status string, limit int) ([]models.WebhookDelivery, error)
+	ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery models.WebhookDelivery) error
+}
+
+// MatchStore persists OXO matches and their moves.
+type MatchStore interface {
+	CreateMatch(match models.Match) (uint, error)
+	GetMatchByID(id uint) (*models.Match, error)
+	ApplyMove(matchID uint, playerID string, row, col int) (*models.Match, error)
+}
+
+// GormStore implements every store interface on top of a GORM connection.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a store backed by the given connection.
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+var (
+	_ PlayerStore              = (*GormStore)(nil)
+	_ LevelStore               = (*GormStore)(nil)
+	_ RoomStore                = (*GormStore)(nil)
+	_ ReservationStore         = (*GormStore)(nil)
+	_ ChallengeStore           = (*GormStore)(nil)
+	_ ChallengeDefinitionStore = (*GormStore)(nil)
+	_ LogStore                 = (*GormStore)(nil)
+	_ PaymentStore             = (*GormStore)(nil)
+	_ WalletStore              = (*GormStore)(nil)
+	_ IdempotencyStore         = (*GormStore)(nil)
+	_ WebhookStore             = (*GormStore)(nil)
+	_ MatchStore               = (*GormStore)(nil)
+)
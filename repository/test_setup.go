@@ -2,48 +2,67 @@
 package repository
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"interview_YangYang_20241010/models" // Ensure this import is correct
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/migrations"
 )
 
-// TestDB is the global database connection used for testing
-var TestDB *gorm.DB
+// testCleanups holds the per-test cleanup registered by SetupTestDB,
+// keyed by the connection it belongs to.
+var testCleanups sync.Map
 
-// SetupTestDB initializes the test database connection
+// SetupTestDB creates an isolated database for a single test.
+//
+// By default each test gets its own SQLite file in a temporary directory, so
+// the suite runs without any database server. Set TEST_DB_DRIVER=postgres to
+// run against PostgreSQL instead; TEST_DB_DSN selects the server and every
+// test then gets a private schema.
 func SetupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	var db *gorm.DB
+	var cleanup func() error
 	var err error
-	// Replace with your actual test database connection string
-	dsn := "host=localhost user=archie password=postgres dbname=spinnerdb port=5432 sslmode=disable"
-	TestDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	switch driver := os.Getenv("TEST_DB_DRIVER"); driver {
+	case "", config.DriverSQLite:
+		db, cleanup, err = openTestSQLite(t)
+	case config.DriverPostgres:
+		db, cleanup, err = openTestPostgres()
+	default:
+		err = fmt.Errorf("unsupported TEST_DB_DRIVER %q", driver)
+	}
 	if err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Perform migrations for all models
-	err = TestDB.AutoMigrate(
-		&models.Player{},
-		&models.Level{},
-		&models.Room{},
-		&models.Reservation{},
-		&models.Challenge{},
-		&models.Log{},
-		&models.Payment{},
-	)
+	// Apply every migration so tests run against the production schema
+	migrator, err := migrations.New(db, driverName(db))
 	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// Assign TestDB to the repository's global DB variable
-	DB = TestDB
+	testCleanups.Store(db, cleanup)
 
-	return TestDB
+	return db
 }
 
-// TearDownTestDB closes the database connection and cleans up
+// TearDownTestDB closes the database connection and removes the data
+// created for the test.
 func TearDownTestDB(db *gorm.DB, t *testing.T) {
+	t.Helper()
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		t.Fatalf("Failed to get database from gorm: %v", err)
@@ -52,5 +71,74 @@ func TearDownTestDB(db *gorm.DB, t *testing.T) {
 		t.Fatalf("Failed to close database: %v", err)
 	}
 
-	// Optionally, you can drop the test database or clean up test data here
-}
\ No newline at end of file
+	if cleanup, ok := testCleanups.LoadAndDelete(db); ok {
+		if err := cleanup.(func() error)(); err != nil {
+			t.Fatalf("Failed to clean up test database: %v", err)
+		}
+	}
+}
+
+// openTestSQLite opens a fresh SQLite file inside the test's temp directory.
+func openTestSQLite(t *testing.T) (*gorm.DB, func() error, error) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(config.DatabaseConfig{Driver: config.DriverSQLite, Path: path})
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() error { return os.Remove(path) }, nil
+}
+
+// openTestPostgres creates a uniquely named schema and returns a connection
+// whose search_path points at it.
+func openTestPostgres() (*gorm.DB, func() error, error) {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		dsn = "host=localhost user=archie password=postgres dbname=spinnerdb port=5432 sslmode=disable"
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, nil, err
+	}
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := openPostgresDSN(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error; err != nil {
+		closeDB(admin)
+		return nil, nil, err
+	}
+
+	db, err := openPostgresDSN(fmt.Sprintf("%s search_path=%s", dsn, schema))
+	if err != nil {
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		closeDB(admin)
+		return nil, nil, err
+	}
+
+	cleanup := func() error {
+		defer closeDB(admin)
+		return admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema)).Error
+	}
+	return db, cleanup, nil
+}
+
+func openPostgresDSN(dsn string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+}
+
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// driverName maps a connection back to the driver name used by migrations.
+func driverName(db *gorm.DB) string {
+	if db.Dialector.Name() == "postgres" {
+		return config.DriverPostgres
+	}
+	return config.DriverSQLite
+}
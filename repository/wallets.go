@@ -0,0 +1,189 @@
+// repository/wallets.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("insufficient funds in wallet")
+	ErrUnbalancedTransfer = errors.New("ledger transfer does not balance")
+)
+
+// GetWallet retrieves a player's wallet. Players who never paid in have an
+// empty wallet.
+func (s *GormStore) GetWallet(playerID uint) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := s.db.First(&wallet, "player_id = ?", playerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.Wallet{PlayerID: playerID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// GetLedger retrieves the most recent ledger entries of a player's wallet up
+// to limit, newest first.
+func (s *GormStore) GetLedger(playerID uint, limit int) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	err := s.db.Where("account = ?", models.PlayerAccount(playerID)).
+		Order("id desc").Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CheckLedger verifies the ledger invariants: every transfer sums to zero,
+// every wallet balance equals the sum of its account's entries and the
+// jackpot account holds the current pot. It returns each broken invariant.
+func (s *GormStore) CheckLedger() ([]models.LedgerDiscrepancy, error) {
+	discrepancies := []models.LedgerDiscrepancy{}
+
+	var unbalanced []struct {
+		Transfer string
+		Total    int64
+	}
+	err := s.db.Model(&models.LedgerEntry{}).Select("transfer, SUM(amount) AS total").
+		Group("transfer").Having("SUM(amount) <> 0").Order("transfer").Scan(&unbalanced).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range unbalanced {
+		discrepancies = append(discrepancies, models.LedgerDiscrepancy{Transfer: t.Transfer, Actual: t.Total})
+	}
+
+	var sums []struct {
+		Account string
+		Total   int64
+	}
+	err = s.db.Model(&models.LedgerEntry{}).Select("account, SUM(amount) AS total").
+		Group("account").Scan(&sums).Error
+	if err != nil {
+		return nil, err
+	}
+	ledger := map[string]int64{}
+	for _, sum := range sums {
+		ledger[sum.Account] = sum.Total
+	}
+
+	var wallets []models.Wallet
+	if err := s.db.Order("player_id").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, wallet := range wallets {
+		account := models.PlayerAccount(wallet.PlayerID)
+		seen[account] = true
+		if ledger[account] != wallet.Balance {
+			discrepancies = append(discrepancies, models.LedgerDiscrepancy{Account: account, Expected: ledger[account], Actual: wallet.Balance})
+		}
+	}
+	// Player accounts with entries but no wallet row
+	for account, total := range ledger {
+		if strings.HasPrefix(account, "player:") && !seen[account] && total != 0 {
+			discrepancies = append(discrepancies, models.LedgerDiscrepancy{Account: account, Expected: total})
+		}
+	}
+
+	var pot models.Jackpot
+	err = s.db.First(&pot, jackpotID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if ledger[models.AccountJackpot] != pot.Amount {
+		discrepancies = append(discrepancies, models.LedgerDiscrepancy{Account: models.AccountJackpot, Expected: ledger[models.AccountJackpot], Actual: pot.Amount})
+	}
+	return discrepancies, nil
+}
+
+// leg is one side of a transfer. Legs on a player's account also move the
+// balance of the player's wallet.
+type leg struct {
+	account  string
+	playerID uint
+	amount   int64
+}
+
+// walletLeg moves amount cents into (or, when negative, out of) a wallet.
+func walletLeg(playerID uint, amount int64) leg {
+	return leg{account: models.PlayerAccount(playerID), playerID: playerID, amount: amount}
+}
+
+// accountLeg moves amount cents into (or out of) an internal account.
+func accountLeg(account string, amount int64) leg {
+	return leg{account: account, amount: amount}
+}
+
+// post records a transfer in the ledger inside tx and applies it to the
+// wallets involved. The legs must sum to zero; legs of zero are skipped. A
+// transfer name can only be posted once, so retrying a movement that
+// already happened fails instead of moving the money twice.
+func post(tx *gorm.DB, transfer, kind string, legs ...leg) error {
+	var total int64
+	entries := make([]models.LedgerEntry, 0, len(legs))
+	for _, l := range legs {
+		total += l.amount
+		if l.amount != 0 {
+			entries = append(entries, models.LedgerEntry{Transfer: transfer, Account: l.account, Kind: kind, Amount: l.amount})
+		}
+	}
+	if total != 0 {
+		return fmt.Errorf("%w: %s is off by %d", ErrUnbalancedTransfer, transfer, total)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Debits go first so a short wallet fails before anything is written
+	for _, l := range legs {
+		if l.playerID != 0 && l.amount < 0 {
+			if err := debitWallet(tx, l.playerID, -l.amount); err != nil {
+				return err
+			}
+		}
+	}
+	for _, l := range legs {
+		if l.playerID != 0 && l.amount > 0 {
+			if err := creditWallet(tx, l.playerID, l.amount); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Create(&entries).Error
+}
+
+// debitWallet takes amount cents from the player's wallet, failing with
+// ErrInsufficientFunds instead of letting the balance go negative. The
+// check and the debit are one statement, so concurrent debits cannot both
+// spend the same money.
+func debitWallet(tx *gorm.DB, playerID uint, amount int64) error {
+	result := tx.Model(&models.Wallet{}).
+		Where("player_id = ? AND balance >= ?", playerID, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// creditWallet adds amount cents to the player's wallet, opening it if needed.
+func creditWallet(tx *gorm.DB, playerID uint, amount int64) error {
+	wallet := models.Wallet{PlayerID: playerID, Balance: amount}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "player_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"balance": gorm.Expr("wallets.balance + excluded.balance"), "updated_at": gorm.Expr("excluded.updated_at")}),
+	}).Create(&wallet).Error
+}
@@ -0,0 +1,186 @@
+// repository/wallets_test.go
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fundWallet pays amount cents into the player's wallet through a successful payment.
+func fundWallet(t *testing.T, store *GormStore, playerID uint, amount int64) {
+	t.Helper()
+	_, err := store.CreatePayment(models.Payment{PlayerID: playerID, Method: "CreditCard", Amount: amount, Status: "Success"})
+	assert.NoError(t, err)
+}
+
+// assertBalance checks the player's wallet balance in cents.
+func assertBalance(t *testing.T, store *GormStore, playerID uint, want int64) {
+	t.Helper()
+	wallet, err := store.GetWallet(playerID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, wallet.Balance)
+	}
+}
+
+// assertLedgerBalanced checks that no ledger invariant is broken.
+func assertLedgerBalanced(t *testing.T, store *GormStore) {
+	t.Helper()
+	discrepancies, err := store.CheckLedger()
+	if assert.NoError(t, err) {
+		assert.Empty(t, discrepancies)
+	}
+}
+
+func TestSuccessfulPaymentsCreditTheWalletOnce(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	assertBalance(t, store, 1, 0)
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 5000})
+	assert.NoError(t, err)
+	assertBalance(t, store, 1, 0)
+
+	payment, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	payment.Status = "Success"
+	assert.NoError(t, store.UpdatePayment(*payment))
+	assert.NoError(t, store.UpdatePayment(*payment))
+	assertBalance(t, store, 1, 5000)
+
+	// Failed payments bring in nothing
+	id, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "BankTransfer", Amount: 3000})
+	assert.NoError(t, err)
+	payment, err = store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	payment.Status = "Failed"
+	assert.NoError(t, store.UpdatePayment(*payment))
+	assertBalance(t, store, 1, 5000)
+	assertLedgerBalanced(t, store)
+}
+
+func TestRefundsLeaveTheLedgerBalanced(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2001, Rake: 0.1, JackpotSeed: 10000, Odds: flatOdds(0)}
+
+	id, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 3050, Status: "Success"})
+	assert.NoError(t, err)
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+	assert.NoError(t, err)
+
+	// The entry fee is spent, so the deposit can no longer be refunded in full
+	payment, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	payment.Status = "Refunded"
+	assert.ErrorIs(t, store.UpdatePayment(*payment), ErrInsufficientFunds)
+	stored, err := store.GetPaymentByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Success", stored.Status)
+
+	fundWallet(t, store, 1, 2100)
+	assert.NoError(t, store.UpdatePayment(*payment))
+	assertBalance(t, store, 1, 3050-2001+2100-3050)
+
+	entries, err := store.GetLedger(1, 10)
+	assert.NoError(t, err)
+	var kinds []string
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []string{models.LedgerKindRefund, models.LedgerKindDeposit, models.LedgerKindEntryFee, models.LedgerKindDeposit}, kinds)
+	assertLedgerBalanced(t, store)
+}
+
+func TestCheckLedgerReportsDrift(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	fundWallet(t, store, 1, 1000)
+	assertLedgerBalanced(t, store)
+
+	db.Model(&models.Wallet{}).Where("player_id = ?", 1).Update("balance", 1500)
+	db.Create(&models.LedgerEntry{Transfer: "manual:1", Account: models.AccountHouse, Kind: "manual", Amount: 7})
+
+	discrepancies, err := store.CheckLedger()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.LedgerDiscrepancy{
+		{Transfer: "manual:1", Actual: 7},
+		{Account: "player:1", Expected: 1000, Actual: 1500},
+	}, discrepancies)
+}
+
+func TestChallengeEntryIsChargedToTheWallet(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2001, Rake: 0.1, JackpotSeed: 10000, Odds: flatOdds(1)}
+
+	// Without funds there is no challenge and the pot does not grow
+	_, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	fundWallet(t, store, 1, 2000)
+	_, err = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	var count int64
+	db.Model(&models.Challenge{}).Count(&count)
+	assert.Zero(t, count)
+	pot, err := store.GetJackpot(cfg.JackpotSeed)
+	assert.NoError(t, err)
+	assert.Zero(t, pot.Entries)
+	assertBalance(t, store, 1, 2000)
+
+	fundWallet(t, store, 1, 500)
+	id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee, ResolveAt: time.Now().Add(-time.Second)}, cfg)
+	assert.NoError(t, err)
+	assertBalance(t, store, 1, 499)
+
+	// Winnings go back to the wallet
+	_, err = store.ResolveChallenge(id, time.Now(), cfg)
+	assert.NoError(t, err)
+	pot, err = store.GetJackpot(cfg.JackpotSeed)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.JackpotSeed, pot.Amount)
+	assertBalance(t, store, 1, 499+cfg.JackpotSeed+1801)
+	assertLedgerBalanced(t, store)
+}
+
+func TestConcurrentEntriesCannotOverdraw(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{EntryFee: 2000, Odds: flatOdds(0)}
+	fundWallet(t, store, 1, 5000)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: cfg.EntryFee}, cfg)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	// SQLite may turn some entries away as busy, but none may overdraw
+	assert.LessOrEqual(t, succeeded, 2)
+	assert.NotZero(t, succeeded)
+	assertBalance(t, store, 1, 5000-2000*int64(succeeded))
+	assertLedgerBalanced(t, store)
+}
@@ -0,0 +1,150 @@
+// repository/webhooks.go
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"interview_YangYang_20241010/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// GetWebhooks retrieves every webhook.
+func (s *GormStore) GetWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := s.db.Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// GetWebhookByID retrieves a webhook by its ID.
+func (s *GormStore) GetWebhookByID(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// CreateWebhook adds a webhook. It receives the events that happen from
+// then on.
+func (s *GormStore) CreateWebhook(webhook models.Webhook) (uint, error) {
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return 0, err
+	}
+	return webhook.ID, nil
+}
+
+// DeleteWebhook deletes a webhook along with its deliveries, including the
+// ones not yet sent.
+func (s *GormStore) DeleteWebhook(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+// GetWebhookDeliveries retrieves the most recent deliveries to a webhook up
+// to limit, optionally only those with the given status.
+func (s *GormStore) GetWebhookDeliveries(webhookID uint, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := s.db.Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id desc").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDueWebhookDeliveries claims up to limit pending deliveries due by now
+// and returns them. A claimed delivery is not due again until lease has
+// passed, so dispatchers running side by side never send it at the same
+// time, and one that stopped midway is retried once the lease is over.
+func (s *GormStore) ClaimDueWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	err := s.db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+		Order("next_attempt_at, id").Limit(limit).Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := due[:0]
+	for _, delivery := range due {
+		// Only the dispatcher whose update still sees the delivery due claims it
+		result := s.db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.DeliveryStatusPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", now.Add(lease))
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected > 0 {
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+// UpdateWebhookDelivery records the outcome of an attempt to send a
+// delivery.
+func (s *GormStore) UpdateWebhookDelivery(delivery models.WebhookDelivery) error {
+	return s.db.Model(&delivery).Select(
+		"status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error",
+	).Updates(&delivery).Error
+}
+
+// enqueueEvent queues the event for every webhook subscribed to it, inside
+// the transaction that makes it happen, so an event is delivered if and
+// only if it took place. Queueing the same event twice is a no-op.
+func enqueueEvent(tx *gorm.DB, eventType string, subjectID uint, data any, now time.Time) error {
+	var webhooks []models.Webhook
+	if err := tx.Find(&webhooks).Error; err != nil {
+		return err
+	}
+	webhooks = slices.DeleteFunc(webhooks, func(w models.Webhook) bool {
+		return !slices.Contains(w.Events, eventType)
+	})
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	event := models.NewWebhookEvent(eventType, subjectID, data, now)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			Event:         eventType,
+			Payload:       string(payload),
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: now,
+		}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
@@ -0,0 +1,162 @@
+// repository/webhooks_test.go
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createWebhook(t *testing.T, store *GormStore, events ...string) uint {
+	t.Helper()
+	id, err := store.CreateWebhook(models.Webhook{URL: "http://example.com/hook", Events: events, Secret: "whsec"})
+	assert.NoError(t, err)
+	return id
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+
+	id := createWebhook(t, store, models.EventPaymentSucceeded, models.EventJackpotWon)
+	webhook, err := store.GetWebhookByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{models.EventPaymentSucceeded, models.EventJackpotWon}, webhook.Events)
+		assert.Equal(t, "whsec", webhook.Secret)
+	}
+	webhooks, err := store.GetWebhooks()
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+
+	_, err = store.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Success"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteWebhook(id))
+	_, err = store.GetWebhookByID(id)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+	assert.ErrorIs(t, store.DeleteWebhook(id), ErrWebhookNotFound)
+
+	// Its deliveries went with it
+	var left int64
+	assert.NoError(t, db.Model(&models.WebhookDelivery{}).Count(&left).Error)
+	assert.Zero(t, left)
+}
+
+func TestPaymentOutcomesQueueWebhookEvents(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	succeeded := createWebhook(t, store, models.EventPaymentSucceeded)
+	both := createWebhook(t, store, models.EventPaymentSucceeded, models.EventPaymentFailed)
+
+	payment := models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Pending", Details: `{"cvv":"123"}`}
+	id, err := store.CreatePayment(payment)
+	assert.NoError(t, err)
+	payment.ID = id
+
+	// Saving it again without a new outcome queues nothing
+	assert.NoError(t, store.UpdatePayment(payment))
+	payment.Status = "Success"
+	payment.TransactionID = "tx-1"
+	assert.NoError(t, store.UpdatePayment(payment))
+	assert.NoError(t, store.UpdatePayment(payment))
+
+	failed := models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Pending"}
+	failed.ID, err = store.CreatePayment(failed)
+	assert.NoError(t, err)
+	failed.Status = "Failed"
+	assert.NoError(t, store.UpdatePayment(failed))
+
+	deliveries, err := store.GetWebhookDeliveries(succeeded, "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		delivery := deliveries[0]
+		assert.Equal(t, models.EventPaymentSucceeded, delivery.Event)
+		assert.Equal(t, "payment.succeeded:1", delivery.EventID)
+		assert.Equal(t, models.DeliveryStatusPending, delivery.Status)
+
+		var event struct {
+			ID   string         `json:"id"`
+			Type string         `json:"type"`
+			Data models.Payment `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &event))
+		assert.Equal(t, delivery.EventID, event.ID)
+		assert.Equal(t, "tx-1", event.Data.TransactionID)
+		assert.Empty(t, event.Data.Details)
+	}
+
+	deliveries, err = store.GetWebhookDeliveries(both, "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, models.EventPaymentFailed, deliveries[0].Event)
+		assert.Equal(t, models.EventPaymentSucceeded, deliveries[1].Event)
+	}
+	deliveries, err = store.GetWebhookDeliveries(both, models.DeliveryStatusSucceeded, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestChallengeOutcomesQueueWebhookEvents(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	cfg := config.ChallengeConfig{JackpotSeed: 500, Odds: flatOdds(1)}
+	start := time.Date(2024, 10, 10, 14, 0, 0, 0, time.UTC)
+	fundWallet(t, store, 1, 2000)
+	webhookID := createWebhook(t, store, models.EventChallengeResolved, models.EventJackpotWon)
+
+	id, err := store.CreateChallenge(models.Challenge{PlayerID: 1, Amount: 2000, ResolveAt: start}, cfg)
+	assert.NoError(t, err)
+	_, err = store.ResolveChallenge(id, start, cfg)
+	assert.NoError(t, err)
+
+	deliveries, err := store.GetWebhookDeliveries(webhookID, "", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, models.EventChallengeResolved, deliveries[0].Event)
+		assert.Contains(t, deliveries[0].Payload, `"won":true`)
+		assert.Equal(t, models.EventJackpotWon, deliveries[1].Event)
+	}
+}
+
+func TestClaimDueWebhookDeliveries(t *testing.T) {
+	db := SetupTestDB(t)
+	defer TearDownTestDB(db, t)
+	store := NewGormStore(db)
+	createWebhook(t, store, models.EventPaymentSucceeded)
+	for i := 0; i < 3; i++ {
+		_, err := store.CreatePayment(models.Payment{PlayerID: 1, Method: "CreditCard", Amount: 1000, Status: "Success"})
+		assert.NoError(t, err)
+	}
+	now := time.Now().UTC().Add(time.Second)
+
+	claimed, err := store.ClaimDueWebhookDeliveries(now, time.Minute, 2)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+
+	// Claimed deliveries are leased out until the lease is over
+	claimed, err = store.ClaimDueWebhookDeliveries(now, time.Minute, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1) {
+		delivery := claimed[0]
+		attempted := now
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.Attempts = 1
+		delivery.LastAttemptAt = &attempted
+		delivery.ResponseStatus = 204
+		assert.NoError(t, store.UpdateWebhookDelivery(delivery))
+	}
+	claimed, err = store.ClaimDueWebhookDeliveries(now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = store.ClaimDueWebhookDeliveries(now.Add(2*time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+}
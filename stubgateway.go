@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"interview_YangYang_20241010/config"
+	"interview_YangYang_20241010/gateway"
+)
+
+// runStubGateway implements the `stubgateway` subcommand. It serves a stub
+// provider for every enabled payment method under /<method in lower case>,
+// which is where the default gateway URLs point, until interrupted. Each
+// stub can be rescripted while running through its /_script endpoint, and
+// transactions it left pending are settled through its /_settle endpoint,
+// which reports the outcome to the payment service when -callback-url is
+// set and the method has a callback secret.
+func runStubGateway(cfg *config.Config, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("stubgateway", flag.ContinueOnError)
+	flags.SetOutput(out)
+	addr := flags.String("addr", ":9090", "address to listen on")
+	latency := flags.Duration("latency", 0, "delay before every answer")
+	decline := flags.String("decline", "", "comma-separated methods whose authorizations are declined")
+	apiKey := flags.String("api-key", "", "bearer token the stubs require")
+	pending := flags.String("pending", "", "comma-separated methods whose captures are left pending until settled")
+	callbackURL := flags.String("callback-url", "", "base URL of the payment service that settled transactions are reported to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	declined := strings.Split(*decline, ",")
+	pendingMethods := strings.Split(*pending, ",")
+
+	mux := http.NewServeMux()
+	for _, method := range cfg.Payment.Methods {
+		stub := gateway.NewStub(*apiKey)
+		for _, op := range []string{gateway.OpAuthorize, gateway.OpCapture, gateway.OpRefund, gateway.OpStatus} {
+			stub.Script(op, gateway.Behavior{Latency: *latency})
+		}
+		if slices.Contains(declined, method) {
+			stub.Script(gateway.OpAuthorize, gateway.Behavior{Outcome: gateway.OutcomeDecline, Latency: *latency, Message: "Insufficient funds"})
+		}
+		if slices.Contains(pendingMethods, method) {
+			stub.Script(gateway.OpCapture, gateway.Behavior{Outcome: gateway.OutcomePending, Latency: *latency})
+		}
+		prefix := "/" + strings.ToLower(method)
+		if secret := cfg.Payment.Gateways[method].CallbackSecret; *callbackURL != "" && secret != "" {
+			stub.SetCallback(strings.TrimSuffix(*callbackURL, "/")+"/payments/callbacks"+prefix, secret)
+		}
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, stub))
+		fmt.Fprintf(out, "serving the %s stub at %s\n", method, prefix)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(out, "stub gateways listening on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
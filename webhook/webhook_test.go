@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"payment.succeeded:1"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign("whsec", now, body)
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+
+	assert.NoError(t, Verify("whsec", header, body, now.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verify("other", header, body, now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec", header, []byte(`{}`), now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec", header, body, now.Add(time.Hour), 5*time.Minute), ErrExpiredSignature)
+	assert.NoError(t, Verify("whsec", header, body, now.Add(time.Hour), 0))
+	assert.ErrorIs(t, Verify("whsec", "v1=abc", body, now, 0), ErrMalformedSignature)
+	assert.ErrorIs(t, Verify("whsec", "t=1700000000", body, now, 0), ErrMalformedSignature)
+}
+
+func TestNewRequestIsSigned(t *testing.T) {
+	body := []byte(`{"id":"jackpot.won:3"}`)
+	now := time.Now()
+	req, err := NewRequest(context.Background(), "http://example.com/hook", "whsec", "jackpot.won", 42, body, now)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "jackpot.won", req.Header.Get(EventHeader))
+	assert.Equal(t, "42", req.Header.Get(DeliveryHeader))
+	sent, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, sent)
+	assert.NoError(t, Verify("whsec", req.Header.Get(SignatureHeader), sent, now, time.Minute))
+}
+
+func TestBackoffDoublesUpToTheMax(t *testing.T) {
+	var waits []time.Duration
+	for attempts := 1; attempts <= 6; attempts++ {
+		waits = append(waits, Backoff(attempts, 10*time.Second, time.Minute))
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute, time.Minute,
+	}, waits)
+}